@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"mcm-api/internal/scheduler"
+)
+
+// schedulerCmd represents the scheduler command
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Start scheduler",
+	Long: `Start the scheduler firing the events of the contribution sessions: the closing
+reminder to the students who did not contribute, the closure notification to the
+coordinators and the export of the accepted contributions at the final closure.
+It also escalates the contributions without coordinator feedback past their deadline.
+Several instances may run, each event is fired once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := scheduler.InitializeScheduler()
+		s.Start()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schedulerCmd)
+}
@@ -24,6 +24,31 @@ type Config struct {
 	MediaBucket       string `mapstructure:"media_bucket"`
 	ConverterService  string `mapstructure:"converter_service"`
 	ImageProxyService string `mapstructure:"image_proxy_service"`
+	// comma separated roles that must sign in through the identity provider
+	PasswordLoginDisabledRoles string `mapstructure:"password_login_disabled_roles"`
+	OidcIssuer                 string `mapstructure:"oidc_issuer"`
+	OidcClientId               string `mapstructure:"oidc_client_id"`
+	OidcClientSecret           string `mapstructure:"oidc_client_secret"`
+	OidcRedirectUrl            string `mapstructure:"oidc_redirect_url"`
+	OidcGroupsClaim            string `mapstructure:"oidc_groups_claim"`
+	// comma separated group=role pairs, e.g. "mcm-admins=admin,students=student"
+	OidcRoleMapping string `mapstructure:"oidc_role_mapping"`
+	// groups starting with this prefix name the faculty of the user, e.g. "faculty:Computing"
+	OidcFacultyGroupPrefix string `mapstructure:"oidc_faculty_group_prefix"`
+	OidcProvisionUsers     bool   `mapstructure:"oidc_provision_users"`
+	// accepted contributions are magazine content and survive the erasure of their
+	// author, set this to scrub them like the others
+	PrivacyEraseAcceptedContributions bool `mapstructure:"privacy_erase_accepted_contributions"`
+	// hours before the closure of a session the students who did not contribute are
+	// reminded, 72 when unset
+	SessionReminderHours int `mapstructure:"session_reminder_hours"`
+	// days a coordinator has to comment on a contribution, 14 when unset
+	FeedbackDeadlineDays int `mapstructure:"feedback_deadline_days"`
+	// days before the feedback deadline a contribution is at risk, 3 when unset
+	FeedbackAtRiskDays int `mapstructure:"feedback_at_risk_days"`
+	// comma separated ips or ranges of the reverse proxies whose X-Forwarded-For is
+	// trusted, the peer address is the client ip when unset
+	TrustedProxies string `mapstructure:"trusted_proxies"`
 }
 
 func init() {
@@ -45,6 +70,20 @@ func init() {
 	_ = viper.BindEnv("media_bucket", strings.ToUpper("media_bucket"))
 	_ = viper.BindEnv("converter_service", strings.ToUpper("converter_service"))
 	_ = viper.BindEnv("image_proxy_service", strings.ToUpper("image_proxy_service"))
+	_ = viper.BindEnv("password_login_disabled_roles", strings.ToUpper("password_login_disabled_roles"))
+	_ = viper.BindEnv("oidc_issuer", strings.ToUpper("oidc_issuer"))
+	_ = viper.BindEnv("oidc_client_id", strings.ToUpper("oidc_client_id"))
+	_ = viper.BindEnv("oidc_client_secret", strings.ToUpper("oidc_client_secret"))
+	_ = viper.BindEnv("oidc_redirect_url", strings.ToUpper("oidc_redirect_url"))
+	_ = viper.BindEnv("oidc_groups_claim", strings.ToUpper("oidc_groups_claim"))
+	_ = viper.BindEnv("oidc_role_mapping", strings.ToUpper("oidc_role_mapping"))
+	_ = viper.BindEnv("oidc_faculty_group_prefix", strings.ToUpper("oidc_faculty_group_prefix"))
+	_ = viper.BindEnv("oidc_provision_users", strings.ToUpper("oidc_provision_users"))
+	_ = viper.BindEnv("privacy_erase_accepted_contributions", strings.ToUpper("privacy_erase_accepted_contributions"))
+	_ = viper.BindEnv("session_reminder_hours", strings.ToUpper("session_reminder_hours"))
+	_ = viper.BindEnv("feedback_deadline_days", strings.ToUpper("feedback_deadline_days"))
+	_ = viper.BindEnv("feedback_at_risk_days", strings.ToUpper("feedback_at_risk_days"))
+	_ = viper.BindEnv("trusted_proxies", strings.ToUpper("trusted_proxies"))
 }
 
 func (config *Config) GetDatabaseDsn() string {
@@ -60,9 +60,9 @@ var doc = `{
                 }
             }
         },
-        "/auth/login": {
+        "/auth/2fa/activate": {
             "post": {
-                "description": "Login",
+                "description": "Confirm the authenticator with its first code, returns the recovery codes and logs the user in",
                 "consumes": [
                     "application/json"
                 ],
@@ -72,15 +72,15 @@ var doc = `{
                 "tags": [
                     "Auth"
                 ],
-                "summary": "Login",
+                "summary": "Activate two-factor authentication during login",
                 "parameters": [
                     {
-                        "description": "login req",
+                        "description": "2fa activate req",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/authz.LoginRequest"
+                            "$ref": "#/definitions/authz.TwoFactorLoginRequest"
                         }
                     }
                 ],
@@ -88,20 +88,15 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/authz.LoginResponse"
+                            "$ref": "#/definitions/authz.TwoFactorActivateResponse"
                         }
                     }
                 }
             }
         },
-        "/comments": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "List comments",
+        "/auth/2fa/setup": {
+            "post": {
+                "description": "For roles that require two-factor authentication, generate an authenticator secret using the challenge token returned by login",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,43 +104,38 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comments"
+                    "Auth"
                 ],
-                "summary": "List comments",
+                "summary": "Set up two-factor authentication during login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "name": "contributionId",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "next",
-                        "in": "query"
+                        "description": "2fa setup req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.TwoFactorSetupRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/comment.CursorPaginateComposition"
+                            "$ref": "#/definitions/authz.TwoFactorSetupResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/auth/api-keys": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a comment",
+                "description": "List own api keys, key managers can list the keys of other users",
                 "consumes": [
                     "application/json"
                 ],
@@ -153,38 +143,36 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comments"
+                    "Api keys"
                 ],
-                "summary": "Create a comment",
+                "summary": "List api keys",
                 "parameters": [
                     {
-                        "description": "create",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/comment.CommentCreateReq"
-                        }
+                        "type": "integer",
+                        "description": "UserId lists the keys of another user, 0 lists every key, needs api_key.manage",
+                        "name": "userId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/comment.CommentRes"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/authz.ApiKeyResponse"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/comments/{id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get comment by ID",
+                "description": "Create an api key for yourself or a service account, the key is only returned once. Send it in the X-API-Key header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -192,34 +180,38 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comments"
+                    "Api keys"
                 ],
-                "summary": "Show a comment",
+                "summary": "Create an api key",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "create api key",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.ApiKeyCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/comment.CommentRes"
+                            "$ref": "#/definitions/authz.ApiKeyCreateResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/api-keys/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a comment",
+                "description": "get api key by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -227,32 +219,23 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comments"
+                    "Api keys"
                 ],
-                "summary": "Update a comment",
+                "summary": "Show an api key",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "update",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/comment.CommentUpdateReq"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/comment.CommentRes"
+                            "$ref": "#/definitions/authz.ApiKeyResponse"
                         }
                     }
                 }
@@ -263,7 +246,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a comment",
+                "description": "Revoke an api key, it stops working immediately",
                 "consumes": [
                     "application/json"
                 ],
@@ -271,12 +254,12 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Comments"
+                    "Api keys"
                 ],
-                "summary": "Delete a comment",
+                "summary": "Revoke an api key",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
@@ -288,16 +271,14 @@ var doc = `{
                         "description": ""
                     }
                 }
-            }
-        },
-        "/contribute-sessions": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List Contribute Sessions",
+                "description": "Rename an api key or change its address restrictions and expiry, scopes cannot be changed",
                 "consumes": [
                     "application/json"
                 ],
@@ -305,37 +286,45 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
+                    "Api keys"
                 ],
-                "summary": "List Contribute Sessions",
+                "summary": "Update an api key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "name": "page",
-                        "in": "query"
+                        "description": "update api key",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.ApiKeyUpdateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contributesession.PaginateComposition"
+                            "$ref": "#/definitions/authz.ApiKeyResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/impersonate": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a Contribute Session",
+                "description": "Returns a short lived access token acting as the user. The token is read only unless allowWrite is set, deletions and account changes are always blocked. Every request made with it is audited.",
                 "consumes": [
                     "application/json"
                 ],
@@ -343,17 +332,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
+                    "Auth"
                 ],
-                "summary": "Create a Contribute Session",
+                "summary": "Impersonate a user",
                 "parameters": [
                     {
-                        "description": "create",
+                        "description": "impersonate req",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionCreateReq"
+                            "$ref": "#/definitions/authz.ImpersonateRequest"
                         }
                     }
                 ],
@@ -361,20 +350,15 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionRes"
+                            "$ref": "#/definitions/authz.ImpersonateResponse"
                         }
                     }
                 }
             }
         },
-        "/contribute-sessions/current": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Get Current Contribute Session",
+        "/auth/invitations/accept": {
+            "post": {
+                "description": "Choose the password of an invited account using the token from the invitation email, the account is active afterwards",
                 "consumes": [
                     "application/json"
                 ],
@@ -382,27 +366,30 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
+                    "Auth"
                 ],
-                "summary": "Get Current Contribute Session",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "description": "accept invitation req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionRes"
+                            "$ref": "#/definitions/authz.AcceptInvitationRequest"
                         }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
                 }
             }
         },
-        "/contribute-sessions/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "get Contribute Session by ID",
+        "/auth/login": {
+            "post": {
+                "description": "Login",
                 "consumes": [
                     "application/json"
                 ],
@@ -410,34 +397,33 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
+                    "Auth"
                 ],
-                "summary": "Show a Contribute Session",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "login req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionRes"
+                            "$ref": "#/definitions/authz.LoginResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Update a Contribute Session",
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange the challenge token returned by login and an authenticator or recovery code for tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -445,24 +431,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
+                    "Auth"
                 ],
-                "summary": "Update a Contribute Session",
+                "summary": "Login second step",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "create",
+                        "description": "2fa login req",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionUpdateReq"
+                            "$ref": "#/definitions/authz.TwoFactorLoginRequest"
                         }
                     }
                 ],
@@ -470,18 +449,20 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contributesession.SessionRes"
+                            "$ref": "#/definitions/authz.LoginResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a Contribute Session",
+                "description": "Revoke the current session",
                 "consumes": [
                     "application/json"
                 ],
@@ -489,18 +470,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
-                ],
-                "summary": "Delete a Contribute Session",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Auth"
                 ],
+                "summary": "Logout",
                 "responses": {
                     "200": {
                         "description": ""
@@ -508,14 +480,9 @@ var doc = `{
                 }
             }
         },
-        "/contribute-sessions/{id}/export": {
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Export a Contribute Session",
+        "/auth/oidc/authorize": {
+            "get": {
+                "description": "Returns the identity provider url to redirect the browser to, the provider redirects back to the web app with code and state",
                 "consumes": [
                     "application/json"
                 ],
@@ -523,96 +490,22 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contribute Sessions"
-                ],
-                "summary": "Export a Contribute Session",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": ""
-                    }
-                }
-            }
-        },
-        "/contributions": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "List contributions",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Contributions"
-                ],
-                "summary": "List contributions",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "name": "contributionSessionId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "facultyId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "accepted",
-                            "rejected",
-                            "reviewing"
-                        ],
-                        "type": "string",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "studentId",
-                        "in": "query"
-                    }
+                    "Auth"
                 ],
+                "summary": "Start single sign-on",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contribution.PaginateComposition"
+                            "$ref": "#/definitions/authz.OidcAuthorizeResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/oidc/callback": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Create a contribution",
+                "description": "Exchange the code and state received from the identity provider for tokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -620,17 +513,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
+                    "Auth"
                 ],
-                "summary": "Create a contribution",
+                "summary": "Finish single sign-on",
                 "parameters": [
                     {
-                        "description": "create",
+                        "description": "oidc callback req",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/contribution.ContributionCreateReq"
+                            "$ref": "#/definitions/authz.OidcCallbackRequest"
                         }
                     }
                 ],
@@ -638,20 +531,15 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/contribution.ContributionRes"
+                            "$ref": "#/definitions/authz.LoginResponse"
                         }
                     }
                 }
             }
         },
-        "/contributions/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "get contribution by ID",
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Send a password reset link to the email if it belongs to an account",
                 "consumes": [
                     "application/json"
                 ],
@@ -659,34 +547,30 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
+                    "Auth"
                 ],
-                "summary": "Show a contribution",
+                "summary": "Forgot password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "forgot password req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/contribution.ContributionRes"
-                        }
+                        "description": ""
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Update a contribution",
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Set a new password using the token from the reset email",
                 "consumes": [
                     "application/json"
                 ],
@@ -694,43 +578,35 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
+                    "Auth"
                 ],
-                "summary": "Update a contribution",
+                "summary": "Reset password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "create",
+                        "description": "reset password req",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/contribution.ContributionUpdateReq"
+                            "$ref": "#/definitions/authz.ResetPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/contribution.ContributionRes"
-                        }
+                        "description": ""
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/permissions": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a contribution",
+                "description": "Permissions of the logged in user, with the permissions on a contribution or contribute session when their id is given",
                 "consumes": [
                     "application/json"
                 ],
@@ -738,33 +614,36 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
+                    "Auth"
                 ],
-                "summary": "Delete a contribution",
+                "summary": "Effective permissions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ContributeSessionId adds the permissions on this contribute session",
+                        "name": "contributeSessionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ContributionId adds the permissions on this contribution",
+                        "name": "contributionId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authz.PermissionsResponse"
+                        }
                     }
                 }
             }
         },
-        "/contributions/{id}/images": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Get contribution images",
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token, the refresh token is rotated",
                 "consumes": [
                     "application/json"
                 ],
@@ -772,39 +651,38 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
+                    "Auth"
                 ],
-                "summary": "Get contribution images",
+                "summary": "Refresh access token",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "refresh req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/contribution.ImageRes"
-                            }
+                            "$ref": "#/definitions/authz.LoginResponse"
                         }
                     }
                 }
             }
         },
-        "/contributions/{id}/status": {
-            "post": {
+        "/auth/roles": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update contribution status",
+                "description": "The role and permission matrix currently enforced by the api",
                 "consumes": [
                     "application/json"
                 ],
@@ -812,42 +690,27 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Contributions"
-                ],
-                "summary": "Update contribution status",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "update",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/contribution.ContributionStatusReq"
-                        }
-                    }
+                    "Auth"
                 ],
+                "summary": "Role matrix",
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authz.RoleMatrixResponse"
+                        }
                     }
                 }
             }
         },
-        "/faculties": {
+        "/comments": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List faculties",
+                "description": "List comments",
                 "consumes": [
                     "application/json"
                 ],
@@ -855,18 +718,24 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Faculties"
+                    "Comments"
                 ],
-                "summary": "List faculties",
+                "summary": "List comments",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "contributionId",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "name": "page",
+                        "type": "string",
+                        "name": "next",
                         "in": "query"
                     }
                 ],
@@ -874,7 +743,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/faculty.PaginateComposition"
+                            "$ref": "#/definitions/comment.CursorPaginateComposition"
                         }
                     }
                 }
@@ -885,7 +754,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a faculty",
+                "description": "Create a comment",
                 "consumes": [
                     "application/json"
                 ],
@@ -893,9 +762,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Faculties"
+                    "Comments"
                 ],
-                "summary": "Create a faculty",
+                "summary": "Create a comment",
                 "parameters": [
                     {
                         "description": "create",
@@ -903,7 +772,7 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/faculty.FacultyCreateReq"
+                            "$ref": "#/definitions/comment.CommentCreateReq"
                         }
                     }
                 ],
@@ -911,20 +780,20 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/faculty.FacultyResponse"
+                            "$ref": "#/definitions/comment.CommentRes"
                         }
                     }
                 }
             }
         },
-        "/faculties/{id}": {
+        "/comments/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get faculty by ID",
+                "description": "get comment by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -932,12 +801,12 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Faculties"
+                    "Comments"
                 ],
-                "summary": "Show a faculty",
+                "summary": "Show a comment",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
@@ -948,7 +817,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/faculty.FacultyResponse"
+                            "$ref": "#/definitions/comment.CommentRes"
                         }
                     }
                 }
@@ -959,7 +828,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a faculty",
+                "description": "Update a comment",
                 "consumes": [
                     "application/json"
                 ],
@@ -967,24 +836,24 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Faculties"
+                    "Comments"
                 ],
-                "summary": "Update a faculty",
+                "summary": "Update a comment",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "create",
+                        "description": "update",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/faculty.FacultyUpdateReq"
+                            "$ref": "#/definitions/comment.CommentUpdateReq"
                         }
                     }
                 ],
@@ -992,7 +861,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/faculty.FacultyResponse"
+                            "$ref": "#/definitions/comment.CommentRes"
                         }
                     }
                 }
@@ -1003,7 +872,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a faculty",
+                "description": "Delete a comment",
                 "consumes": [
                     "application/json"
                 ],
@@ -1011,12 +880,12 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Faculties"
+                    "Comments"
                 ],
-                "summary": "Delete a faculty",
+                "summary": "Delete a comment",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
@@ -1030,14 +899,14 @@ var doc = `{
                 }
             }
         },
-        "/statistics/admin-dashboard": {
+        "/contribute-sessions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Admin Dashboard Data",
+                "description": "List Contribute Sessions",
                 "consumes": [
                     "application/json"
                 ],
@@ -1045,27 +914,43 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Statistics"
+                    "Contribute Sessions"
+                ],
+                "summary": "List Contribute Sessions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "FacultyId lists the sessions open to the faculty",
+                        "name": "facultyId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Admin Dashboard Data",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/statistic.AdminDashboard"
+                            "$ref": "#/definitions/contributesession.PaginateComposition"
                         }
                     }
                 }
-            }
-        },
-        "/statistics/contribution-faculty-chart": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Contribution group by faculty data",
+                "description": "Create a Contribute Session, a session overlapping another session of one of its faculties is a conflict listing the overlapping sessionIds in data",
                 "consumes": [
                     "application/json"
                 ],
@@ -1073,39 +958,38 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Statistics"
+                    "Contribute Sessions"
                 ],
-                "summary": "Contribution group by faculty data",
+                "summary": "Create a Contribute Session",
                 "parameters": [
                     {
-                        "enum": [
-                            "accepted",
-                            "reviewing",
-                            "rejected"
-                        ],
-                        "type": "string",
-                        "name": "status",
-                        "in": "query"
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contributesession.SessionCreateReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/statistic.ContributionFacultyChart"
+                            "$ref": "#/definitions/contributesession.SessionRes"
                         }
                     }
                 }
             }
         },
-        "/statistics/contribution-student-chart": {
+        "/contribute-sessions/current": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Contribution group by student data",
+                "description": "Get the session running now for the faculty of the logged in user, users without faculty get any running session",
                 "consumes": [
                     "application/json"
                 ],
@@ -1113,64 +997,43 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Statistics"
-                ],
-                "summary": "Contribution group by student data",
-                "parameters": [
-                    {
-                        "enum": [
-                            "accepted",
-                            "reviewing",
-                            "rejected"
-                        ],
-                        "type": "string",
-                        "name": "status",
-                        "in": "query"
-                    }
+                    "Contribute Sessions"
                 ],
+                "summary": "Get Current Contribute Session",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/statistic.ContributionStudentChart"
+                            "$ref": "#/definitions/contributesession.SessionRes"
                         }
                     }
                 }
             }
         },
-        "/storage/upload": {
-            "post": {
+        "/contribute-sessions/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Upload file",
+                "description": "get Contribute Session by ID",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Storage"
+                    "Contribute Sessions"
                 ],
-                "summary": "Upload file",
+                "summary": "Show a Contribute Session",
                 "parameters": [
                     {
-                        "enum": [
-                            "document",
-                            "image"
-                        ],
-                        "type": "string",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "file",
-                        "description": "upload file",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -1178,20 +1041,18 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/media.UploadResult"
+                            "$ref": "#/definitions/contributesession.SessionRes"
                         }
                     }
                 }
-            }
-        },
-        "/system-data": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get System Data",
+                "description": "Update a Contribute Session, a session overlapping another session of one of its faculties is a conflict listing the overlapping sessionIds in data",
                 "consumes": [
                     "application/json"
                 ],
@@ -1199,30 +1060,77 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System Data"
+                    "Contribute Sessions"
+                ],
+                "summary": "Update a Contribute Session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contributesession.SessionUpdateReq"
+                        }
+                    }
                 ],
-                "summary": "Get System Data",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/systemdata.DataRes"
-                            }
+                            "$ref": "#/definitions/contributesession.SessionRes"
                         }
                     }
                 }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a Contribute Session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contribute Sessions"
+                ],
+                "summary": "Delete a Contribute Session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
             }
         },
-        "/system-data/{id}": {
-            "put": {
+        "/contribute-sessions/{id}/export": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update system data",
+                "description": "Export a Contribute Session",
                 "consumes": [
                     "application/json"
                 ],
@@ -1230,25 +1138,16 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "System Data"
+                    "Contribute Sessions"
                 ],
-                "summary": "Update system data",
+                "summary": "Export a Contribute Session",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "create",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/systemdata.DataUpdateReq"
-                        }
                     }
                 ],
                 "responses": {
@@ -1258,14 +1157,14 @@ var doc = `{
                 }
             }
         },
-        "/users": {
+        "/contributions": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List users",
+                "description": "List contributions",
                 "consumes": [
                     "application/json"
                 ],
@@ -1273,10 +1172,29 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "List users",
+                "summary": "List contributions",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "contributionSessionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "facultyId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "overdue",
+                            "at_risk"
+                        ],
+                        "type": "string",
+                        "name": "feedback",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "name": "limit",
@@ -1289,14 +1207,23 @@ var doc = `{
                     },
                     {
                         "enum": [
-                            "admin",
-                            "marketing_manager",
-                            "marketing_coordinator",
-                            "student",
-                            "guest"
+                            "draft",
+                            "submitted",
+                            "under_review",
+                            "changes_requested",
+                            "resubmitted",
+                            "accepted",
+                            "rejected",
+                            "selected",
+                            "withdrawn"
                         ],
                         "type": "string",
-                        "name": "role",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "studentId",
                         "in": "query"
                     }
                 ],
@@ -1304,7 +1231,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.PaginateComposition"
+                            "$ref": "#/definitions/contribution.PaginateComposition"
                         }
                     }
                 }
@@ -1315,7 +1242,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a user",
+                "description": "Submit a contribution, or save it as a draft only its author sees and submits later\nthrough the status endpoint",
                 "consumes": [
                     "application/json"
                 ],
@@ -1323,17 +1250,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "Create a user",
+                "summary": "Create a contribution",
                 "parameters": [
                     {
-                        "description": "create user",
-                        "name": "user",
+                        "description": "create",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.UserCreateReq"
+                            "$ref": "#/definitions/contribution.ContributionCreateReq"
                         }
                     }
                 ],
@@ -1341,20 +1268,20 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.UserResponse"
+                            "$ref": "#/definitions/contribution.ContributionRes"
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/contributions/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get user by ID",
+                "description": "get contribution by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1362,13 +1289,13 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "Show a user",
+                "summary": "Show a contribution",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1378,18 +1305,18 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.UserResponse"
+                            "$ref": "#/definitions/contribution.ContributionRes"
                         }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a user",
+                "description": "Update a contribution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1397,31 +1324,43 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "Delete a user",
+                "summary": "Update a contribution",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contribution.ContributionUpdateReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/contribution.ContributionRes"
+                        }
                     }
                 }
             },
-            "patch": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a user",
+                "description": "Delete a contribution",
                 "consumes": [
                     "application/json"
                 ],
@@ -1429,45 +1368,73 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "Update a user",
+                "summary": "Delete a contribution",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/contributions/{id}/images": {
+            "get": {
+                "security": [
                     {
-                        "description": "update user",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.UserUpdateReq"
-                        }
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get contribution images",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contributions"
+                ],
+                "summary": "Get contribution images",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user.UserResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/contribution.ImageRes"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{id}/status": {
+        "/contributions/{id}/status": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update user status",
+                "description": "Move the contribution along its workflow, the author submits, resubmits and withdraws\nand the coordinator reviews. A conflict lists the allowed transitions.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1475,16 +1442,25 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Contributions"
                 ],
-                "summary": "Update user status",
+                "summary": "Update contribution status",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/contribution.ContributionStatusReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -1493,78 +1469,2903 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "article.ArticleRes": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "updatedAt": {
-                    "type": "string"
-                },
-                "versions": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/article.VersionRes"
-                    }
-                }
-            }
         },
-        "article.VersionRes": {
-            "type": "object",
-            "properties": {
-                "articleId": {
-                    "type": "integer"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "hash": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "linkOriginal": {
-                    "type": "string"
-                },
-                "linkOriginalCdn": {
-                    "type": "string"
-                },
-                "linkPdf": {
-                    "type": "string"
-                },
-                "linkPdfCdn": {
-                    "type": "string"
+        "/contributions/{id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Status changes, edits, article versions and comments of a contribution, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contributions"
+                ],
+                "summary": "Get contribution timeline",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/contribution.TimelineEventRes"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "authz.LoginRequest": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        "/faculties": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List faculties, archived faculties are hidden unless includeArchived is set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "List faculties",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "IncludeArchived lists the archived faculties too",
+                        "name": "includeArchived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.PaginateComposition"
+                        }
+                    }
                 }
-            }
-        },
-        "authz.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a faculty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Create a faculty",
+                "parameters": [
+                    {
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyCreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/faculties/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get faculty by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Show a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a faculty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Update a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyUpdateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a faculty that never had users or contributions, archive the others",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Delete a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/faculties/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Archive a faculty, its users and contributions are kept but no user can join it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Archive a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore an archived faculty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Restore an archived faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/faculties/{id}/coordinators": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the assigned marketing coordinators and the deputy of a faculty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "List the coordinators of a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/faculty.CoordinatorResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the coordinators and the deputy of a faculty, they must be marketing coordinators of the faculty.\nNew contributions are notified to them, or to every marketing coordinator of the faculty when none is assigned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Assign the coordinators of a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "coordinators",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/faculty.CoordinatorsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/faculty.CoordinatorResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/faculties/{id}/settings": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the contact email, the guest access and the maximum contributions per student in a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Faculties"
+                ],
+                "summary": "Update the settings of a faculty",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultySettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/faculty.FacultyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get profile of the logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Get my profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.ProfileRes"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update profile of the logged in user, a new access token is returned when the name changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Update my profile",
+                "parameters": [
+                    {
+                        "description": "update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.ProfileUpdateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.ProfileUpdateRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Not allowed for roles where two-factor authentication is mandatory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "authenticator or recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/me/2fa/activate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confirm the authenticator with its first code, returns the recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Activate two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.RecoveryCodesRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace all recovery codes, requires a current authenticator code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authz.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.RecoveryCodesRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generate a new authenticator secret, it becomes active after /me/2fa/activate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Set up two-factor authentication",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/authz.TwoFactorSetupResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload avatar of the logged in user",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Upload my avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "avatar image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.ProfileRes"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove avatar of the logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Remove my avatar",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.ProfileRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change password of the logged in user, other sessions are logged out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Change my password",
+                "parameters": [
+                    {
+                        "description": "change password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.PasswordChangeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every permission that can be granted to a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "List permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.PermissionRes"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/erasures": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Anonymize the profile and comments of a user and scrub their contributions, accepted\ncontributions are kept as magazine content unless the retention policy erases them.\nThe user is disabled and cannot be restored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Privacy"
+                ],
+                "summary": "Erase the personal data of a user",
+                "parameters": [
+                    {
+                        "description": "erase req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/privacy.EraseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.EraseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/exports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queue a zip of the profile, contributions, article versions, images and comments of a user.\nPoll the export until it is done to get the download link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Privacy"
+                ],
+                "summary": "Export the personal data of a user",
+                "parameters": [
+                    {
+                        "description": "export req",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ExportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ExportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the state of an export by ID, the download link expires after 15 minutes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Privacy"
+                ],
+                "summary": "Show a data export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ExportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List roles with their permissions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.RoleRes"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a role, changes apply to every api instance immediately",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Create a role",
+                "parameters": [
+                    {
+                        "description": "create role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rbac.RoleCreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rbac.RoleRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get role by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Show a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rbac.RoleRes"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a role that is not built-in and not assigned to any user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a role, permissions are replaced when given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Update a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rbac.RoleUpdateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rbac.RoleRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/statistics/admin-dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Admin Dashboard Data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Admin Dashboard Data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/statistic.AdminDashboard"
+                        }
+                    }
+                }
+            }
+        },
+        "/statistics/contribution-faculty-chart": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Contribution group by faculty data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Contribution group by faculty data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "sessionId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "submitted",
+                            "under_review",
+                            "changes_requested",
+                            "resubmitted",
+                            "accepted",
+                            "rejected",
+                            "selected",
+                            "withdrawn"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/statistic.ContributionFacultyChart"
+                        }
+                    }
+                }
+            }
+        },
+        "/statistics/contribution-student-chart": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Contribution group by student data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Contribution group by student data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "sessionId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "submitted",
+                            "under_review",
+                            "changes_requested",
+                            "resubmitted",
+                            "accepted",
+                            "rejected",
+                            "selected",
+                            "withdrawn"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/statistic.ContributionStudentChart"
+                        }
+                    }
+                }
+            }
+        },
+        "/statistics/feedback-exceptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Contributions that did not get coordinator feedback within the deadline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Feedback exception report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "sessionId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/statistic.FeedbackExceptionReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/storage/upload": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload file",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Storage"
+                ],
+                "summary": "Upload file",
+                "parameters": [
+                    {
+                        "enum": [
+                            "document",
+                            "image"
+                        ],
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "upload file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/media.UploadResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/system-data": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get System Data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System Data"
+                ],
+                "summary": "Get System Data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/systemdata.DataRes"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/system-data/term-and-condition": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the current version of the term and condition, each submission carries\nthe version id the student accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System Data"
+                ],
+                "summary": "Get the current term and condition",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/systemdata.TermAndConditionRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/system-data/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update system data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System Data"
+                ],
+                "summary": "Update system data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "create",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/systemdata.DataUpdateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/user-imports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create users from a csv or xlsx file with the columns name, email, role, faculty (name or id) and status.\nNothing is created unless every row is valid, the per-row report is returned as error data.\nFiles of more than 50 rows are imported by the worker, poll the import until it is done.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Import users",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv or xlsx file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate the file",
+                        "name": "dryRun",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "invite active users to choose their password instead of setting a random one",
+                        "name": "invite",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/userimport.ImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user-imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the state and report of an import by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Show a user import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/userimport.ImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List users, search by name or email and filter or sort by id, name, email, role, status, facultyId, serviceAccount, createdAt and updatedAt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "example": [
+                            "createdAt:gte:2021-04-01"
+                        ],
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "student",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "serviceAccount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-createdAt,name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "disable",
+                            "pending"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.PaginateComposition"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a user, without password the user is invited by email to choose one and stays pending until then",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "create user",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UserCreateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.UserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Export the users matching the filters as a csv or xlsx file",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Export users",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "example": [
+                            "createdAt:gte:2021-04-01"
+                        ],
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "example": "csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "student",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "serviceAccount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-createdAt,name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "disable",
+                            "pending"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Show a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.UserResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update user",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UserUpdateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.UserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/faculty-history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the faculties a user belonged to with their effective dates, the current one first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Show the faculty history of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.FacultyMembershipResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/invitation": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send a new invitation link to a pending user, the previous link stops working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Resend an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Make the invitation link of a pending user unusable",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Cancel an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/users/{id}/status": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update user status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "article.ArticleRes": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/article.VersionRes"
+                    }
+                }
+            }
+        },
+        "article.VersionRes": {
+            "type": "object",
+            "properties": {
+                "articleId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "linkOriginal": {
+                    "type": "string"
+                },
+                "linkOriginalCdn": {
+                    "type": "string"
+                },
+                "linkPdf": {
+                    "type": "string"
+                },
+                "linkPdfCdn": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.AcceptInvitationRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.ApiKeyCreateRequest": {
+            "type": "object",
+            "properties": {
+                "allowedIps": {
+                    "description": "AllowedIps restricts the key to these addresses or cidr ranges, any address when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/8"
+                    ]
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userId": {
+                    "description": "UserId creates the key for a service account, defaults to the logged in user",
+                    "type": "integer"
+                }
+            }
+        },
+        "authz.ApiKeyCreateResponse": {
+            "type": "object",
+            "properties": {
+                "allowedIps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is only shown once, send it in the X-API-Key header",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "lastUsedIp": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the start of the key, e.g. mcm_1a2b3c4d5e6f",
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "authz.ApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "allowedIps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "lastUsedIp": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Prefix is the start of the key, e.g. mcm_1a2b3c4d5e6f",
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "authz.ApiKeyUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "allowedIps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "allowWrite": {
+                    "description": "AllowWrite lets the administrator change data as the user, deletions stay blocked",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "Reason is kept in the audit log, e.g. the support ticket",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "authz.ImpersonateResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "AccessToken acts as the user until ExpiresAt, it cannot be refreshed",
+                    "type": "string"
+                },
+                "allowWrite": {
+                    "type": "boolean"
+                },
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
+                    "type": "string"
+                },
+                "avatar": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "serviceAccount": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
+                },
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
+                    "type": "string"
+                },
+                "avatar": {
+                    "type": "string"
+                },
+                "challengeToken": {
+                    "description": "ChallengeToken is returned instead of the tokens when the login needs a second factor,\nit is exchanged on /auth/login/2fa, or on /auth/2fa/setup and /auth/2fa/activate\nwhen TwoFactorSetupRequired is set.",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "serviceAccount": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
+                },
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
+                },
+                "twoFactorRequired": {
+                    "type": "boolean"
+                },
+                "twoFactorSetupRequired": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.OidcAuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.OidcCallbackRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.PermissionsResponse": {
+            "type": "object",
+            "properties": {
+                "contributeSession": {
+                    "$ref": "#/definitions/authz.ResourcePermissions"
+                },
+                "contribution": {
+                    "$ref": "#/definitions/authz.ResourcePermissions"
+                },
+                "permissions": {
+                    "description": "Permissions granted by the role, some of them only apply to part of the resources",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.ResourcePermissions": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "authz.RoleMatrixEntry": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "requiresFaculty": {
+                    "type": "boolean"
+                },
+                "requiresTwoFactor": {
+                    "type": "boolean"
+                },
+                "system": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authz.RoleMatrixResponse": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/authz.RoleMatrixEntry"
+                    }
+                }
+            }
+        },
+        "authz.TwoFactorActivateResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
+                    "type": "string"
+                },
+                "avatar": {
+                    "type": "string"
+                },
+                "challengeToken": {
+                    "description": "ChallengeToken is returned instead of the tokens when the login needs a second factor,\nit is exchanged on /auth/login/2fa, or on /auth/2fa/setup and /auth/2fa/activate\nwhen TwoFactorSetupRequired is set.",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "recoveryCodes": {
+                    "description": "RecoveryCodes are only shown once",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "serviceAccount": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
+                },
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
+                },
+                "twoFactorRequired": {
+                    "type": "boolean"
+                },
+                "twoFactorSetupRequired": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.TwoFactorCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.TwoFactorLoginRequest": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Code is either the current authenticator code or an unused recovery code",
+                    "type": "string"
+                }
+            }
+        },
+        "authz.TwoFactorSetupRequest": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authz.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "provisioningUri": {
+                    "description": "ProvisioningUri is the otpauth:// uri to render as QR code",
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "comment.CommentCreateReq": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "contributionId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "comment.CommentRes": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "edited": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/user.UserResponse"
+                }
+            }
+        },
+        "comment.CommentUpdateReq": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                }
+            }
+        },
+        "comment.CursorPaginateComposition": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comment.CommentRes"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                }
+            }
+        },
+        "contributesession.PaginateComposition": {
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contributesession.SessionRes"
+                    }
+                },
+                "lastPage": {
+                    "type": "integer"
+                },
+                "perPage": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "contributesession.SessionCreateReq": {
+            "type": "object",
+            "properties": {
+                "closureTime": {
+                    "type": "string"
+                },
+                "facultyIds": {
+                    "description": "FacultyIds opens the session to some faculties only, leave it empty for every faculty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "finalClosureTime": {
+                    "type": "string"
+                },
+                "openTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "contributesession.SessionRes": {
+            "type": "object",
+            "properties": {
+                "closureTime": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "exportedAssets": {
+                    "type": "string"
+                },
+                "exportedAssetsCdn": {
+                    "type": "string"
+                },
+                "facultyIds": {
+                    "description": "FacultyIds the session is open to, empty when it is open to every faculty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "finalClosureTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "openTime": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "contributesession.SessionUpdateReq": {
+            "type": "object",
+            "properties": {
+                "closureTime": {
+                    "type": "string"
+                },
+                "facultyIds": {
+                    "description": "FacultyIds opens the session to some faculties only, leave it empty for every faculty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "finalClosureTIme": {
+                    "type": "string"
+                },
+                "openTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.ArticleReq": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.ContributionCreateReq": {
+            "type": "object",
+            "properties": {
+                "acceptedTermVersionId": {
+                    "description": "AcceptedTermVersionId is the version of the term and condition the student\naccepted, it is required to submit",
+                    "type": "integer"
+                },
+                "article": {
+                    "$ref": "#/definitions/contribution.ArticleReq"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "draft": {
+                    "description": "Draft saves the contribution without submitting it, the article, images and\ntitle can be completed later",
+                    "type": "boolean"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contribution.ImageCreateReq"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.ContributionRes": {
+            "type": "object",
+            "properties": {
+                "articleId": {
+                    "type": "integer"
+                },
+                "contributeSessionId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "feedbackDueAt": {
+                    "type": "string"
+                },
+                "feedbackGivenAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusReason": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/contribution.UserRes"
+                }
+            }
+        },
+        "contribution.ContributionStatusReq": {
+            "type": "object",
+            "properties": {
+                "acceptedTermVersionId": {
+                    "description": "AcceptedTermVersionId is required to submit a draft, see ContributionCreateReq",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Reason is required when rejecting or requesting changes",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "submitted",
+                        "under_review",
+                        "changes_requested",
+                        "resubmitted",
+                        "accepted",
+                        "rejected",
+                        "selected",
+                        "withdrawn"
+                    ]
+                }
+            }
+        },
+        "contribution.ContributionUpdateReq": {
+            "type": "object",
+            "properties": {
+                "article": {
+                    "$ref": "#/definitions/contribution.ArticleReq"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contribution.ImageCreateReq"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.ImageCreateReq": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.ImageRes": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "contribution.PaginateComposition": {
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contribution.ContributionRes"
+                    }
+                },
+                "lastPage": {
+                    "type": "integer"
+                },
+                "perPage": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "contribution.TimelineEventRes": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "$ref": "#/definitions/contribution.UserRes"
+                },
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "status_changed",
+                        "article_version_uploaded",
+                        "images_changed",
+                        "title_changed",
+                        "description_changed",
+                        "commented"
+                    ]
+                }
+            }
+        },
+        "contribution.UserRes": {
+            "type": "object",
+            "properties": {
                 "email": {
                     "type": "string"
                 },
@@ -1579,72 +4380,99 @@ var doc = `{
                 },
                 "role": {
                     "type": "string"
+                }
+            }
+        },
+        "faculty.CoordinatorResponse": {
+            "type": "object",
+            "properties": {
+                "assignedAt": {
+                    "type": "string"
                 },
-                "status": {
+                "deputy": {
+                    "type": "boolean"
+                },
+                "email": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "name": {
                     "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "comment.CommentCreateReq": {
+        "faculty.CoordinatorsReq": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
+                "coordinatorIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "contributionId": {
+                "deputyId": {
                     "type": "integer"
                 }
             }
         },
-        "comment.CommentRes": {
+        "faculty.FacultyCreateReq": {
             "type": "object",
             "properties": {
-                "content": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "faculty.FacultyResponse": {
+            "type": "object",
+            "properties": {
+                "archivedAt": {
                     "type": "string"
                 },
                 "createdAt": {
                     "type": "string"
                 },
-                "edited": {
-                    "type": "boolean"
-                },
                 "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
+                "settings": {
+                    "$ref": "#/definitions/faculty.FacultySettings"
+                },
                 "updatedAt": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/user.UserResponse"
                 }
             }
         },
-        "comment.CommentUpdateReq": {
+        "faculty.FacultySettings": {
             "type": "object",
             "properties": {
-                "content": {
+                "contactEmail": {
                     "type": "string"
+                },
+                "guestAccess": {
+                    "description": "GuestAccess lets the guests of the faculty read its accepted contributions",
+                    "type": "boolean"
+                },
+                "maxContributionsPerStudent": {
+                    "description": "MaxContributionsPerStudent limits the contributions of a student in a session, null is unlimited",
+                    "type": "integer"
                 }
             }
         },
-        "comment.CursorPaginateComposition": {
+        "faculty.FacultyUpdateReq": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/comment.CommentRes"
-                    }
-                },
-                "next": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "contributesession.PaginateComposition": {
+        "faculty.PaginateComposition": {
             "type": "object",
             "properties": {
                 "currentPage": {
@@ -1653,7 +4481,7 @@ var doc = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/contributesession.SessionRes"
+                        "$ref": "#/definitions/faculty.FacultyResponse"
                     }
                 },
                 "lastPage": {
@@ -1667,280 +4495,326 @@ var doc = `{
                 }
             }
         },
-        "contributesession.SessionCreateReq": {
+        "media.UploadResult": {
             "type": "object",
             "properties": {
-                "closureTime": {
-                    "type": "string"
-                },
-                "finalClosureTime": {
-                    "type": "string"
-                },
-                "openTime": {
+                "key": {
                     "type": "string"
                 }
             }
         },
-        "contributesession.SessionRes": {
+        "privacy.EraseRequest": {
             "type": "object",
             "properties": {
-                "closureTime": {
-                    "type": "string"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "exportedAssets": {
-                    "type": "string"
-                },
-                "exportedAssetsCdn": {
-                    "type": "string"
-                },
-                "finalClosureTime": {
-                    "type": "string"
-                },
-                "id": {
+                "userId": {
                     "type": "integer"
-                },
-                "openTime": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
                 }
             }
         },
-        "contributesession.SessionUpdateReq": {
+        "privacy.EraseResponse": {
             "type": "object",
             "properties": {
-                "closureTime": {
-                    "type": "string"
+                "deletedFiles": {
+                    "description": "DeletedFiles counts the files removed from the media bucket, files that could\nnot be deleted are logged",
+                    "type": "integer"
                 },
-                "finalClosureTIme": {
-                    "type": "string"
+                "retainedContributions": {
+                    "description": "RetainedContributions are accepted contributions kept as magazine content",
+                    "type": "integer"
                 },
-                "openTime": {
-                    "type": "string"
+                "scrubbedComments": {
+                    "type": "integer"
+                },
+                "scrubbedContributions": {
+                    "description": "ScrubbedContributions lost their title, description, article and images",
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "contribution.ArticleReq": {
+        "privacy.ExportRequest": {
             "type": "object",
             "properties": {
-                "link": {
-                    "type": "string"
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "contribution.ContributionCreateReq": {
+        "privacy.ExportResponse": {
             "type": "object",
             "properties": {
-                "article": {
-                    "$ref": "#/definitions/contribution.ArticleReq"
+                "createdAt": {
+                    "type": "string"
                 },
-                "description": {
+                "createdBy": {
+                    "type": "integer"
+                },
+                "error": {
                     "type": "string"
                 },
-                "images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/contribution.ImageCreateReq"
-                    }
+                "finishedAt": {
+                    "type": "string"
                 },
-                "title": {
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "description": "Link downloads the zip once the export is done, it expires after 15 minutes",
                     "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "done",
+                        "failed"
+                    ]
+                },
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "contribution.ContributionRes": {
+        "profile.ProfileRes": {
             "type": "object",
             "properties": {
-                "articleId": {
-                    "type": "integer"
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
+                    "type": "string"
                 },
-                "contributeSessionId": {
-                    "type": "integer"
+                "avatar": {
+                    "type": "string"
+                },
+                "avatarLink": {
+                    "type": "string"
                 },
                 "createdAt": {
                     "type": "string"
                 },
-                "description": {
+                "email": {
                     "type": "string"
                 },
+                "facultyId": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "impersonatedBy": {
+                    "description": "ImpersonatedBy is the id of the administrator acting as this user",
+                    "type": "integer"
                 },
-                "title": {
+                "name": {
                     "type": "string"
                 },
-                "updatedAt": {
-                    "type": "string"
+                "notificationPreferences": {
+                    "$ref": "#/definitions/user.NotificationPreferences"
                 },
-                "user": {
-                    "$ref": "#/definitions/contribution.UserRes"
-                }
-            }
-        },
-        "contribution.ContributionStatusReq": {
-            "type": "object",
-            "properties": {
-                "status": {
+                "role": {
                     "type": "string"
-                }
-            }
-        },
-        "contribution.ContributionUpdateReq": {
-            "type": "object",
-            "properties": {
-                "article": {
-                    "$ref": "#/definitions/contribution.ArticleReq"
                 },
-                "description": {
-                    "type": "string"
+                "serviceAccount": {
+                    "type": "boolean"
                 },
-                "images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/contribution.ImageCreateReq"
-                    }
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
                 },
-                "title": {
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
+                },
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "contribution.ImageCreateReq": {
+        "profile.ProfileUpdateRes": {
             "type": "object",
             "properties": {
-                "key": {
+                "accessToken": {
+                    "description": "AccessToken is only set when the change made the current token stale",
                     "type": "string"
                 },
-                "title": {
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
                     "type": "string"
-                }
-            }
-        },
-        "contribution.ImageRes": {
-            "type": "object",
-            "properties": {
-                "key": {
+                },
+                "avatar": {
                     "type": "string"
                 },
-                "link": {
+                "avatarLink": {
                     "type": "string"
                 },
-                "title": {
+                "createdAt": {
                     "type": "string"
-                }
-            }
-        },
-        "contribution.PaginateComposition": {
-            "type": "object",
-            "properties": {
-                "currentPage": {
-                    "type": "integer"
                 },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/contribution.ContributionRes"
-                    }
+                "email": {
+                    "type": "string"
                 },
-                "lastPage": {
+                "facultyId": {
                     "type": "integer"
                 },
-                "perPage": {
+                "id": {
                     "type": "integer"
                 },
-                "total": {
+                "impersonatedBy": {
+                    "description": "ImpersonatedBy is the id of the administrator acting as this user",
                     "type": "integer"
-                }
-            }
-        },
-        "contribution.UserRes": {
-            "type": "object",
-            "properties": {
-                "email": {
+                },
+                "name": {
                     "type": "string"
                 },
-                "facultyId": {
-                    "type": "integer"
+                "notificationPreferences": {
+                    "$ref": "#/definitions/user.NotificationPreferences"
                 },
-                "id": {
-                    "type": "integer"
+                "role": {
+                    "type": "string"
+                },
+                "serviceAccount": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
                 },
-                "name": {
-                    "type": "string"
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
                 },
-                "role": {
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "faculty.FacultyCreateReq": {
+        "profile.RecoveryCodesRes": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "recoveryCodes": {
+                    "description": "RecoveryCodes are only shown once",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "faculty.FacultyResponse": {
+        "rbac.PermissionRes": {
             "type": "object",
             "properties": {
-                "createdAt": {
+                "description": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
                 "name": {
                     "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
                 }
             }
         },
-        "faculty.FacultyUpdateReq": {
+        "rbac.RoleCreateReq": {
             "type": "object",
             "properties": {
-                "name": {
+                "conditions": {
+                    "description": "e.g. {\"contribution.read\": \"faculty \u0026\u0026 published\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "description": {
                     "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "faculty_editor"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "requiresFaculty": {
+                    "type": "boolean"
+                },
+                "requiresTwoFactor": {
+                    "type": "boolean"
                 }
             }
         },
-        "faculty.PaginateComposition": {
+        "rbac.RoleRes": {
             "type": "object",
             "properties": {
-                "currentPage": {
-                    "type": "integer"
+                "conditions": {
+                    "description": "grant conditions by permission, permissions without condition apply to every resource",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
-                "data": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/faculty.FacultyResponse"
+                        "type": "string"
                     }
                 },
-                "lastPage": {
-                    "type": "integer"
+                "requiresFaculty": {
+                    "type": "boolean"
                 },
-                "perPage": {
-                    "type": "integer"
+                "requiresTwoFactor": {
+                    "type": "boolean"
                 },
-                "total": {
-                    "type": "integer"
+                "system": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "media.UploadResult": {
+        "rbac.RoleUpdateReq": {
             "type": "object",
             "properties": {
-                "key": {
+                "conditions": {
+                    "description": "replaced together with the permissions, the kept permissions keep their conditions\nwhen not given, ignored when permissions are not given",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "description": {
                     "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "requiresFaculty": {
+                    "type": "boolean"
+                },
+                "requiresTwoFactor": {
+                    "type": "boolean"
                 }
             }
         },
@@ -1984,6 +4858,12 @@ var doc = `{
                 },
                 "session": {
                     "$ref": "#/definitions/statistic.Session"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/statistic.Session"
+                    }
                 }
             }
         },
@@ -1998,6 +4878,12 @@ var doc = `{
                 },
                 "session": {
                     "$ref": "#/definitions/statistic.Session"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/statistic.Session"
+                    }
                 }
             }
         },
@@ -2032,12 +4918,65 @@ var doc = `{
                 }
             }
         },
+        "statistic.FeedbackException": {
+            "type": "object",
+            "properties": {
+                "contributionId": {
+                    "type": "integer"
+                },
+                "daysLate": {
+                    "type": "integer"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "facultyName": {
+                    "type": "string"
+                },
+                "feedbackDueAt": {
+                    "type": "string"
+                },
+                "feedbackGivenAt": {
+                    "type": "string"
+                },
+                "studentName": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "statistic.FeedbackExceptionReport": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/statistic.FeedbackException"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/statistic.Session"
+                    }
+                }
+            }
+        },
         "statistic.Session": {
             "type": "object",
             "properties": {
                 "closureTime": {
                     "type": "string"
                 },
+                "facultyIds": {
+                    "description": "FacultyIds the session is open to, empty when it is open to every faculty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "finalClosureTime": {
                     "type": "string"
                 },
@@ -2079,6 +5018,58 @@ var doc = `{
                 }
             }
         },
+        "systemdata.TermAndConditionRes": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Accepted tells whether the logged in user accepted this version",
+                    "type": "boolean"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "versionId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "user.FacultyMembershipResponse": {
+            "type": "object",
+            "properties": {
+                "createdBy": {
+                    "description": "CreatedBy is the user who made the transfer, it is empty for the initial faculty\nand for changes made by single sign-on",
+                    "type": "integer"
+                },
+                "endedAt": {
+                    "type": "string"
+                },
+                "facultyId": {
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "newContributionEmail": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "user.NotificationPreferencesUpdate": {
+            "type": "object",
+            "properties": {
+                "newContributionEmail": {
+                    "type": "boolean"
+                }
+            }
+        },
         "user.PaginateComposition": {
             "type": "object",
             "properties": {
@@ -2102,6 +5093,28 @@ var doc = `{
                 }
             }
         },
+        "user.PasswordChangeReq": {
+            "type": "object",
+            "properties": {
+                "currentPassword": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.ProfileUpdateReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "notificationPreferences": {
+                    "$ref": "#/definitions/user.NotificationPreferencesUpdate"
+                }
+            }
+        },
         "user.UserCreateReq": {
             "type": "object",
             "properties": {
@@ -2115,17 +5128,16 @@ var doc = `{
                     "type": "string"
                 },
                 "password": {
+                    "description": "Password is left empty to invite the user, they receive a link to choose it\nand stay pending until they do",
                     "type": "string"
                 },
                 "role": {
                     "type": "string",
-                    "enum": [
-                        "admin",
-                        "marketing_manager",
-                        "marketing_coordinator",
-                        "student",
-                        "guest"
-                    ]
+                    "example": "student"
+                },
+                "serviceAccount": {
+                    "description": "ServiceAccount creates an account for integrations, it has no password and\nonly authenticates with api keys",
+                    "type": "boolean"
                 },
                 "status": {
                     "type": "string",
@@ -2139,6 +5151,13 @@ var doc = `{
         "user.UserResponse": {
             "type": "object",
             "properties": {
+                "anonymizedAt": {
+                    "description": "AnonymizedAt is set once the personal data of the user was erased",
+                    "type": "string"
+                },
+                "avatar": {
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
@@ -2157,8 +5176,20 @@ var doc = `{
                 "role": {
                     "type": "string"
                 },
+                "serviceAccount": {
+                    "type": "boolean"
+                },
                 "status": {
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "disable",
+                        "pending"
+                    ]
+                },
+                "twoFactorEnabled": {
+                    "description": "TwoFactorEnabled is true once the user activated an authenticator app",
+                    "type": "boolean"
                 },
                 "updatedAt": {
                     "type": "string"
@@ -2191,6 +5222,95 @@ var doc = `{
                     ]
                 }
             }
+        },
+        "userimport.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Id is empty for dry runs, nothing is stored",
+                    "type": "integer"
+                },
+                "invite": {
+                    "type": "boolean"
+                },
+                "report": {
+                    "$ref": "#/definitions/userimport.Report"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "done",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "userimport.Report": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "invited": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/userimport.RowResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "userimport.RowResult": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors maps a column of the file to what is wrong with it",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -22,10 +22,14 @@ import (
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/log"
 	"mcm-api/pkg/media"
+	"mcm-api/pkg/privacy"
+	"mcm-api/pkg/profile"
 	"mcm-api/pkg/queue"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/statistic"
 	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 )
 
 func ProvideConfig() *config.Config {
@@ -84,4 +88,8 @@ var HandlerSet = wire.NewSet(
 	comment.NewHandler,
 	systemdata.NewHandler,
 	statistic.NewHandler,
+	profile.NewHandler,
+	rbac.NewHandler,
+	userimport.NewHandler,
+	privacy.NewHandler,
 )
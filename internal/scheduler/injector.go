@@ -0,0 +1,30 @@
+//+build wireinject
+
+package scheduler
+
+import (
+	"github.com/google/wire"
+	"mcm-api/internal/core"
+	"mcm-api/pkg/article"
+	"mcm-api/pkg/contributesession"
+	"mcm-api/pkg/contribution"
+	"mcm-api/pkg/faculty"
+	"mcm-api/pkg/media"
+	"mcm-api/pkg/notification"
+	"mcm-api/pkg/systemdata"
+	"mcm-api/pkg/user"
+)
+
+func InitializeScheduler() *scheduler {
+	panic(wire.Build(
+		core.InfraSet,
+		media.Set,
+		notification.Set,
+		user.Set,
+		faculty.Set,
+		contributesession.Set,
+		article.Set,
+		contribution.Set,
+		systemdata.Set,
+		newScheduler))
+}
@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redsync/redsync/v4"
+	"go.uber.org/zap"
+	"mcm-api/config"
+	"mcm-api/pkg/contributesession"
+	"mcm-api/pkg/contribution"
+	"mcm-api/pkg/faculty"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/notification"
+	"mcm-api/pkg/user"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+const (
+	tickInterval = time.Minute
+	// an event keeps its lock while it notifies, other instances skip it meanwhile
+	eventLockExpiry      = 10 * time.Minute
+	defaultReminderHours = 72
+	emailTimeLayout      = "02 Jan 2006 15:04 MST"
+)
+
+// events are fired in this order so a session created late still gets its reminder first
+var events = []contributesession.Event{
+	contributesession.EventClosingReminder,
+	contributesession.EventClosureReached,
+	contributesession.EventFinalClosureReached,
+}
+
+type scheduler struct {
+	cfg                      *config.Config
+	contributeSessionService *contributesession.Service
+	contributionService      *contribution.Service
+	userService              *user.Service
+	facultyService           *faculty.Service
+	notificationService      *notification.Service
+	lock                     *redsync.Redsync
+}
+
+func newScheduler(
+	config *config.Config,
+	contributeSessionService *contributesession.Service,
+	contributionService *contribution.Service,
+	userService *user.Service,
+	facultyService *faculty.Service,
+	notificationService *notification.Service,
+	lock *redsync.Redsync,
+) *scheduler {
+	return &scheduler{
+		cfg:                      config,
+		contributeSessionService: contributeSessionService,
+		contributionService:      contributionService,
+		userService:              userService,
+		facultyService:           facultyService,
+		notificationService:      notificationService,
+		lock:                     lock,
+	}
+}
+
+func (s scheduler) Start() {
+	log.Logger.Info("starting scheduler")
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	go func() {
+		signalChannel := make(chan os.Signal, 1)
+		signal.Notify(signalChannel, os.Interrupt)
+		sig := <-signalChannel
+		log.Logger.Info("receive signal", zap.String("signal", sig.String()))
+		cancelFunc()
+		log.Logger.Info("grateful shutdown...")
+	}()
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick fires the events that are due, a failed event is retried on the next tick.
+func (s scheduler) tick(ctx context.Context) {
+	for _, event := range events {
+		sessions, err := s.contributeSessionService.FindDueForEvent(ctx, event, s.reminderLead())
+		if err != nil {
+			log.Logger.Error("find sessions due failed", zap.String("event", string(event)), zap.Error(err))
+			continue
+		}
+		for _, session := range sessions {
+			err = s.fire(ctx, event, session)
+			if err != nil {
+				log.Logger.Error("fire session event failed",
+					zap.Int("sessionId", session.Id), zap.String("event", string(event)), zap.Error(err))
+			}
+		}
+	}
+	err := s.escalateOverdueFeedback(ctx)
+	if err != nil {
+		log.Logger.Error("escalate overdue feedback failed", zap.Error(err))
+	}
+}
+
+// escalateOverdueFeedback queues the escalations of the contributions that passed their
+// feedback deadline, the lock keeps two instances from queueing the same ones.
+func (s scheduler) escalateOverdueFeedback(ctx context.Context) error {
+	mutex := s.lock.NewMutex("feedback-escalation-lock",
+		redsync.WithExpiry(eventLockExpiry),
+		redsync.WithTries(1),
+	)
+	err := mutex.LockContext(ctx)
+	if errors.Is(err, redsync.ErrFailed) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ok, err := mutex.UnlockContext(ctx)
+		if !ok || err != nil {
+			log.Logger.Error("failed to unlock", zap.Error(err))
+		}
+	}()
+	return s.contributionService.EscalateOverdueFeedback(ctx)
+}
+
+// fire runs the event of a session under a redis lock and records it, the lock keeps
+// the other instances from firing it at the same time and the record from firing it again.
+func (s scheduler) fire(ctx context.Context, event contributesession.Event, session *contributesession.SessionRes) error {
+	mutex := s.lock.NewMutex(generateLockKey(session.Id, event),
+		redsync.WithExpiry(eventLockExpiry),
+		redsync.WithTries(1),
+	)
+	err := mutex.LockContext(ctx)
+	if errors.Is(err, redsync.ErrFailed) {
+		log.Logger.Debug("session event is fired by another instance",
+			zap.Int("sessionId", session.Id), zap.String("event", string(event)))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ok, err := mutex.UnlockContext(ctx)
+		if !ok || err != nil {
+			log.Logger.Error("failed to unlock", zap.Error(err))
+		}
+	}()
+	// another instance may have fired it since the sessions were found
+	fired, err := s.contributeSessionService.IsEventFired(ctx, session.Id, event)
+	if err != nil || fired {
+		return err
+	}
+	log.Logger.Info("fire session event", zap.Int("sessionId", session.Id), zap.String("event", string(event)))
+	switch event {
+	case contributesession.EventClosingReminder:
+		err = s.remindStudents(ctx, session)
+	case contributesession.EventClosureReached:
+		err = s.notifyCoordinators(ctx, session)
+	case contributesession.EventFinalClosureReached:
+		err = s.contributeSessionService.ExportAsset(ctx, session.Id)
+	default:
+		err = fmt.Errorf("unknown session event %v", event)
+	}
+	if err != nil {
+		return err
+	}
+	return s.contributeSessionService.MarkEventFired(ctx, session.Id, event)
+}
+
+// remindStudents emails the students who did not contribute to the session yet, a
+// failed email is logged so the others are not reminded twice.
+func (s scheduler) remindStudents(ctx context.Context, session *contributesession.SessionRes) error {
+	students, err := s.userService.GetStudentsWithoutContribution(ctx, session.Id, session.FacultyIds)
+	if err != nil {
+		return err
+	}
+	for _, student := range students {
+		err = s.notificationService.SendSessionClosingEmail(
+			&notification.Destination{ToAddresses: []string{student.Email}},
+			&notification.TemplateSessionClosingPayload{
+				Name:        student.Name,
+				Link:        s.cfg.WebAppUrl + "/contribution",
+				ClosureTime: session.ClosureTime.Format(emailTimeLayout),
+			})
+		if err != nil {
+			log.Logger.Error("send session closing email failed", zap.Int("userId", student.Id), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// notifyCoordinators emails the coordinators of the faculties of the session that the
+// contributions can be reviewed.
+func (s scheduler) notifyCoordinators(ctx context.Context, session *contributesession.SessionRes) error {
+	facultyIds := session.FacultyIds
+	if len(facultyIds) == 0 {
+		faculties, err := s.facultyService.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, f := range faculties {
+			if !f.IsArchived() {
+				facultyIds = append(facultyIds, f.Id)
+			}
+		}
+	}
+	for _, facultyId := range facultyIds {
+		coordinators, err := s.userService.GetCoordinatorsOfFaculty(ctx, facultyId)
+		if err != nil {
+			return err
+		}
+		for _, coordinator := range coordinators {
+			err = s.notificationService.SendSessionClosedEmail(
+				&notification.Destination{ToAddresses: []string{coordinator.Email}},
+				&notification.TemplateSessionClosedPayload{
+					Name:             coordinator.Name,
+					Link:             s.cfg.WebAppUrl + "/contribution?contributionSessionId=" + strconv.Itoa(session.Id),
+					FinalClosureTime: session.FinalClosureTime.Format(emailTimeLayout),
+				})
+			if err != nil {
+				log.Logger.Error("send session closed email failed", zap.Int("userId", coordinator.Id), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+func (s scheduler) reminderLead() time.Duration {
+	hours := s.cfg.SessionReminderHours
+	if hours <= 0 {
+		hours = defaultReminderHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func generateLockKey(id int, event contributesession.Event) string {
+	return fmt.Sprintf("session:%v:%v-lock", id, event)
+}
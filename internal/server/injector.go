@@ -6,22 +6,29 @@ import (
 	"github.com/google/wire"
 	"mcm-api/internal/core"
 	"mcm-api/pkg/article"
+	"mcm-api/pkg/audit"
 	"mcm-api/pkg/authz"
 	"mcm-api/pkg/comment"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/contribution"
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/media"
+	"mcm-api/pkg/middleware"
+	"mcm-api/pkg/privacy"
+	"mcm-api/pkg/profile"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/startup"
 	"mcm-api/pkg/statistic"
 	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 )
 
 func InitializeServer() *Server {
 	panic(wire.Build(
 		core.InfraSet,
 		user.Set,
+		audit.Set,
 		authz.Set,
 		startup.Set,
 		faculty.Set,
@@ -32,6 +39,11 @@ func InitializeServer() *Server {
 		comment.Set,
 		systemdata.Set,
 		statistic.Set,
+		profile.Set,
+		rbac.Set,
+		userimport.Set,
+		privacy.Set,
+		middleware.Set,
 		core.HandlerSet,
 		newServer,
 	))
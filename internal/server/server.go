@@ -16,10 +16,15 @@ import (
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/log"
 	"mcm-api/pkg/media"
+	appMiddleware "mcm-api/pkg/middleware"
+	"mcm-api/pkg/privacy"
+	"mcm-api/pkg/profile"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/startup"
 	"mcm-api/pkg/statistic"
 	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 	"os"
 	"os/signal"
 	"time"
@@ -39,6 +44,10 @@ type Server struct {
 	comment           *comment.Handler
 	systemdata        *systemdata.Handler
 	statistic         *statistic.Handler
+	profile           *profile.Handler
+	rbac              *rbac.Handler
+	userImport        *userimport.Handler
+	privacy           *privacy.Handler
 }
 
 func newServer(
@@ -54,9 +63,18 @@ func newServer(
 	comment *comment.Handler,
 	systemdata *systemdata.Handler,
 	statistic *statistic.Handler,
+	profile *profile.Handler,
+	rbac *rbac.Handler,
+	userImport *userimport.Handler,
+	privacy *privacy.Handler,
 ) *Server {
+	ipExtractor, err := appMiddleware.IpExtractor(config.TrustedProxies)
+	if err != nil {
+		log.Logger.Panic("Trusted proxies are invalid", zap.Error(err))
+	}
 	e := echo.New()
 	e.HideBanner = true
+	e.IPExtractor = ipExtractor
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{
@@ -80,6 +98,10 @@ func newServer(
 		comment:           comment,
 		systemdata:        systemdata,
 		statistic:         statistic,
+		profile:           profile,
+		rbac:              rbac,
+		userImport:        userImport,
+		privacy:           privacy,
 	}
 }
 
@@ -94,6 +116,11 @@ func (s *Server) registerHandler() {
 	s.comment.Register(s.echo.Group("comments"))
 	s.systemdata.Register(s.echo.Group("system-data"))
 	s.statistic.Register(s.echo.Group("statistics"))
+	s.profile.Register(s.echo.Group("me"))
+	s.rbac.Register(s.echo.Group("roles"))
+	s.rbac.RegisterPermissions(s.echo.Group("permissions"))
+	s.userImport.Register(s.echo.Group("user-imports"))
+	s.privacy.Register(s.echo.Group("privacy"))
 }
 
 // @title 123
@@ -8,17 +8,23 @@ package server
 import (
 	"mcm-api/internal/core"
 	"mcm-api/pkg/article"
+	"mcm-api/pkg/audit"
 	"mcm-api/pkg/authz"
 	"mcm-api/pkg/comment"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/contribution"
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/media"
+	"mcm-api/pkg/middleware"
+	"mcm-api/pkg/privacy"
+	"mcm-api/pkg/profile"
 	"mcm-api/pkg/queue"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/startup"
 	"mcm-api/pkg/statistic"
 	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 )
 
 // Injectors from injector.go:
@@ -29,35 +35,51 @@ func InitializeServer() *Server {
 	repository := user.InitializeRepository(db)
 	facultyRepository := faculty.InitializeRepository(db)
 	service := faculty.InitializeService(config, facultyRepository)
-	userService := user.InitializeService(config, repository, service)
-	startupService := startup.InitializeStartUpService(userService)
-	authzService := authz.InitializeAuthService(config, userService)
-	handler := authz.NewAuthHandler(authzService)
-	userHandler := user.NewUserHandler(config, userService)
-	facultyHandler := faculty.NewHandler(config, service)
-	imageProxyService := media.NewDarthsimImageProxyService(config)
-	mediaService := media.NewStorageService(config, imageProxyService)
-	mediaHandler := media.NewHandler(config, mediaService)
-	contributesessionRepository := contributesession.InitializeRepository(db)
 	client := core.ProvideRedis(config)
 	queueQueue := queue.InitializeRedisQueue(config, client)
-	contributesessionService := contributesession.InitializeService(config, contributesessionRepository, queueQueue, mediaService)
-	contributesessionHandler := contributesession.NewHandler(config, contributesessionService)
+	userService := user.InitializeService(config, repository, service, queueQueue)
+	rbacRepository := rbac.InitializeRepository(db)
+	rbacService := rbac.InitializeService(config, rbacRepository, client)
+	startupService := startup.InitializeStartUpService(userService, rbacService)
+	authzRepository := authz.InitializeRepository(db)
+	auditRepository := audit.InitializeRepository(db)
+	auditService := audit.InitializeService(auditRepository)
+	authzService := authz.InitializeAuthService(config, authzRepository, userService, queueQueue, client, auditService)
+	imageProxyService := media.NewDarthsimImageProxyService(config)
+	mediaService := media.NewStorageService(config, imageProxyService)
 	contributionRepository := contribution.InitializeRepository(db)
+	contributesessionRepository := contributesession.InitializeRepository(db)
+	contributesessionService := contributesession.InitializeService(config, contributesessionRepository, queueQueue, mediaService, service)
 	articleRepository := article.InitializeRepository(db)
 	articleService := article.InitializeService(config, articleRepository, mediaService, queueQueue)
-	contributionService := contribution.InitializeService(config, contributionRepository, queueQueue, contributesessionService, articleService, mediaService)
-	contributionHandler := contribution.NewHandler(config, contributionService)
-	articleHandler := article.NewHandler(config, articleService)
-	commentRepository := comment.InitializeRepository(db)
-	commentService := comment.InitializeService(config, commentRepository, client, contributionService)
-	commentHandler := comment.NewHandler(config, commentService)
 	systemdataRepository := systemdata.InitializeRepository(db)
 	systemdataService := systemdata.InitializeService(config, systemdataRepository)
-	systemdataHandler := systemdata.NewHandler(config, systemdataService)
+	contributionService := contribution.InitializeService(config, contributionRepository, queueQueue, contributesessionService, articleService, mediaService, service, systemdataService)
+	permissionService := authz.InitializePermissionService(contributionService, contributesessionService)
+	authenticator := middleware.NewAuthenticator(config, authzService, authzService, authzService)
+	handler := authz.NewAuthHandler(config, authenticator, authzService, permissionService)
+	userHandler := user.NewUserHandler(config, authenticator, userService)
+	facultyHandler := faculty.NewHandler(config, authenticator, service)
+	mediaHandler := media.NewHandler(config, authenticator, mediaService)
+	contributesessionHandler := contributesession.NewHandler(config, authenticator, contributesessionService)
+	contributionHandler := contribution.NewHandler(config, authenticator, contributionService)
+	articleHandler := article.NewHandler(config, authenticator, articleService)
+	commentRepository := comment.InitializeRepository(db)
+	commentService := comment.InitializeService(config, commentRepository, client, contributionService)
+	commentHandler := comment.NewHandler(config, authenticator, commentService)
+	systemdataHandler := systemdata.NewHandler(config, authenticator, systemdataService)
 	statisticRepository := statistic.InitializeRepository(db)
 	statisticService := statistic.InitializeService(statisticRepository, contributesessionService)
-	statisticHandler := statistic.NewHandler(config, statisticService)
-	server := newServer(config, startupService, handler, userHandler, facultyHandler, mediaHandler, contributesessionHandler, contributionHandler, articleHandler, commentHandler, systemdataHandler, statisticHandler)
+	statisticHandler := statistic.NewHandler(config, authenticator, statisticService)
+	profileService := profile.InitializeService(config, userService, authzService, mediaService)
+	profileHandler := profile.NewHandler(config, authenticator, profileService)
+	rbacHandler := rbac.NewHandler(config, authenticator, rbacService)
+	userimportRepository := userimport.InitializeRepository(db)
+	userimportService := userimport.InitializeService(config, userimportRepository, userService, service, queueQueue)
+	userimportHandler := userimport.NewHandler(config, authenticator, userimportService)
+	privacyRepository := privacy.InitializeRepository(db)
+	privacyService := privacy.InitializeService(config, privacyRepository, mediaService, queueQueue, auditService)
+	privacyHandler := privacy.NewHandler(config, authenticator, privacyService)
+	server := newServer(config, startupService, handler, userHandler, facultyHandler, mediaHandler, contributesessionHandler, contributionHandler, articleHandler, commentHandler, systemdataHandler, statisticHandler, profileHandler, rbacHandler, userimportHandler, privacyHandler)
 	return server
 }
@@ -6,13 +6,18 @@ import (
 	"github.com/google/wire"
 	"mcm-api/internal/core"
 	"mcm-api/pkg/article"
+	"mcm-api/pkg/audit"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/contribution"
 	"mcm-api/pkg/converter"
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/media"
 	"mcm-api/pkg/notification"
+	"mcm-api/pkg/privacy"
+	"mcm-api/pkg/rbac"
+	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 )
 
 func InitializeWorker() *worker {
@@ -25,6 +30,11 @@ func InitializeWorker() *worker {
 		user.Set,
 		faculty.Set,
 		contribution.Set,
+		systemdata.Set,
 		contributesession.Set,
+		rbac.Set,
+		userimport.Set,
+		audit.Set,
+		privacy.Set,
 		newWorker))
 }
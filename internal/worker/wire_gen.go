@@ -8,14 +8,19 @@ package worker
 import (
 	"mcm-api/internal/core"
 	"mcm-api/pkg/article"
+	"mcm-api/pkg/audit"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/contribution"
 	"mcm-api/pkg/converter"
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/media"
 	"mcm-api/pkg/notification"
+	"mcm-api/pkg/privacy"
 	"mcm-api/pkg/queue"
+	"mcm-api/pkg/rbac"
+	"mcm-api/pkg/systemdata"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
 )
 
 // Injectors from injector.go:
@@ -34,12 +39,22 @@ func InitializeWorker() *worker {
 	userRepository := user.InitializeRepository(db)
 	facultyRepository := faculty.InitializeRepository(db)
 	facultyService := faculty.InitializeService(config, facultyRepository)
-	userService := user.InitializeService(config, userRepository, facultyService)
+	userService := user.InitializeService(config, userRepository, facultyService, queueQueue)
 	contributionRepository := contribution.InitializeRepository(db)
 	contributesessionRepository := contributesession.InitializeRepository(db)
-	contributesessionService := contributesession.InitializeService(config, contributesessionRepository, queueQueue, service)
-	contributionService := contribution.InitializeService(config, contributionRepository, queueQueue, contributesessionService, articleService, service)
+	contributesessionService := contributesession.InitializeService(config, contributesessionRepository, queueQueue, service, facultyService)
+	systemdataRepository := systemdata.InitializeRepository(db)
+	systemdataService := systemdata.InitializeService(config, systemdataRepository)
+	contributionService := contribution.InitializeService(config, contributionRepository, queueQueue, contributesessionService, articleService, service, facultyService, systemdataService)
+	userimportRepository := userimport.InitializeRepository(db)
+	userimportService := userimport.InitializeService(config, userimportRepository, userService, facultyService, queueQueue)
+	rbacRepository := rbac.InitializeRepository(db)
+	rbacService := rbac.InitializeService(config, rbacRepository, client)
+	privacyRepository := privacy.InitializeRepository(db)
+	auditRepository := audit.InitializeRepository(db)
+	auditService := audit.InitializeService(auditRepository)
+	privacyService := privacy.InitializeService(config, privacyRepository, service, queueQueue, auditService)
 	redsync := core.ProvideLock(client)
-	workerWorker := newWorker(config, queueQueue, documentConverter, articleService, notificationService, userService, service, contributionService, contributesessionService, redsync)
+	workerWorker := newWorker(config, queueQueue, documentConverter, articleService, notificationService, userService, service, contributionService, contributesessionService, userimportService, rbacService, privacyService, redsync)
 	return workerWorker
 }
@@ -15,8 +15,12 @@ import (
 	"mcm-api/pkg/log"
 	"mcm-api/pkg/media"
 	"mcm-api/pkg/notification"
+	"mcm-api/pkg/privacy"
 	"mcm-api/pkg/queue"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/user"
+	"mcm-api/pkg/userimport"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -35,6 +39,9 @@ type worker struct {
 	contributionService        *contribution.Service
 	contributionSessionService *contributesession.Service
 	mediaService               media.Service
+	userImportService          *userimport.Service
+	rbacService                *rbac.Service
+	privacyService             *privacy.Service
 	lock                       *redsync.Redsync
 }
 
@@ -48,6 +55,9 @@ func newWorker(
 	mediaService media.Service,
 	contributionService *contribution.Service,
 	contributionSessionService *contributesession.Service,
+	userImportService *userimport.Service,
+	rbacService *rbac.Service,
+	privacyService *privacy.Service,
 	lock *redsync.Redsync,
 ) *worker {
 	return &worker{
@@ -60,6 +70,9 @@ func newWorker(
 		contributionService:        contributionService,
 		contributionSessionService: contributionSessionService,
 		mediaService:               mediaService,
+		userImportService:          userImportService,
+		rbacService:                rbacService,
+		privacyService:             privacyService,
 		lock:                       lock,
 	}
 }
@@ -75,6 +88,12 @@ func (w worker) Start() {
 		cancelFunc()
 		log.Logger.Info("grateful shutdown...")
 	}()
+	// imported users are validated against the roles of the database
+	err := w.rbacService.Load(ctx)
+	if err != nil {
+		log.Logger.Panic("load roles failed", zap.Error(err))
+	}
+	go w.rbacService.Watch(ctx)
 poolQueueLoop:
 	for {
 		select {
@@ -119,6 +138,16 @@ func (w worker) handleMessage(ctx context.Context, message *queue.Message) error
 		return w.articleUploadedHandler(ctx, message)
 	case queue.ExportContributeSession:
 		return w.exportContributeSessionHandler(ctx, message)
+	case queue.PasswordResetRequested:
+		return w.passwordResetRequestedHandler(ctx, message)
+	case queue.UserImportRequested:
+		return w.userImportRequestedHandler(ctx, message)
+	case queue.UserInvited:
+		return w.userInvitedHandler(ctx, message)
+	case queue.UserDataExportRequested:
+		return w.userDataExportRequestedHandler(ctx, message)
+	case queue.FeedbackOverdue:
+		return w.feedbackOverdueHandler(ctx, message)
 	default:
 		return fmt.Errorf("unknown topic %v", message.Topic)
 	}
@@ -126,7 +155,7 @@ func (w worker) handleMessage(ctx context.Context, message *queue.Message) error
 
 func (w worker) contributionCreatedHandler(ctx context.Context, message *queue.Message) error {
 	if v, ok := message.Data.(*queue.ContributionCreatedPayload); ok {
-		entities, err := w.userService.GetAllUserOfFaculty(ctx, enforcer.MarketingCoordinator, v.FacultyId)
+		entities, err := w.userService.GetCoordinatorsOfFaculty(ctx, v.FacultyId)
 		if err != nil {
 			return err
 		}
@@ -135,6 +164,9 @@ func (w worker) contributionCreatedHandler(ctx context.Context, message *queue.M
 			return nil
 		}
 		for _, marketingCoordinator := range entities {
+			if !marketingCoordinator.NotificationPreferences.NewContributionEmail {
+				continue
+			}
 			err = w.notificationService.SendNewContributionEmail(
 				&notification.Destination{ToAddresses: []string{marketingCoordinator.Email}},
 				&notification.TemplateNewContributionPayLoad{
@@ -166,3 +198,82 @@ func (w worker) articleUploadedHandler(ctx context.Context, message *queue.Messa
 		return errors.New("unknown message")
 	}
 }
+
+func (w worker) passwordResetRequestedHandler(ctx context.Context, message *queue.Message) error {
+	if v, ok := message.Data.(*queue.PasswordResetRequestedPayload); ok {
+		return w.notificationService.SendPasswordResetEmail(
+			&notification.Destination{ToAddresses: []string{v.Email}},
+			&notification.TemplatePasswordResetPayload{
+				Name:          v.Name,
+				Link:          w.cfg.WebAppUrl + "/reset-password?token=" + url.QueryEscape(v.Token),
+				ExpireMinutes: v.ExpireMinutes,
+			})
+	} else {
+		return errors.New("unknown message")
+	}
+}
+
+func (w worker) userImportRequestedHandler(ctx context.Context, message *queue.Message) error {
+	if v, ok := message.Data.(*queue.UserImportRequestedPayload); ok {
+		return w.userImportService.Process(ctx, v.ImportId)
+	} else {
+		return errors.New("unknown message")
+	}
+}
+
+func (w worker) userInvitedHandler(ctx context.Context, message *queue.Message) error {
+	if v, ok := message.Data.(*queue.UserInvitedPayload); ok {
+		return w.notificationService.SendInvitationEmail(
+			&notification.Destination{ToAddresses: []string{v.Email}},
+			&notification.TemplateInvitationPayload{
+				Name:        v.Name,
+				Link:        w.cfg.WebAppUrl + "/accept-invitation?token=" + url.QueryEscape(v.Token),
+				ExpireHours: v.ExpireHours,
+			})
+	} else {
+		return errors.New("unknown message")
+	}
+}
+
+func (w worker) userDataExportRequestedHandler(ctx context.Context, message *queue.Message) error {
+	if v, ok := message.Data.(*queue.UserDataExportRequestedPayload); ok {
+		return w.privacyService.ProcessExport(ctx, v.ExportId)
+	} else {
+		return errors.New("unknown message")
+	}
+}
+
+// feedbackOverdueHandler escalates a contribution without coordinator feedback to the
+// coordinators of its faculty and to the marketing managers.
+func (w worker) feedbackOverdueHandler(ctx context.Context, message *queue.Message) error {
+	if v, ok := message.Data.(*queue.FeedbackOverduePayload); ok {
+		coordinators, err := w.userService.GetCoordinatorsOfFaculty(ctx, v.FacultyId)
+		if err != nil {
+			return err
+		}
+		managers, err := w.userService.GetActiveUsersOfRole(ctx, enforcer.MarketingManager)
+		if err != nil {
+			return err
+		}
+		for _, target := range append(coordinators, managers...) {
+			err = w.notificationService.SendFeedbackOverdueEmail(
+				&notification.Destination{ToAddresses: []string{target.Email}},
+				&notification.TemplateFeedbackOverduePayload{
+					Name:          target.Name,
+					Link:          w.cfg.WebAppUrl + "/contribution/" + strconv.Itoa(v.ContributionId),
+					StudentName:   v.StudentName,
+					Title:         v.Title,
+					FeedbackDueAt: v.FeedbackDueAt,
+				})
+			if err != nil {
+				log.Logger.Error("send email failed",
+					zap.Error(err),
+					zap.Any("target", target),
+				)
+			}
+		}
+		return nil
+	} else {
+		return errors.New("unknown message")
+	}
+}
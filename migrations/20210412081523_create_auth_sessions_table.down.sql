@@ -0,0 +1 @@
+drop table auth_sessions;
\ No newline at end of file
@@ -0,0 +1,14 @@
+create table auth_sessions
+(
+    id                  uuid primary key default uuid_generate_v4(),
+    user_id             bigint      not null references users (id),
+    refresh_token_hash  text        not null,
+    previous_token_hash text,
+    user_agent          text,
+    ip_address          text,
+    expires_at          timestamptz not null,
+    revoked_at          timestamptz,
+    created_at          timestamptz,
+    updated_at          timestamptz
+);
+create index auth_sessions_user_id_idx on auth_sessions (user_id);
@@ -0,0 +1,9 @@
+create table password_reset_tokens
+(
+    id         serial primary key,
+    user_id    bigint      not null references users (id),
+    token_hash text unique not null,
+    expires_at timestamptz not null,
+    used_at    timestamptz,
+    created_at timestamptz
+);
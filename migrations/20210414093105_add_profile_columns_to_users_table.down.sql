@@ -0,0 +1,2 @@
+alter table users drop column avatar;
+alter table users drop column notification_preferences;
\ No newline at end of file
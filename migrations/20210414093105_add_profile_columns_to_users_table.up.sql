@@ -0,0 +1,4 @@
+alter table users
+    add column avatar text;
+alter table users
+    add column notification_preferences jsonb not null default '{}';
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table users drop column totp_secret;
+alter table users drop column totp_enabled_at;
+alter table users drop column totp_recovery_codes;
\ No newline at end of file
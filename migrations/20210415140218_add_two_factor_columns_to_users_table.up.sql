@@ -0,0 +1,6 @@
+alter table users
+    add column totp_secret text;
+alter table users
+    add column totp_enabled_at timestamptz;
+alter table users
+    add column totp_recovery_codes jsonb not null default '[]';
\ No newline at end of file
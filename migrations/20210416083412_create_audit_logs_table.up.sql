@@ -0,0 +1,14 @@
+create table audit_logs
+(
+    id          bigserial primary key,
+    actor_id    bigint,
+    action      text not null,
+    target_type text,
+    target_id   text,
+    ip_address  text,
+    user_agent  text,
+    data        jsonb not null default '{}',
+    created_at  timestamptz
+);
+create index audit_logs_actor_id_idx on audit_logs (actor_id);
+create index audit_logs_action_created_at_idx on audit_logs (action, created_at);
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table users drop constraint users_role_fkey;
+drop table role_permissions;
+drop table permissions;
+drop table roles;
\ No newline at end of file
@@ -0,0 +1,105 @@
+create table roles
+(
+    name                text primary key,
+    description         text,
+    requires_faculty    boolean not null default false,
+    requires_two_factor boolean not null default false,
+    system              boolean not null default false,
+    created_at          timestamptz,
+    updated_at          timestamptz
+);
+create table permissions
+(
+    name        text primary key,
+    description text
+);
+create table role_permissions
+(
+    role_name       text not null references roles (name) on delete cascade,
+    permission_name text not null references permissions (name) on delete cascade,
+    primary key (role_name, permission_name)
+);
+insert into permissions (name, description)
+values ('user.read', 'Read user'),
+       ('user.create', 'Create user'),
+       ('user.update', 'Update user'),
+       ('user.delete', 'Delete user'),
+       ('faculty.read', 'Read faculty'),
+       ('faculty.create', 'Create faculty'),
+       ('faculty.update', 'Update faculty'),
+       ('faculty.delete', 'Delete faculty'),
+       ('contribute_session.read', 'Read contribute session'),
+       ('contribute_session.create', 'Create contribute session'),
+       ('contribute_session.update', 'Update contribute session'),
+       ('contribute_session.delete', 'Delete contribute session'),
+       ('contribute_session.export', 'Export contribute session'),
+       ('media.create', 'Create media'),
+       ('contribution.read', 'Read contribution'),
+       ('contribution.create', 'Create contribution'),
+       ('contribution.update', 'Update contribution'),
+       ('contribution.delete', 'Delete contribution'),
+       ('contribution.update_status', 'Update contribution status'),
+       ('comment.read', 'Read comment'),
+       ('comment.create', 'Create comment'),
+       ('comment.update', 'Update comment'),
+       ('comment.delete', 'Delete comment'),
+       ('system_data.read', 'Read system data'),
+       ('system_data.update', 'Update system data'),
+       ('statistic.read', 'Read statistic'),
+       ('role.read', 'Read role'),
+       ('role.create', 'Create role'),
+       ('role.update', 'Update role'),
+       ('role.delete', 'Delete role');
+insert into roles (name, description, requires_faculty, requires_two_factor, system, created_at, updated_at)
+values ('admin', 'Manages accounts, faculties, sessions and system data', false, true, true, now(), now()),
+       ('marketing_manager', 'Oversees every faculty and exports accepted contributions', false, true, true, now(), now()),
+       ('marketing_coordinator', 'Reviews contributions of their faculty', true, false, true, now(), now()),
+       ('student', 'Submits contributions', true, false, true, now(), now()),
+       ('guest', 'Reads accepted contributions of their faculty', true, false, true, now(), now());
+insert into role_permissions (role_name, permission_name)
+values ('admin', 'user.read'),
+       ('admin', 'user.update'),
+       ('admin', 'user.create'),
+       ('admin', 'user.delete'),
+       ('admin', 'faculty.read'),
+       ('admin', 'faculty.update'),
+       ('admin', 'faculty.create'),
+       ('admin', 'faculty.delete'),
+       ('admin', 'contribute_session.read'),
+       ('admin', 'contribute_session.update'),
+       ('admin', 'contribute_session.create'),
+       ('admin', 'contribute_session.delete'),
+       ('admin', 'system_data.read'),
+       ('admin', 'system_data.update'),
+       ('admin', 'role.read'),
+       ('admin', 'role.create'),
+       ('admin', 'role.update'),
+       ('admin', 'role.delete'),
+       ('marketing_manager', 'contribution.read'),
+       ('marketing_manager', 'contribute_session.read'),
+       ('marketing_manager', 'contribute_session.export'),
+       ('marketing_manager', 'statistic.read'),
+       ('marketing_manager', 'faculty.read'),
+       ('marketing_coordinator', 'contribution.read'),
+       ('marketing_coordinator', 'contribution.update_status'),
+       ('marketing_coordinator', 'comment.read'),
+       ('marketing_coordinator', 'comment.update'),
+       ('marketing_coordinator', 'comment.create'),
+       ('marketing_coordinator', 'comment.delete'),
+       ('marketing_coordinator', 'faculty.read'),
+       ('marketing_coordinator', 'contribute_session.read'),
+       ('student', 'contribution.read'),
+       ('student', 'contribution.update'),
+       ('student', 'contribution.create'),
+       ('student', 'contribution.delete'),
+       ('student', 'media.create'),
+       ('student', 'comment.read'),
+       ('student', 'comment.update'),
+       ('student', 'comment.create'),
+       ('student', 'comment.delete'),
+       ('student', 'system_data.read'),
+       ('student', 'faculty.read'),
+       ('student', 'contribute_session.read'),
+       ('guest', 'contribution.read');
+alter table users
+    add constraint users_role_fkey foreign key (role) references roles (name);
\ No newline at end of file
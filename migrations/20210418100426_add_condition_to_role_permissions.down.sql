@@ -0,0 +1,2 @@
+alter table role_permissions
+    drop column condition;
\ No newline at end of file
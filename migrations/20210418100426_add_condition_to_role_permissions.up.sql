@@ -0,0 +1,23 @@
+alter table role_permissions
+    add column condition text not null default '';
+update role_permissions
+set condition = 'published'
+where role_name = 'marketing_manager'
+  and permission_name = 'contribution.read';
+update role_permissions
+set condition = 'faculty'
+where role_name = 'marketing_coordinator'
+  and permission_name in ('contribution.read', 'contribution.update_status', 'comment.read', 'comment.create');
+update role_permissions
+set condition = 'owner'
+where role_name = 'marketing_coordinator'
+  and permission_name in ('comment.update', 'comment.delete');
+update role_permissions
+set condition = 'owner'
+where role_name = 'student'
+  and permission_name in ('contribution.read', 'contribution.update', 'contribution.delete',
+                          'comment.read', 'comment.create', 'comment.update', 'comment.delete');
+update role_permissions
+set condition = 'faculty && published'
+where role_name = 'guest'
+  and permission_name = 'contribution.read';
\ No newline at end of file
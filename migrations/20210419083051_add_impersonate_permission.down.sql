@@ -0,0 +1 @@
+delete from permissions where name = 'user.impersonate';
\ No newline at end of file
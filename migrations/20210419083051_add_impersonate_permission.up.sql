@@ -0,0 +1,4 @@
+insert into permissions (name, description)
+values ('user.impersonate', 'Impersonate user');
+insert into role_permissions (role_name, permission_name)
+values ('admin', 'user.impersonate');
\ No newline at end of file
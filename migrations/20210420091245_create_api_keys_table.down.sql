@@ -0,0 +1,4 @@
+delete from permissions where name in ('api_key.create', 'api_key.manage');
+drop table api_keys;
+alter table users
+    drop column service_account;
\ No newline at end of file
@@ -0,0 +1,27 @@
+alter table users
+    add column service_account boolean not null default false;
+create table api_keys
+(
+    id           serial primary key,
+    user_id      bigint      not null references users (id) on delete cascade,
+    name         text        not null,
+    prefix       text unique not null,
+    secret_hash  text        not null,
+    scopes       jsonb       not null default '[]',
+    allowed_ips  jsonb       not null default '[]',
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    last_used_ip text,
+    revoked_at   timestamptz,
+    created_by   bigint references users (id) on delete set null,
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+create index api_keys_user_id_idx on api_keys (user_id);
+insert into permissions (name, description)
+values ('api_key.create', 'Create personal api key'),
+       ('api_key.manage', 'Manage api keys and service accounts of every user');
+insert into role_permissions (role_name, permission_name)
+values ('admin', 'api_key.create'),
+       ('admin', 'api_key.manage'),
+       ('marketing_manager', 'api_key.create');
\ No newline at end of file
@@ -0,0 +1 @@
+drop table user_imports;
\ No newline at end of file
@@ -0,0 +1,14 @@
+create table user_imports
+(
+    id          serial primary key,
+    file_name   text        not null,
+    status      text        not null,
+    invite      boolean     not null default false,
+    rows        jsonb       not null default '[]',
+    report      jsonb       not null default '{}',
+    error       text        not null default '',
+    created_by  bigint references users (id) on delete set null,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    finished_at timestamptz
+);
\ No newline at end of file
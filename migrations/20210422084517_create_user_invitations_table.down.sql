@@ -0,0 +1 @@
+drop table user_invitations;
\ No newline at end of file
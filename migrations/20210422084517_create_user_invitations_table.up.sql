@@ -0,0 +1,12 @@
+create table user_invitations
+(
+    id           serial primary key,
+    user_id      bigint      not null references users (id) on delete cascade,
+    expires_at   timestamptz not null,
+    accepted_at  timestamptz,
+    cancelled_at timestamptz,
+    created_by   bigint references users (id) on delete set null,
+    created_at   timestamptz
+);
+
+create index user_invitations_user_id_idx on user_invitations (user_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+drop index users_email_trgm_idx;
+drop index users_name_trgm_idx;
\ No newline at end of file
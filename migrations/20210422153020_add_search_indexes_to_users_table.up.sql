@@ -0,0 +1,4 @@
+create extension if not exists pg_trgm;
+
+create index users_name_trgm_idx on users using gin (name gin_trgm_ops);
+create index users_email_trgm_idx on users using gin (email gin_trgm_ops);
\ No newline at end of file
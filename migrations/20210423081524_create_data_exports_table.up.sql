@@ -0,0 +1,13 @@
+create table data_exports
+(
+    id          serial primary key,
+    user_id     bigint      not null references users (id) on delete cascade,
+    status      text        not null,
+    file_key    text        not null default '',
+    error       text        not null default '',
+    created_by  bigint references users (id) on delete set null,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    finished_at timestamptz
+);
+create index data_exports_user_id_idx on data_exports (user_id);
\ No newline at end of file
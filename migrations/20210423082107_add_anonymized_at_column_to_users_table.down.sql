@@ -0,0 +1,2 @@
+alter table users
+    drop column anonymized_at;
\ No newline at end of file
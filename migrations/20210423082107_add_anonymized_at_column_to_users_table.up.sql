@@ -0,0 +1,2 @@
+alter table users
+    add column anonymized_at timestamptz;
\ No newline at end of file
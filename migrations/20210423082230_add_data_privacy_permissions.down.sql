@@ -0,0 +1 @@
+delete from permissions where name in ('user.export_data', 'user.erase');
\ No newline at end of file
@@ -0,0 +1,6 @@
+insert into permissions (name, description)
+values ('user.export_data', 'Export the personal data of user'),
+       ('user.erase', 'Erase the personal data of user');
+insert into role_permissions (role_name, permission_name)
+values ('admin', 'user.export_data'),
+       ('admin', 'user.erase');
\ No newline at end of file
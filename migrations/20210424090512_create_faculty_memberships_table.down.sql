@@ -0,0 +1 @@
+drop table faculty_memberships;
\ No newline at end of file
@@ -0,0 +1,19 @@
+create table faculty_memberships
+(
+    id         serial primary key,
+    user_id    bigint      not null references users (id) on delete cascade,
+    faculty_id bigint      not null references faculties (id),
+    started_at timestamptz not null,
+    ended_at   timestamptz,
+    created_by bigint references users (id) on delete set null
+);
+
+create index faculty_memberships_user_id_idx on faculty_memberships (user_id);
+-- a user belongs to at most one faculty at a time
+create unique index faculty_memberships_open_idx on faculty_memberships (user_id) where ended_at is null;
+
+-- nothing is known about earlier transfers, the current faculty starts when the user was created
+insert into faculty_memberships (user_id, faculty_id, started_at)
+select id, faculty_id, created_at
+from users
+where faculty_id is not null;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table contributions
+    drop column faculty_id;
\ No newline at end of file
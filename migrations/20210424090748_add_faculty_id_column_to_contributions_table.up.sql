@@ -0,0 +1,10 @@
+alter table contributions
+    add column faculty_id bigint references faculties (id);
+
+-- contributions were attributed to the current faculty of their author until now
+update contributions
+set faculty_id = users.faculty_id
+from users
+where users.id = contributions.user_id;
+
+create index contributions_faculty_id_idx on contributions (faculty_id);
\ No newline at end of file
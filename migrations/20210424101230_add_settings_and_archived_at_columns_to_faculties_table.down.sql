@@ -0,0 +1,5 @@
+alter table faculties
+    drop column contact_email,
+    drop column guest_access,
+    drop column max_contributions_per_student,
+    drop column archived_at;
\ No newline at end of file
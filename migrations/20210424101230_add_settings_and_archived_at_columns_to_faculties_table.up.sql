@@ -0,0 +1,5 @@
+alter table faculties
+    add column contact_email                 text    not null default '',
+    add column guest_access                  boolean not null default true,
+    add column max_contributions_per_student integer,
+    add column archived_at                   timestamptz;
\ No newline at end of file
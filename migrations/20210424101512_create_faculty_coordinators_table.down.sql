@@ -0,0 +1 @@
+drop table faculty_coordinators;
\ No newline at end of file
@@ -0,0 +1,11 @@
+create table faculty_coordinators
+(
+    faculty_id bigint  not null references faculties (id) on delete cascade,
+    user_id    bigint  not null references users (id) on delete cascade,
+    deputy     boolean not null default false,
+    created_at timestamptz,
+    primary key (faculty_id, user_id)
+);
+
+create index faculty_coordinators_user_id_idx on faculty_coordinators (user_id);
+create unique index faculty_coordinators_deputy_idx on faculty_coordinators (faculty_id) where deputy;
\ No newline at end of file
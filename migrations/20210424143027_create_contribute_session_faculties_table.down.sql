@@ -0,0 +1 @@
+drop table contribute_session_faculties;
\ No newline at end of file
@@ -0,0 +1,8 @@
+create table contribute_session_faculties
+(
+    contribute_session_id bigint not null references contribute_sessions (id) on delete cascade,
+    faculty_id            bigint not null references faculties (id) on delete cascade,
+    primary key (contribute_session_id, faculty_id)
+);
+
+create index contribute_session_faculties_faculty_id_idx on contribute_session_faculties (faculty_id);
\ No newline at end of file
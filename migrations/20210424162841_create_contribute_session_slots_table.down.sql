@@ -0,0 +1 @@
+drop table contribute_session_slots;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- one slot per faculty a session is open to, a session open to every faculty has a single slot
+-- with an unbounded faculty scope, so the sessions sharing a faculty cannot overlap in time
+create table contribute_session_slots
+(
+    contribute_session_id bigint    not null references contribute_sessions (id) on delete cascade,
+    faculty_scope         int4range not null,
+    period                tstzrange not null,
+    exclude using gist (faculty_scope with &&, period with &&)
+);
+
+create index contribute_session_slots_contribute_session_id_idx on contribute_session_slots (contribute_session_id);
+
+-- sessions overlapping an earlier one were allowed until now, they are skipped and keep no slot
+insert into contribute_session_slots (contribute_session_id, faculty_scope, period)
+select s.id, int4range(f.faculty_id, f.faculty_id, '[]'), tstzrange(s.open_time, s.final_closure_time, '[)')
+from contribute_sessions s
+         left join contribute_session_faculties f on f.contribute_session_id = s.id
+order by s.id
+on conflict do nothing;
\ No newline at end of file
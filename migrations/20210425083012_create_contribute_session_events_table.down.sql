@@ -0,0 +1 @@
+drop table contribute_session_events;
\ No newline at end of file
@@ -0,0 +1,14 @@
+create table contribute_session_events
+(
+    contribute_session_id bigint      not null references contribute_sessions (id) on delete cascade,
+    event                 text        not null,
+    fired_at              timestamptz not null,
+    primary key (contribute_session_id, event)
+);
+
+-- the sessions that ended before the scheduler existed must not be reminded or exported again
+insert into contribute_session_events (contribute_session_id, event, fired_at)
+select s.id, e.event, now()
+from contribute_sessions s
+         cross join (values ('closing_reminder'), ('closure_reached'), ('final_closure_reached')) e(event)
+where s.final_closure_time <= now();
\ No newline at end of file
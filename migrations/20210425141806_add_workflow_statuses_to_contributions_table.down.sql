@@ -0,0 +1,10 @@
+update contributions
+set status = case
+                 when status = 'selected' then 'accepted'
+                 when status = 'withdrawn' then 'rejected'
+                 when status in ('draft', 'submitted', 'under_review', 'changes_requested', 'resubmitted') then 'reviewing'
+                 else status
+    end;
+
+alter table contributions
+    drop column status_reason;
\ No newline at end of file
@@ -0,0 +1,6 @@
+alter table contributions
+    add column status_reason text;
+
+update contributions
+set status = 'submitted'
+where status = 'reviewing';
\ No newline at end of file
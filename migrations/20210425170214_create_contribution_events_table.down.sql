@@ -0,0 +1 @@
+drop table contribution_events;
\ No newline at end of file
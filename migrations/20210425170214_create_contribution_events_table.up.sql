@@ -0,0 +1,26 @@
+create table contribution_events
+(
+    id              bigserial primary key,
+    contribution_id bigint      not null references contributions (id) on delete cascade,
+    actor_id        bigint references users (id) on delete set null,
+    type            text        not null,
+    before          text,
+    after           text,
+    note            text,
+    created_at      timestamptz not null default now()
+);
+
+create index contribution_events_contribution_id_created_at_idx on contribution_events (contribution_id, created_at);
+
+insert into contribution_events (contribution_id, actor_id, type, after, created_at)
+select id, user_id, 'created', status, coalesce(created_at, now())
+from contributions;
+
+insert into contribution_events (contribution_id, actor_id, type, after, created_at)
+select c.id, c.user_id, 'article_version_uploaded', v.link_original, v.created_at
+from article_versions v
+         join contributions c on c.article_id = v.article_id;
+
+insert into contribution_events (contribution_id, actor_id, type, after, created_at)
+select contribution_id, user_id, 'commented', id::text, coalesce(created_at, now())
+from comments;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table contributions
+    drop column feedback_due_at,
+    drop column feedback_given_at,
+    drop column feedback_escalated_at;
\ No newline at end of file
@@ -0,0 +1,27 @@
+alter table contributions
+    add column feedback_due_at       timestamptz,
+    add column feedback_given_at     timestamptz,
+    add column feedback_escalated_at timestamptz;
+
+update contributions
+set feedback_due_at = coalesce(created_at, now()) + interval '14 days';
+
+alter table contributions
+    alter column feedback_due_at set not null;
+
+update contributions c
+set feedback_given_at = (select min(comments.created_at)
+                         from comments
+                                  join users on users.id = comments.user_id
+                         where comments.contribution_id = c.id
+                           and users.role = 'marketing_coordinator');
+
+-- contributions of ended sessions are not escalated anymore
+update contributions c
+set feedback_escalated_at = now()
+from contribute_sessions s
+where s.id = c.contribute_session_id
+  and s.final_closure_time <= now()
+  and c.feedback_given_at is null;
+
+create index contributions_feedback_due_at_idx on contributions (feedback_due_at) where feedback_given_at is null;
\ No newline at end of file
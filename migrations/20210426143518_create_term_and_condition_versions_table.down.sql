@@ -0,0 +1,2 @@
+drop table term_and_condition_acceptances;
+drop table term_and_condition_versions;
\ No newline at end of file
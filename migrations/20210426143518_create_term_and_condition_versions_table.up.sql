@@ -0,0 +1,24 @@
+create table term_and_condition_versions
+(
+    id         serial primary key,
+    content    text        not null,
+    created_by bigint references users (id) on delete set null,
+    created_at timestamptz not null default now()
+);
+
+insert into term_and_condition_versions (content, created_at)
+select value, coalesce(updated_at, now())
+from system_data
+where key = 'term_and_condition';
+
+create table term_and_condition_acceptances
+(
+    id              bigserial primary key,
+    user_id         bigint      not null references users (id) on delete cascade,
+    term_version_id bigint      not null references term_and_condition_versions (id),
+    contribution_id bigint references contributions (id) on delete set null,
+    ip_address      text,
+    accepted_at     timestamptz not null default now()
+);
+
+create index term_and_condition_acceptances_user_id_idx on term_and_condition_acceptances (user_id, term_version_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table users
+    drop column external_subject,
+    drop column external_provisioned;
\ No newline at end of file
@@ -0,0 +1,5 @@
+alter table users
+    add column external_subject     text,
+    add column external_provisioned boolean not null default false;
+
+create unique index users_external_subject_idx on users (external_subject);
\ No newline at end of file
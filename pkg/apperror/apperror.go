@@ -17,6 +17,7 @@ const (
 	ErrNotFound     AppErrCode = "not_found"
 	ErrForbidden    AppErrCode = "forbidden"
 	ErrUnauthorized AppErrCode = "unauthorized"
+	ErrTooMany      AppErrCode = "too_many_requests"
 )
 
 type appError struct {
@@ -79,6 +80,12 @@ func (a *appError) ToResponse(ctx echo.Context) error {
 			Code:    a.Code,
 			Data:    a.Data,
 		})
+	case ErrTooMany:
+		return ctx.JSON(http.StatusTooManyRequests, appErrorRes{
+			Message: valueOrDefault(a.Message, "too many requests"),
+			Code:    a.Code,
+			Data:    a.Data,
+		})
 	default:
 		return ctx.JSON(http.StatusInternalServerError, appErrorRes{
 			Message: valueOrDefault(a.Message, "internal server error"),
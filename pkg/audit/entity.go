@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type Action string
+
+const (
+	LoginLockout Action = "auth.login_lockout"
+	// an administrator started acting as another user
+	ImpersonationStart Action = "auth.impersonation_start"
+	// a request made with an impersonation token, including blocked ones
+	ImpersonatedRequest Action = "auth.impersonated_request"
+	// the personal data of a user was exported or erased, see privacy.Service
+	UserDataExported Action = "user.data_exported"
+	UserErased       Action = "user.erased"
+)
+
+type Entity struct {
+	Id         int
+	ActorId    *int
+	Action     Action
+	TargetType string
+	TargetId   string
+	IpAddress  string
+	UserAgent  string
+	Data       Data
+	CreatedAt  time.Time
+}
+
+func (e *Entity) TableName() string {
+	return "audit_logs"
+}
+
+// Data is free form context of an audit entry, stored as jsonb.
+type Data map[string]interface{}
+
+func (d Data) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]interface{}(d))
+}
+
+func (d *Data) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return errors.New("unsupported type of audit data")
+	}
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func InitializeRepository(db *gorm.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
+	db := r.db.WithContext(ctx).Create(entity)
+	return entity, db.Error
+}
@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"mcm-api/pkg/log"
+)
+
+type Service struct {
+	repository *repository
+}
+
+func InitializeService(repository *repository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+func (s Service) Record(ctx context.Context, entry *Entity) error {
+	_, err := s.repository.Create(ctx, entry)
+	return err
+}
+
+// RecordAsync writes the entry without holding up the request, failures are only logged.
+func (s Service) RecordAsync(entry *Entity) {
+	go func() {
+		err := s.Record(context.Background(), entry)
+		if err != nil {
+			log.Logger.Error("write audit log failed", zap.String("action", string(entry.Action)), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,121 @@
+package authz
+
+import (
+	"github.com/labstack/echo/v4"
+	"mcm-api/pkg/apperror"
+	"net/http"
+	"strconv"
+)
+
+// @Tags Api keys
+// @Summary List api keys
+// @Description List own api keys, key managers can list the keys of other users
+// @Accept  json
+// @Produce  json
+// @Param params query authz.ApiKeyIndexQuery false "index query"
+// @Success 200 {array} authz.ApiKeyResponse
+// @Security ApiKeyAuth
+// @Router /auth/api-keys [get]
+func (h Handler) apiKeys(ctx echo.Context) error {
+	query := new(ApiKeyIndexQuery)
+	err := ctx.Bind(query)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.FindApiKeys(ctx.Request().Context(), query)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Api keys
+// @Summary Show an api key
+// @Description get api key by ID
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} authz.ApiKeyResponse
+// @Security ApiKeyAuth
+// @Router /auth/api-keys/{id} [get]
+func (h Handler) apiKey(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), ctx)
+	}
+	res, err := h.service.FindApiKey(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Api keys
+// @Summary Create an api key
+// @Description Create an api key for yourself or a service account, the key is only returned once. Send it in the X-API-Key header.
+// @Accept  json
+// @Produce  json
+// @Param body body authz.ApiKeyCreateRequest true "create api key"
+// @Success 200 {object} authz.ApiKeyCreateResponse
+// @Security ApiKeyAuth
+// @Router /auth/api-keys [post]
+func (h Handler) createApiKey(ctx echo.Context) error {
+	req := new(ApiKeyCreateRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.CreateApiKey(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Api keys
+// @Summary Update an api key
+// @Description Rename an api key or change its address restrictions and expiry, scopes cannot be changed
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Param body body authz.ApiKeyUpdateRequest true "update api key"
+// @Success 200 {object} authz.ApiKeyResponse
+// @Security ApiKeyAuth
+// @Router /auth/api-keys/{id} [patch]
+func (h Handler) updateApiKey(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), ctx)
+	}
+	req := new(ApiKeyUpdateRequest)
+	err = ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.UpdateApiKey(ctx.Request().Context(), id, req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Api keys
+// @Summary Revoke an api key
+// @Description Revoke an api key, it stops working immediately
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /auth/api-keys/{id} [delete]
+func (h Handler) revokeApiKey(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), ctx)
+	}
+	err = h.service.RevokeApiKey(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
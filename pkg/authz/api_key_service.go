@@ -0,0 +1,305 @@
+package authz
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/user"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// keys look like mcm_<prefix>_<secret>
+	apiKeyTag = "mcm"
+	// last use is written at most once per interval, unit: minutes
+	apiKeyTouchInterval = 1
+)
+
+func (s Service) FindApiKeys(ctx context.Context, query *ApiKeyIndexQuery) ([]*ApiKeyResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userId := &loggedInUser.Id
+	if query.UserId != nil && *query.UserId != loggedInUser.Id {
+		if !enforcer.Enforce(*loggedInUser, enforcer.ManageApiKey) {
+			return nil, apperror.New(apperror.ErrForbidden, "you can only list your own api keys", nil)
+		}
+		userId = query.UserId
+		if *userId == 0 {
+			userId = nil
+		}
+	}
+	entities, err := s.repository.FindApiKeys(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ApiKeyResponse, len(entities))
+	for i, entity := range entities {
+		result[i] = mapApiKeyToResponse(entity)
+	}
+	return result, nil
+}
+
+func (s Service) FindApiKey(ctx context.Context, id int) (*ApiKeyResponse, error) {
+	entity, err := s.findAccessibleApiKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return mapApiKeyToResponse(entity), nil
+}
+
+// CreateApiKey issues a key for the logged in user or, for key managers, for a service
+// account. The key can never do more than the role of its owner.
+func (s Service) CreateApiKey(ctx context.Context, req *ApiKeyCreateRequest) (*ApiKeyCreateResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if loggedInUser.ApiKeyId != nil || loggedInUser.ImpersonatedBy != nil {
+		return nil, apperror.New(apperror.ErrForbidden, "api keys can only be created from an interactive session", nil)
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.userService.FindById(ctx, loggedInUser.Id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId != nil && *req.UserId != loggedInUser.Id {
+		if !enforcer.Enforce(*loggedInUser, enforcer.ManageApiKey) {
+			return nil, apperror.New(apperror.ErrForbidden, "you can only create your own api keys", nil)
+		}
+		owner, err = s.userService.FindById(ctx, *req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if !owner.ServiceAccount {
+			return nil, apperror.New(apperror.ErrInvalid, "api keys of other users can only be created for service accounts", nil)
+		}
+	}
+	for _, scope := range req.Scopes {
+		if !enforcer.Can(owner.Role, scope) {
+			return nil, apperror.New(apperror.ErrInvalid, "the role of the key owner does not have permission "+string(scope), nil)
+		}
+	}
+
+	prefix, err := generateApiKeyPrefix()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	scopes := make(StringList, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = string(scope)
+	}
+	entity, err := s.repository.CreateApiKey(ctx, &ApiKeyEntity{
+		UserId:     owner.Id,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashToken(secret),
+		Scopes:     scopes,
+		AllowedIps: req.AllowedIps,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  &loggedInUser.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ApiKeyCreateResponse{
+		Key:            formatApiKey(prefix, secret),
+		ApiKeyResponse: mapApiKeyToResponse(entity),
+	}, nil
+}
+
+func (s Service) UpdateApiKey(ctx context.Context, id int, req *ApiKeyUpdateRequest) (*ApiKeyResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.findAccessibleApiKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.RevokedAt != nil {
+		return nil, apperror.New(apperror.ErrInvalid, "api key is revoked", nil)
+	}
+	if req.Name != nil {
+		entity.Name = *req.Name
+	}
+	if req.AllowedIps != nil {
+		entity.AllowedIps = *req.AllowedIps
+	}
+	if req.ExpiresAt != nil {
+		entity.ExpiresAt = req.ExpiresAt
+	}
+	entity, err = s.repository.UpdateApiKey(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return mapApiKeyToResponse(entity), nil
+}
+
+func (s Service) RevokeApiKey(ctx context.Context, id int) error {
+	entity, err := s.findAccessibleApiKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if entity.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	entity.RevokedAt = &now
+	_, err = s.repository.UpdateApiKey(ctx, entity)
+	return err
+}
+
+// AuthenticateApiKey is called by the authentication middleware for requests with
+// an X-API-Key header. Every failure looks the same to the caller.
+func (s Service) AuthenticateApiKey(ctx context.Context, key string, ipAddress string) (*enforcer.LoggedInUser, error) {
+	invalid := apperror.New(apperror.ErrUnauthorized, "invalid api key", nil)
+	prefix, secret, ok := parseApiKey(key)
+	if !ok {
+		return nil, invalid
+	}
+	entity, err := s.repository.FindApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if !tokenHashEqual(entity.SecretHash, hashToken(secret)) || !entity.IsActive(time.Now()) {
+		return nil, invalid
+	}
+	if !ipAllowed(entity.AllowedIps, ipAddress) {
+		return nil, apperror.New(apperror.ErrForbidden, "api key is not allowed from this address", nil)
+	}
+	if entity.User.Status == user.UserDisable {
+		return nil, apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
+	}
+	go s.touchApiKey(entity.Id, ipAddress)
+
+	scopes := make([]enforcer.Permission, len(entity.Scopes))
+	for i, scope := range entity.Scopes {
+		scopes[i] = enforcer.Permission(scope)
+	}
+	return &enforcer.LoggedInUser{
+		Id:        entity.User.Id,
+		Email:     entity.User.Email,
+		Name:      entity.User.Name,
+		Role:      entity.User.Role,
+		FacultyId: entity.User.FacultyId,
+		ApiKeyId:  &entity.Id,
+		Scopes:    scopes,
+	}, nil
+}
+
+func (s Service) touchApiKey(id int, ipAddress string) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancelFunc()
+	err := s.repository.TouchApiKey(ctx, id, ipAddress, time.Minute*apiKeyTouchInterval)
+	if err != nil {
+		log.Logger.Error("update api key last use failed", zap.Error(err))
+	}
+}
+
+// findAccessibleApiKey loads a key owned by the logged in user, or any key for key managers.
+func (s Service) findAccessibleApiKey(ctx context.Context, id int) (*ApiKeyEntity, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.repository.FindApiKeyById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "api key not found", err)
+		}
+		return nil, err
+	}
+	if entity.UserId != loggedInUser.Id && !enforcer.Enforce(*loggedInUser, enforcer.ManageApiKey) {
+		return nil, apperror.New(apperror.ErrNotFound, "api key not found", nil)
+	}
+	return entity, nil
+}
+
+func generateApiKeyPrefix() (string, error) {
+	b := make([]byte, 6)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func formatApiKey(prefix string, secret string) string {
+	return apiKeyTag + "_" + prefix + "_" + secret
+}
+
+func parseApiKey(key string) (string, string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func ipAllowed(allowed []string, ipAddress string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if allowedIp := net.ParseIP(entry); allowedIp != nil {
+			if allowedIp.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mapApiKeyToResponse(entity *ApiKeyEntity) *ApiKeyResponse {
+	scopes := make([]enforcer.Permission, len(entity.Scopes))
+	for i, scope := range entity.Scopes {
+		scopes[i] = enforcer.Permission(scope)
+	}
+	allowedIps := entity.AllowedIps
+	if allowedIps == nil {
+		allowedIps = StringList{}
+	}
+	return &ApiKeyResponse{
+		Id:         entity.Id,
+		UserId:     entity.UserId,
+		Name:       entity.Name,
+		Prefix:     apiKeyTag + "_" + entity.Prefix,
+		Scopes:     scopes,
+		AllowedIps: allowedIps,
+		ExpiresAt:  entity.ExpiresAt,
+		LastUsedAt: entity.LastUsedAt,
+		LastUsedIp: entity.LastUsedIp,
+		RevokedAt:  entity.RevokedAt,
+		CreatedBy:  entity.CreatedBy,
+		CreatedAt:  entity.CreatedAt,
+	}
+}
@@ -2,22 +2,62 @@ package authz
 
 import (
 	"github.com/labstack/echo/v4"
+	"mcm-api/config"
 	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/middleware"
 	"net/http"
 )
 
 type Handler struct {
-	service *Service
+	config            *config.Config
+	authenticator     *middleware.Authenticator
+	service           *Service
+	permissionService *PermissionService
 }
 
-func NewAuthHandler(service *Service) *Handler {
+func NewAuthHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service, permissionService *PermissionService) *Handler {
 	return &Handler{
-		service: service,
+		config:            config,
+		authenticator:     authenticator,
+		service:           service,
+		permissionService: permissionService,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
 	group.POST("/login", h.login)
+	group.POST("/login/2fa", h.loginTwoFactor)
+	group.POST("/2fa/setup", h.setupTwoFactor)
+	group.POST("/2fa/activate", h.activateTwoFactor)
+	group.POST("/refresh", h.refresh)
+	group.POST("/logout", h.logout, h.authenticator.RequireAuthentication(), middleware.RequireSession())
+	group.POST("/password/forgot", h.forgotPassword)
+	group.POST("/password/reset", h.resetPassword)
+	group.POST("/invitations/accept", h.acceptInvitation)
+	group.GET("/oidc/authorize", h.oidcAuthorize)
+	group.POST("/oidc/callback", h.oidcCallback)
+	group.POST("/impersonate", h.impersonate,
+		h.authenticator.RequireAuthentication(),
+		middleware.RequireSession(),
+		middleware.RequirePermission(enforcer.ImpersonateUser),
+	)
+	apiKeys := group.Group("/api-keys",
+		h.authenticator.RequireAuthentication(),
+		middleware.RequireSession(),
+		middleware.RequirePermission(enforcer.CreateApiKey),
+	)
+	apiKeys.GET("", h.apiKeys)
+	apiKeys.POST("", h.createApiKey)
+	apiKeys.GET("/:id", h.apiKey)
+	apiKeys.PATCH("/:id", h.updateApiKey)
+	apiKeys.DELETE("/:id", h.revokeApiKey)
+	group.GET("/permissions", h.permissions, h.authenticator.RequireAuthentication(), middleware.RequireSession())
+	group.GET("/roles", h.roles,
+		h.authenticator.RequireAuthentication(),
+		middleware.RequireSession(),
+		middleware.RequirePermission(enforcer.ReadRole),
+	)
 }
 
 // @Tags Auth
@@ -34,9 +74,271 @@ func (h Handler) login(ctx echo.Context) error {
 	if err != nil {
 		return err
 	}
-	loginResponse, err := h.service.Login(ctx.Request().Context(), req)
+	loginResponse, err := h.service.Login(ctx.Request().Context(), req, clientInfo(ctx))
 	if err != nil {
 		return apperror.HandleError(err, ctx)
 	}
 	return ctx.JSON(http.StatusOK, loginResponse)
 }
+
+// @Tags Auth
+// @Summary Login second step
+// @Description Exchange the challenge token returned by login and an authenticator or recovery code for tokens
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorLoginRequest true "2fa login req"
+// @Success 200 {object} authz.LoginResponse
+// @Router /auth/login/2fa [post]
+func (h Handler) loginTwoFactor(ctx echo.Context) error {
+	req := new(TwoFactorLoginRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	loginResponse, err := h.service.LoginTwoFactor(ctx.Request().Context(), req, clientInfo(ctx))
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// @Tags Auth
+// @Summary Set up two-factor authentication during login
+// @Description For roles that require two-factor authentication, generate an authenticator secret using the challenge token returned by login
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorSetupRequest true "2fa setup req"
+// @Success 200 {object} authz.TwoFactorSetupResponse
+// @Router /auth/2fa/setup [post]
+func (h Handler) setupTwoFactor(ctx echo.Context) error {
+	req := new(TwoFactorSetupRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.SetupTwoFactorWithChallenge(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Auth
+// @Summary Activate two-factor authentication during login
+// @Description Confirm the authenticator with its first code, returns the recovery codes and logs the user in
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorLoginRequest true "2fa activate req"
+// @Success 200 {object} authz.TwoFactorActivateResponse
+// @Router /auth/2fa/activate [post]
+func (h Handler) activateTwoFactor(ctx echo.Context) error {
+	req := new(TwoFactorLoginRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.ActivateTwoFactorWithChallenge(ctx.Request().Context(), req, clientInfo(ctx))
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Auth
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token, the refresh token is rotated
+// @Accept  json
+// @Produce  json
+// @Param body body authz.RefreshRequest true "refresh req"
+// @Success 200 {object} authz.LoginResponse
+// @Router /auth/refresh [post]
+func (h Handler) refresh(ctx echo.Context) error {
+	req := new(RefreshRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	loginResponse, err := h.service.Refresh(ctx.Request().Context(), req, clientInfo(ctx))
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// @Tags Auth
+// @Summary Logout
+// @Description Revoke the current session
+// @Accept  json
+// @Produce  json
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /auth/logout [post]
+func (h Handler) logout(ctx echo.Context) error {
+	err := h.service.Logout(ctx.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// @Tags Auth
+// @Summary Forgot password
+// @Description Send a password reset link to the email if it belongs to an account
+// @Accept  json
+// @Produce  json
+// @Param body body authz.ForgotPasswordRequest true "forgot password req"
+// @Success 200
+// @Router /auth/password/forgot [post]
+func (h Handler) forgotPassword(ctx echo.Context) error {
+	req := new(ForgotPasswordRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	err = h.service.ForgotPassword(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// @Tags Auth
+// @Summary Reset password
+// @Description Set a new password using the token from the reset email
+// @Accept  json
+// @Produce  json
+// @Param body body authz.ResetPasswordRequest true "reset password req"
+// @Success 200
+// @Router /auth/password/reset [post]
+func (h Handler) resetPassword(ctx echo.Context) error {
+	req := new(ResetPasswordRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	err = h.service.ResetPassword(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// @Tags Auth
+// @Summary Accept an invitation
+// @Description Choose the password of an invited account using the token from the invitation email, the account is active afterwards
+// @Accept  json
+// @Produce  json
+// @Param body body authz.AcceptInvitationRequest true "accept invitation req"
+// @Success 200
+// @Router /auth/invitations/accept [post]
+func (h Handler) acceptInvitation(ctx echo.Context) error {
+	req := new(AcceptInvitationRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	err = h.service.AcceptInvitation(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// @Tags Auth
+// @Summary Start single sign-on
+// @Description Returns the identity provider url to redirect the browser to, the provider redirects back to the web app with code and state
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} authz.OidcAuthorizeResponse
+// @Router /auth/oidc/authorize [get]
+func (h Handler) oidcAuthorize(ctx echo.Context) error {
+	res, err := h.service.OidcAuthorize(ctx.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Auth
+// @Summary Finish single sign-on
+// @Description Exchange the code and state received from the identity provider for tokens
+// @Accept  json
+// @Produce  json
+// @Param body body authz.OidcCallbackRequest true "oidc callback req"
+// @Success 200 {object} authz.LoginResponse
+// @Router /auth/oidc/callback [post]
+func (h Handler) oidcCallback(ctx echo.Context) error {
+	req := new(OidcCallbackRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	loginResponse, err := h.service.OidcCallback(ctx.Request().Context(), req, clientInfo(ctx))
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// @Tags Auth
+// @Summary Impersonate a user
+// @Description Returns a short lived access token acting as the user. The token is read only unless allowWrite is set, deletions and account changes are always blocked. Every request made with it is audited.
+// @Accept  json
+// @Produce  json
+// @Param body body authz.ImpersonateRequest true "impersonate req"
+// @Success 200 {object} authz.ImpersonateResponse
+// @Security ApiKeyAuth
+// @Router /auth/impersonate [post]
+func (h Handler) impersonate(ctx echo.Context) error {
+	req := new(ImpersonateRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.service.Impersonate(ctx.Request().Context(), req, clientInfo(ctx))
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Auth
+// @Summary Effective permissions
+// @Description Permissions of the logged in user, with the permissions on a contribution or contribute session when their id is given
+// @Accept  json
+// @Produce  json
+// @Param params query authz.PermissionsQuery false "resources to check"
+// @Success 200 {object} authz.PermissionsResponse
+// @Security ApiKeyAuth
+// @Router /auth/permissions [get]
+func (h Handler) permissions(ctx echo.Context) error {
+	query := new(PermissionsQuery)
+	err := ctx.Bind(query)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	res, err := h.permissionService.FindPermissions(ctx.Request().Context(), query)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// @Tags Auth
+// @Summary Role matrix
+// @Description The role and permission matrix currently enforced by the api
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} authz.RoleMatrixResponse
+// @Security ApiKeyAuth
+// @Router /auth/roles [get]
+func (h Handler) roles(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.permissionService.RoleMatrix())
+}
+
+func clientInfo(ctx echo.Context) *ClientInfo {
+	return &ClientInfo{
+		UserAgent: ctx.Request().UserAgent(),
+		IpAddress: ctx.RealIP(),
+	}
+}
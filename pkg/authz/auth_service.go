@@ -2,63 +2,513 @@ package authz
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"mcm-api/config"
 	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/audit"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/queue"
 	"mcm-api/pkg/user"
 	"strconv"
+	"strings"
 	"time"
 )
 
-// access token ttl, unit: hours
-const accessTokenTtl = 168
+const (
+	// access token ttl, unit: minutes
+	accessTokenTtl = 15
+	// refresh token ttl, unit: hours
+	refreshTokenTtl = 720
+	// password reset token ttl, unit: minutes
+	passwordResetTokenTtl = 60
+)
 
 type Service struct {
-	config      *config.Config
-	userService *user.Service
+	config       *config.Config
+	repository   *repository
+	userService  *user.Service
+	queue        queue.Queue
+	redis        *redis.Client
+	auditService *audit.Service
+	oidc         *oidcClient
 }
 
-func InitializeAuthService(config *config.Config, userService *user.Service) *Service {
+func InitializeAuthService(
+	config *config.Config,
+	repository *repository,
+	userService *user.Service,
+	queue queue.Queue,
+	redis *redis.Client,
+	auditService *audit.Service,
+) *Service {
 	return &Service{
-		config:      config,
-		userService: userService,
+		config:       config,
+		repository:   repository,
+		userService:  userService,
+		queue:        queue,
+		redis:        redis,
+		auditService: auditService,
+		oidc:         newOidcClient(config),
 	}
 }
 
-func (s Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+func (s Service) Login(ctx context.Context, req *LoginRequest, client *ClientInfo) (*LoginResponse, error) {
 	err := req.Validate()
 	if err != nil {
 		return nil, err
 	}
+	err = s.checkLoginAllowed(ctx, req.Email, client)
+	if err != nil {
+		return nil, err
+	}
 	userResponse, err := s.userService.FindByEmailAndPassword(ctx, req.Email, req.Password)
 	if err != nil {
-		return nil, apperror.New(apperror.ErrUnauthorized, "Wrong username or password", err)
+		if !apperror.Is(err, apperror.ErrUnauthorized) {
+			return nil, err
+		}
+		err = s.recordLoginFailure(ctx, req.Email, client)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperror.New(apperror.ErrUnauthorized, "Wrong username or password", nil)
+	}
+	s.resetLoginFailures(ctx, req.Email)
+
+	if userResponse.Status == user.UserDisable {
+		return nil, apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
+	}
+	if userResponse.Status == user.UserPending {
+		return nil, apperror.New(apperror.ErrForbidden, "Please accept your invitation first", nil)
+	}
+	if !s.isPasswordLoginAllowed(userResponse.Role) {
+		return nil, apperror.New(apperror.ErrForbidden, "Password login is disabled for your account, please use single sign-on", nil)
+	}
+	return s.completeLogin(ctx, userResponse, client)
+}
+
+// OidcAuthorize starts a single sign-on login and returns the identity provider url
+// the browser has to be redirected to.
+func (s Service) OidcAuthorize(ctx context.Context) (*OidcAuthorizeResponse, error) {
+	if !s.oidc.Enabled() {
+		return nil, apperror.New(apperror.ErrNotFound, "single sign-on is not configured", nil)
+	}
+	state, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	oidcState := &oidcState{}
+	oidcState.Verifier, err = generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	oidcState.Nonce, err = generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := json.Marshal(oidcState)
+	if err != nil {
+		return nil, err
+	}
+	err = s.redis.Set(ctx, oidcStateKey(state), bytes, time.Minute*oidcStateTtl).Err()
+	if err != nil {
+		return nil, err
+	}
+	authorizationUrl, err := s.oidc.AuthorizationUrl(ctx, state, oidcState.Nonce, oidcState.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	return &OidcAuthorizeResponse{Url: authorizationUrl}, nil
+}
+
+// OidcCallback finishes a single sign-on login, the user is provisioned on first login
+// and receives the same tokens as a password login.
+func (s Service) OidcCallback(ctx context.Context, req *OidcCallbackRequest, client *ClientInfo) (*LoginResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !s.oidc.Enabled() {
+		return nil, apperror.New(apperror.ErrNotFound, "single sign-on is not configured", nil)
+	}
+	bytes, err := s.redis.Get(ctx, oidcStateKey(req.State)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, apperror.New(apperror.ErrUnauthorized, "login request expired, please try again", err)
+		}
+		return nil, err
+	}
+	// state is single use
+	s.redis.Del(ctx, oidcStateKey(req.State))
+	oidcState := new(oidcState)
+	err = json.Unmarshal(bytes, oidcState)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.oidc.Exchange(ctx, req.Code, oidcState.Verifier)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrUnauthorized, "single sign-on failed", err)
+	}
+	claims, err := s.oidc.VerifyIdToken(ctx, idToken, oidcState.Nonce)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrUnauthorized, "single sign-on failed", err)
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, apperror.New(apperror.ErrUnauthorized, "identity provider did not return a verified email", nil)
 	}
 
+	var role enforcer.Role
+	var facultyName string
+	if s.config.OidcProvisionUsers {
+		role, facultyName = mapOidcGroups(s.config, claims.Groups)
+	}
+	userResponse, err := s.userService.SyncExternalUser(ctx, &user.ExternalUserReq{
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		Name:        claims.Name,
+		Role:        role,
+		FacultyName: facultyName,
+		Provision:   s.config.OidcProvisionUsers,
+	})
+	if apperror.Is(err, apperror.ErrNotFound) {
+		return nil, apperror.New(apperror.ErrUnauthorized, "no account is registered for "+claims.Email, err)
+	}
+	if err != nil {
+		return nil, err
+	}
 	if userResponse.Status == user.UserDisable {
 		return nil, apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
 	}
+	if userResponse.Status == user.UserPending {
+		return nil, apperror.New(apperror.ErrForbidden, "Please accept your invitation first", nil)
+	}
+	return s.completeLogin(ctx, userResponse, client)
+}
 
-	// Create token
-	token := jwt.New(jwt.SigningMethodHS256)
+func (s Service) Refresh(ctx context.Context, req *RefreshRequest, client *ClientInfo) (*LoginResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	sessionId, secret, err := parseRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.repository.FindSessionById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrUnauthorized, "invalid refresh token", err)
+		}
+		return nil, err
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, apperror.New(apperror.ErrUnauthorized, "session expired", nil)
+	}
+	presentedHash := hashToken(secret)
+	if session.PreviousTokenHash != "" && tokenHashEqual(presentedHash, session.PreviousTokenHash) {
+		// a rotated token is being replayed, the session may be stolen
+		err = s.repository.RevokeSession(ctx, session.Id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperror.New(apperror.ErrUnauthorized, "refresh token reused, session revoked", nil)
+	}
+	if !tokenHashEqual(presentedHash, session.RefreshTokenHash) {
+		return nil, apperror.New(apperror.ErrUnauthorized, "invalid refresh token", nil)
+	}
+	if session.User.Status == user.UserDisable {
+		err = s.repository.RevokeSession(ctx, session.Id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
+	}
+
+	newSecret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	session.PreviousTokenHash = session.RefreshTokenHash
+	session.RefreshTokenHash = hashToken(newSecret)
+	session.ExpiresAt = time.Now().Add(time.Hour * refreshTokenTtl)
+	session.UserAgent = client.UserAgent
+	session.IpAddress = client.IpAddress
+	rotated, err := s.repository.RotateSession(ctx, session, presentedHash)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// another refresh rotated the token first, or the session was revoked meanwhile
+		return nil, apperror.New(apperror.ErrUnauthorized, "invalid refresh token", nil)
+	}
 
-	// Set claims
-	claims := token.Claims.(jwt.MapClaims)
+	userResponse, err := s.userService.FindById(ctx, session.UserId)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.generateAccessToken(session.Id, userResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: formatRefreshToken(session.Id, newSecret),
+		UserResponse: userResponse,
+	}, nil
+}
+
+func (s Service) Logout(ctx context.Context) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repository.RevokeSession(ctx, loggedInUser.SessionId)
+}
+
+// ReissueAccessToken mints a new access token for the current session, used after
+// the user changed data that is embedded in the token claims.
+func (s Service) ReissueAccessToken(ctx context.Context) (string, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	userResponse, err := s.userService.FindById(ctx, loggedInUser.Id)
+	if err != nil {
+		return "", err
+	}
+	return s.generateAccessToken(loggedInUser.SessionId, userResponse)
+}
+
+// RevokeOtherSessions logs the current user out everywhere except the current session.
+func (s Service) RevokeOtherSessions(ctx context.Context) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repository.RevokeAllSessionsOfUser(ctx, loggedInUser.Id, loggedInUser.SessionId)
+}
+
+// ValidateSession is called by the authentication middleware on every request,
+// so revoked sessions and disabled users lose access without waiting for the
+// access token to expire.
+func (s Service) ValidateSession(ctx context.Context, sessionId string, userId int) error {
+	session, err := s.repository.FindSessionById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.ErrUnauthorized, "invalid token", err)
+		}
+		return err
+	}
+	if session.UserId != userId || !session.IsActive(time.Now()) {
+		return apperror.New(apperror.ErrUnauthorized, "session expired", nil)
+	}
+	if session.User.Status == user.UserDisable {
+		return apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
+	}
+	return nil
+}
+
+// ForgotPassword issues a single-use reset token and emails it to the user.
+// It never reveals whether the email belongs to an account.
+func (s Service) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+	userResponse, err := s.userService.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if apperror.Is(err, apperror.ErrNotFound) {
+			log.Logger.Debug("password reset requested for unknown email")
+			return nil
+		}
+		return err
+	}
+	// pending users set their password with the invitation
+	if userResponse.Status != user.UserActive || userResponse.ServiceAccount {
+		return nil
+	}
+	err = s.repository.InvalidatePasswordResetTokens(ctx, userResponse.Id)
+	if err != nil {
+		return err
+	}
+	token, err := generateSecret()
+	if err != nil {
+		return err
+	}
+	_, err = s.repository.CreatePasswordResetToken(ctx, &PasswordResetTokenEntity{
+		UserId:    userResponse.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(time.Minute * passwordResetTokenTtl),
+	})
+	if err != nil {
+		return err
+	}
+	go s.addToQueue(&queue.Message{
+		Topic: queue.PasswordResetRequested,
+		Data: &queue.PasswordResetRequestedPayload{
+			UserId:        userResponse.Id,
+			Name:          userResponse.Name,
+			Email:         userResponse.Email,
+			Token:         token,
+			ExpireMinutes: passwordResetTokenTtl,
+		},
+	})
+	return nil
+}
+
+func (s Service) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+	entity, err := s.repository.FindPasswordResetTokenByHash(ctx, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.ErrInvalid, "invalid or expired reset token", err)
+		}
+		return err
+	}
+	if !entity.IsUsable(time.Now()) {
+		return apperror.New(apperror.ErrInvalid, "invalid or expired reset token", nil)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	if err != nil {
+		return err
+	}
+	// whoever knew the old password must not stay logged in, the sessions are revoked with it
+	consumed, err := s.repository.ResetPassword(ctx, entity, string(hashedPassword))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.New(apperror.ErrNotFound, "user not found", err)
+	}
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return apperror.New(apperror.ErrInvalid, "invalid or expired reset token", nil)
+	}
+	return nil
+}
+
+// AcceptInvitation sets the password of an invited user, who logs in with it afterwards.
+func (s Service) AcceptInvitation(ctx context.Context, req *AcceptInvitationRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+	_, err = s.userService.AcceptInvitation(ctx, req.Token, req.Password)
+	return err
+}
+
+func (s Service) isPasswordLoginAllowed(role enforcer.Role) bool {
+	for _, r := range strings.Split(s.config.PasswordLoginDisabledRoles, ",") {
+		if enforcer.Role(strings.TrimSpace(r)) == role {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Service) addToQueue(message *queue.Message) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancelFunc()
+	err := s.queue.Add(ctx, message)
+	if err != nil {
+		log.Logger.Error("add to queue failed", zap.Error(err))
+	}
+}
+
+func (s Service) createSession(ctx context.Context, userResponse *user.UserResponse, client *ClientInfo) (*LoginResponse, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.repository.CreateSession(ctx, &SessionEntity{
+		UserId:           userResponse.Id,
+		RefreshTokenHash: hashToken(secret),
+		UserAgent:        client.UserAgent,
+		IpAddress:        client.IpAddress,
+		ExpiresAt:        time.Now().Add(time.Hour * refreshTokenTtl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.generateAccessToken(session.Id, userResponse)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: formatRefreshToken(session.Id, secret),
+		UserResponse: userResponse,
+	}, nil
+}
+
+func (s Service) generateAccessToken(sessionId string, userResponse *user.UserResponse) (string, error) {
+	claims := accessTokenClaims(sessionId, userResponse)
+	claims["exp"] = time.Now().Add(time.Minute * accessTokenTtl).Unix()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JwtSecret))
+}
+
+func accessTokenClaims(sessionId string, userResponse *user.UserResponse) jwt.MapClaims {
+	claims := jwt.MapClaims{}
 	claims["sub"] = strconv.Itoa(userResponse.Id)
+	claims["sid"] = sessionId
 	claims["name"] = userResponse.Name
 	claims["email"] = userResponse.Email
 	claims["role"] = userResponse.Role
 	if userResponse.FacultyId != nil {
 		claims["facultyId"] = strconv.Itoa(*userResponse.FacultyId)
 	}
-	claims["exp"] = time.Now().Add(time.Hour * accessTokenTtl).Unix()
+	return claims
+}
 
-	accessToken, err := token.SignedString([]byte(s.config.JwtSecret))
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return &LoginResponse{
-		AccessToken:  accessToken,
-		UserResponse: userResponse,
-	}, nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenHashEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+// refresh token format: <session id>.<secret>
+func formatRefreshToken(sessionId string, secret string) string {
+	return sessionId + "." + secret
+}
+
+func parseRefreshToken(token string) (string, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", apperror.New(apperror.ErrUnauthorized, "invalid refresh token", nil)
+	}
+	if _, err := uuid.Parse(parts[0]); err != nil {
+		return "", "", apperror.New(apperror.ErrUnauthorized, "invalid refresh token", err)
+	}
+	return parts[0], parts[1], nil
 }
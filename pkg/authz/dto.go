@@ -1,8 +1,13 @@
 package authz
 
 import (
+	"errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/user"
+	"net"
+	"time"
 )
 
 type LoginRequest struct {
@@ -18,6 +23,270 @@ func (r *LoginRequest) Validate() error {
 }
 
 type LoginResponse struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// ChallengeToken is returned instead of the tokens when the login needs a second factor,
+	// it is exchanged on /auth/login/2fa, or on /auth/2fa/setup and /auth/2fa/activate
+	// when TwoFactorSetupRequired is set.
+	ChallengeToken         string `json:"challengeToken,omitempty"`
+	TwoFactorRequired      bool   `json:"twoFactorRequired,omitempty"`
+	TwoFactorSetupRequired bool   `json:"twoFactorSetupRequired,omitempty"`
 	*user.UserResponse
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (r *RefreshRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.RefreshToken, validation.Required),
+	)
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (r *ForgotPasswordRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Email, validation.Required, is.Email),
+	)
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (r *ResetPasswordRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Token, validation.Required),
+		validation.Field(&r.Password, validation.Required, validation.Length(5, 50)),
+	)
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (r *AcceptInvitationRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Token, validation.Required),
+		validation.Field(&r.Password, validation.Required, validation.Length(5, 50)),
+	)
+}
+
+type OidcAuthorizeResponse struct {
+	Url string `json:"url"`
+}
+
+type OidcCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+func (r *OidcCallbackRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Code, validation.Required),
+		validation.Field(&r.State, validation.Required),
+	)
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	// Code is either the current authenticator code or an unused recovery code
+	Code string `json:"code"`
+}
+
+func (r *TwoFactorLoginRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.ChallengeToken, validation.Required),
+		validation.Field(&r.Code, validation.Required),
+	)
+}
+
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+}
+
+func (r *TwoFactorSetupRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.ChallengeToken, validation.Required),
+	)
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	// ProvisioningUri is the otpauth:// uri to render as QR code
+	ProvisioningUri string `json:"provisioningUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (r *TwoFactorCodeRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Code, validation.Required),
+	)
+}
+
+type TwoFactorActivateResponse struct {
+	// RecoveryCodes are only shown once
+	RecoveryCodes []string `json:"recoveryCodes"`
+	*LoginResponse
+}
+
+// ClientInfo describes the device a session was started from.
+type ClientInfo struct {
+	UserAgent string
+	IpAddress string
+}
+
+type PermissionsQuery struct {
+	// ContributionId adds the permissions on this contribution
+	ContributionId *int `query:"contributionId"`
+	// ContributeSessionId adds the permissions on this contribute session
+	ContributeSessionId *int `query:"contributeSessionId"`
+}
+
+type PermissionsResponse struct {
+	Role enforcer.Role `json:"role"`
+	// Permissions granted by the role, some of them only apply to part of the resources
+	Permissions       []enforcer.Permission `json:"permissions"`
+	Contribution      *ResourcePermissions  `json:"contribution,omitempty"`
+	ContributeSession *ResourcePermissions  `json:"contributeSession,omitempty"`
+}
+
+type ResourcePermissions struct {
+	Id          int                   `json:"id"`
+	Permissions []enforcer.Permission `json:"permissions"`
+}
+
+type RoleMatrixResponse struct {
+	Permissions []enforcer.Permission `json:"permissions"`
+	Roles       []RoleMatrixEntry     `json:"roles"`
+}
+
+type RoleMatrixEntry struct {
+	Name              enforcer.Role                  `json:"name"`
+	Description       string                         `json:"description"`
+	RequiresFaculty   bool                           `json:"requiresFaculty"`
+	RequiresTwoFactor bool                           `json:"requiresTwoFactor"`
+	System            bool                           `json:"system"`
+	Permissions       []enforcer.Permission          `json:"permissions"`
+	Conditions        map[enforcer.Permission]string `json:"conditions"`
+}
+
+type ImpersonateRequest struct {
+	UserId int `json:"userId"`
+	// Reason is kept in the audit log, e.g. the support ticket
+	Reason string `json:"reason"`
+	// AllowWrite lets the administrator change data as the user, deletions stay blocked
+	AllowWrite bool `json:"allowWrite"`
+}
+
+func (r *ImpersonateRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.UserId, validation.Required),
+		validation.Field(&r.Reason, validation.Required, validation.Length(5, 500)),
+	)
+}
+
+type ImpersonateResponse struct {
+	// AccessToken acts as the user until ExpiresAt, it cannot be refreshed
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	AllowWrite  bool      `json:"allowWrite"`
+	*user.UserResponse
+}
+
+type ApiKeyIndexQuery struct {
+	// UserId lists the keys of another user, 0 lists every key, needs api_key.manage
+	UserId *int `query:"userId"`
+}
+
+type ApiKeyCreateRequest struct {
+	Name string `json:"name"`
+	// UserId creates the key for a service account, defaults to the logged in user
+	UserId *int                  `json:"userId"`
+	Scopes []enforcer.Permission `json:"scopes"`
+	// AllowedIps restricts the key to these addresses or cidr ranges, any address when empty
+	AllowedIps []string   `json:"allowedIps" example:"10.0.0.0/8"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
+
+func (r *ApiKeyCreateRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.Required, validation.Length(3, 100)),
+		validation.Field(&r.Scopes, validation.Required, validation.Each(validation.By(validateScope))),
+		validation.Field(&r.AllowedIps, validation.Each(validation.By(validateIpOrCidr))),
+		validation.Field(&r.ExpiresAt, validation.By(validateFutureTime)),
+	)
+}
+
+type ApiKeyUpdateRequest struct {
+	Name       *string    `json:"name"`
+	AllowedIps *[]string  `json:"allowedIps"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
+
+func (r *ApiKeyUpdateRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.NilOrNotEmpty, validation.Length(3, 100)),
+		validation.Field(&r.AllowedIps, validation.Each(validation.By(validateIpOrCidr))),
+		validation.Field(&r.ExpiresAt, validation.By(validateFutureTime)),
+	)
+}
+
+type ApiKeyResponse struct {
+	Id     int    `json:"id"`
+	UserId int    `json:"userId"`
+	Name   string `json:"name"`
+	// Prefix is the start of the key, e.g. mcm_1a2b3c4d5e6f
+	Prefix     string                `json:"prefix"`
+	Scopes     []enforcer.Permission `json:"scopes"`
+	AllowedIps []string              `json:"allowedIps"`
+	ExpiresAt  *time.Time            `json:"expiresAt"`
+	LastUsedAt *time.Time            `json:"lastUsedAt"`
+	LastUsedIp string                `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time            `json:"revokedAt"`
+	CreatedBy  *int                  `json:"createdBy"`
+	CreatedAt  time.Time             `json:"createdAt"`
+}
+
+type ApiKeyCreateResponse struct {
+	// Key is only shown once, send it in the X-API-Key header
+	Key string `json:"key"`
+	*ApiKeyResponse
+}
+
+func validateScope(value interface{}) error {
+	p, _ := value.(enforcer.Permission)
+	if !enforcer.IsPermission(p) {
+		return errors.New("unknown permission " + string(p))
+	}
+	return nil
+}
+
+func validateIpOrCidr(value interface{}) error {
+	s, _ := value.(string)
+	if net.ParseIP(s) != nil {
+		return nil
+	}
+	_, _, err := net.ParseCIDR(s)
+	if err != nil {
+		return errors.New("must be an ip address or cidr range")
+	}
+	return nil
+}
+
+func validateFutureTime(value interface{}) error {
+	t, _ := value.(*time.Time)
+	if t != nil && !t.After(time.Now()) {
+		return errors.New("must be in the future")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package authz
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"mcm-api/pkg/user"
+	"time"
+)
+
+type SessionEntity struct {
+	Id                string `gorm:"default:null"`
+	UserId            int
+	User              user.Entity `gorm:"foreignKey:UserId"`
+	RefreshTokenHash  string
+	PreviousTokenHash string
+	UserAgent         string
+	IpAddress         string
+	ExpiresAt         time.Time
+	RevokedAt         *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (e *SessionEntity) TableName() string {
+	return "auth_sessions"
+}
+
+func (e *SessionEntity) IsActive(now time.Time) bool {
+	return e.RevokedAt == nil && now.Before(e.ExpiresAt)
+}
+
+type PasswordResetTokenEntity struct {
+	Id        int
+	UserId    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (e *PasswordResetTokenEntity) TableName() string {
+	return "password_reset_tokens"
+}
+
+func (e *PasswordResetTokenEntity) IsUsable(now time.Time) bool {
+	return e.UsedAt == nil && now.Before(e.ExpiresAt)
+}
+
+type ApiKeyEntity struct {
+	Id     int
+	UserId int
+	User   user.Entity `gorm:"foreignKey:UserId"`
+	Name   string
+	// Prefix is the public part of the key, it identifies the key in logs and lists
+	Prefix     string
+	SecretHash string
+	Scopes     StringList
+	AllowedIps StringList
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIp string
+	RevokedAt  *time.Time
+	CreatedBy  *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (e *ApiKeyEntity) TableName() string {
+	return "api_keys"
+}
+
+func (e *ApiKeyEntity) IsActive(now time.Time) bool {
+	return e.RevokedAt == nil && (e.ExpiresAt == nil || now.Before(*e.ExpiresAt))
+}
+
+// StringList is stored as a jsonb array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	*l = nil
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("unsupported type of string list")
+	}
+}
@@ -0,0 +1,243 @@
+package authz
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis speaks enough of the redis protocol for the login throttle: strings, sorted
+// sets, expiry and MULTI/EXEC.
+type fakeRedis struct {
+	mu      sync.Mutex
+	strings map[string]string
+	zsets   map[string]map[string]float64
+	expires map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{
+		strings: map[string]string{},
+		zsets:   map[string]map[string]float64{},
+		expires: map[string]time.Time{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = listener.Close()
+	})
+	return client
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	var queued [][]string
+	inTx := false
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		name := strings.ToLower(args[0])
+		var reply string
+		switch {
+		case name == "multi":
+			inTx, queued, reply = true, nil, "+OK\r\n"
+		case name == "exec":
+			replies := make([]string, 0, len(queued))
+			for _, cmd := range queued {
+				replies = append(replies, f.execute(cmd))
+			}
+			inTx, queued = false, nil
+			reply = fmt.Sprintf("*%d\r\n%s", len(replies), strings.Join(replies, ""))
+		case inTx:
+			queued = append(queued, args)
+			reply = "+QUEUED\r\n"
+		default:
+			reply = f.execute(args)
+		}
+		if _, err = io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err = reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) execute(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := ""
+	if len(args) > 1 {
+		key = args[1]
+		if at, ok := f.expires[key]; ok && !time.Now().Before(at) {
+			f.delete(key)
+		}
+	}
+	switch strings.ToLower(args[0]) {
+	case "ping":
+		return "+PONG\r\n"
+	case "set":
+		f.strings[key] = args[2]
+		delete(f.expires, key)
+		if len(args) == 5 {
+			amount, _ := strconv.Atoi(args[4])
+			unit := time.Second
+			if strings.ToLower(args[3]) == "px" {
+				unit = time.Millisecond
+			}
+			f.expires[key] = time.Now().Add(time.Duration(amount) * unit)
+		}
+		return "+OK\r\n"
+	case "del":
+		deleted := 0
+		for _, k := range args[1:] {
+			if f.exists(k) {
+				deleted++
+			}
+			f.delete(k)
+		}
+		return integerReply(deleted)
+	case "ttl":
+		if !f.exists(key) {
+			return integerReply(-2)
+		}
+		at, ok := f.expires[key]
+		if !ok {
+			return integerReply(-1)
+		}
+		return integerReply(int(math.Ceil(time.Until(at).Seconds())))
+	case "expire":
+		if !f.exists(key) {
+			return integerReply(0)
+		}
+		seconds, _ := strconv.Atoi(args[2])
+		f.expires[key] = time.Now().Add(time.Duration(seconds) * time.Second)
+		return integerReply(1)
+	case "zadd":
+		set, ok := f.zsets[key]
+		if !ok {
+			set = map[string]float64{}
+			f.zsets[key] = set
+		}
+		added := 0
+		for i := 2; i+1 < len(args); i += 2 {
+			score, _ := strconv.ParseFloat(args[i], 64)
+			if _, ok := set[args[i+1]]; !ok {
+				added++
+			}
+			set[args[i+1]] = score
+		}
+		return integerReply(added)
+	case "zremrangebyscore":
+		min, _ := strconv.ParseFloat(args[2], 64)
+		max, _ := strconv.ParseFloat(args[3], 64)
+		removed := 0
+		for member, score := range f.zsets[key] {
+			if score >= min && score <= max {
+				delete(f.zsets[key], member)
+				removed++
+			}
+		}
+		return integerReply(removed)
+	case "zcard":
+		return integerReply(len(f.zsets[key]))
+	case "zrange":
+		start, _ := strconv.Atoi(args[2])
+		stop, _ := strconv.Atoi(args[3])
+		members := f.sortedMembers(key)
+		if stop < 0 || stop >= len(members) {
+			stop = len(members) - 1
+		}
+		var items []string
+		for i := start; i <= stop && i < len(members); i++ {
+			items = append(items, members[i])
+			if len(args) > 4 {
+				items = append(items, strconv.FormatFloat(f.zsets[key][members[i]], 'f', -1, 64))
+			}
+		}
+		var reply strings.Builder
+		fmt.Fprintf(&reply, "*%d\r\n", len(items))
+		for _, item := range items {
+			fmt.Fprintf(&reply, "$%d\r\n%s\r\n", len(item), item)
+		}
+		return reply.String()
+	}
+	return "-ERR unknown command " + args[0] + "\r\n"
+}
+
+func (f *fakeRedis) exists(key string) bool {
+	_, isString := f.strings[key]
+	return isString || len(f.zsets[key]) > 0
+}
+
+func (f *fakeRedis) delete(key string) {
+	delete(f.strings, key)
+	delete(f.zsets, key)
+	delete(f.expires, key)
+}
+
+func (f *fakeRedis) sortedMembers(key string) []string {
+	set := f.zsets[key]
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return set[members[i]] < set[members[j]]
+	})
+	return members
+}
+
+func integerReply(v int) string {
+	return ":" + strconv.Itoa(v) + "\r\n"
+}
@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"context"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/audit"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/middleware"
+	"strconv"
+	"time"
+)
+
+// impersonation token ttl, unit: minutes
+const impersonationTokenTtl = 30
+
+// Impersonate mints a short lived access token acting as another user. The token
+// lives on the session of the administrator, so logging out ends it as well.
+func (s Service) Impersonate(ctx context.Context, req *ImpersonateRequest, client *ClientInfo) (*ImpersonateResponse, error) {
+	actor, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if actor.ImpersonatedBy != nil || actor.ApiKeyId != nil {
+		return nil, apperror.New(apperror.ErrForbidden, "impersonation needs an interactive session", nil)
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId == actor.Id {
+		return nil, apperror.New(apperror.ErrInvalid, "cannot impersonate yourself", nil)
+	}
+	target, err := s.userService.FindById(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if enforcer.Can(target.Role, enforcer.ImpersonateUser) {
+		return nil, apperror.New(apperror.ErrForbidden, "users who can impersonate cannot be impersonated", nil)
+	}
+
+	impersonationId := uuid.NewString()
+	expiresAt := time.Now().Add(time.Minute * impersonationTokenTtl)
+	// the token is only handed out once the start is on record
+	err = s.auditService.Record(ctx, &audit.Entity{
+		ActorId:    &actor.Id,
+		Action:     audit.ImpersonationStart,
+		TargetType: "user",
+		TargetId:   strconv.Itoa(target.Id),
+		IpAddress:  client.IpAddress,
+		UserAgent:  client.UserAgent,
+		Data: audit.Data{
+			"impersonationId": impersonationId,
+			"reason":          req.Reason,
+			"allowWrite":      req.AllowWrite,
+			"expiresAt":       expiresAt,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := accessTokenClaims(actor.SessionId, target)
+	claims["act"] = strconv.Itoa(actor.Id)
+	claims["imp"] = impersonationId
+	claims["imp_write"] = req.AllowWrite
+	claims["exp"] = expiresAt.Unix()
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JwtSecret))
+	if err != nil {
+		return nil, err
+	}
+	return &ImpersonateResponse{
+		AccessToken:  accessToken,
+		ExpiresAt:    expiresAt,
+		AllowWrite:   req.AllowWrite,
+		UserResponse: target,
+	}, nil
+}
+
+// RecordImpersonatedRequest is called by the authentication middleware for every
+// request made with an impersonation token.
+func (s Service) RecordImpersonatedRequest(request *middleware.ImpersonatedRequest) {
+	s.auditService.RecordAsync(&audit.Entity{
+		ActorId:    &request.ActorId,
+		Action:     audit.ImpersonatedRequest,
+		TargetType: "user",
+		TargetId:   strconv.Itoa(request.UserId),
+		IpAddress:  request.IpAddress,
+		UserAgent:  request.UserAgent,
+		Data: audit.Data{
+			"impersonationId": request.ImpersonationId,
+			"method":          request.Method,
+			"path":            request.Path,
+			"status":          request.Status,
+		},
+	})
+}
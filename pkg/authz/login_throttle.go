@@ -0,0 +1,121 @@
+package authz
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/audit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sliding window login attempts are counted in, unit: minutes
+	loginWindow = 15
+	// attempts from one ip address per window, successful or not
+	loginMaxAttemptsPerIp = 30
+	// failed attempts on one email per window before it is locked
+	loginMaxFailuresPerEmail = 5
+	// unit: minutes
+	loginLockoutDuration = 15
+)
+
+// checkLoginAllowed counts the attempt against the client ip and rejects it when
+// the ip is throttled or the email is locked. Locks apply to any email so the
+// response does not tell whether an account exists.
+func (s Service) checkLoginAllowed(ctx context.Context, email string, client *ClientInfo) error {
+	ttl, err := s.redis.TTL(ctx, loginLockKey(email)).Result()
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return tooManyLoginAttempts(ttl)
+	}
+	count, oldest, err := s.addToWindow(ctx, loginIpKey(client.IpAddress), time.Now())
+	if err != nil {
+		return err
+	}
+	if count > loginMaxAttemptsPerIp {
+		return tooManyLoginAttempts(oldest.Add(time.Minute * loginWindow).Sub(time.Now()))
+	}
+	return nil
+}
+
+// recordLoginFailure locks the email once it reached the failure limit.
+func (s Service) recordLoginFailure(ctx context.Context, email string, client *ClientInfo) error {
+	count, _, err := s.addToWindow(ctx, loginEmailKey(email), time.Now())
+	if err != nil {
+		return err
+	}
+	if count < loginMaxFailuresPerEmail {
+		return nil
+	}
+	err = s.redis.Set(ctx, loginLockKey(email), 1, time.Minute*loginLockoutDuration).Err()
+	if err != nil {
+		return err
+	}
+	s.redis.Del(ctx, loginEmailKey(email))
+	s.auditService.RecordAsync(&audit.Entity{
+		Action:     audit.LoginLockout,
+		TargetType: "email",
+		TargetId:   email,
+		IpAddress:  client.IpAddress,
+		UserAgent:  client.UserAgent,
+		Data: audit.Data{
+			"failures":        count,
+			"lockoutDuration": loginLockoutDuration,
+		},
+	})
+	return nil
+}
+
+func (s Service) resetLoginFailures(ctx context.Context, email string) {
+	s.redis.Del(ctx, loginEmailKey(email))
+}
+
+// addToWindow adds an event to a sorted set based sliding window and returns
+// the number of events in the window and the time of the oldest one.
+func (s Service) addToWindow(ctx context.Context, key string, now time.Time) (int64, time.Time, error) {
+	member, err := generateSecret()
+	if err != nil {
+		return 0, now, err
+	}
+	windowStart := now.Add(-time.Minute * loginWindow)
+	var card *redis.IntCmd
+	var oldest *redis.ZSliceCmd
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(windowStart.UnixNano(), 10))
+		pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.UnixNano()), Member: member})
+		pipe.Expire(ctx, key, time.Minute*loginWindow)
+		card = pipe.ZCard(ctx, key)
+		oldest = pipe.ZRangeWithScores(ctx, key, 0, 0)
+		return nil
+	})
+	if err != nil {
+		return 0, now, err
+	}
+	oldestTime := now
+	if len(oldest.Val()) > 0 {
+		oldestTime = time.Unix(0, int64(oldest.Val()[0].Score))
+	}
+	return card.Val(), oldestTime, nil
+}
+
+func tooManyLoginAttempts(retryAfter time.Duration) error {
+	seconds := int(retryAfter.Seconds()) + 1
+	return apperror.New(apperror.ErrTooMany, "Too many login attempts, please try again later", nil).
+		WithData(map[string]int{"retryAfter": seconds})
+}
+
+func loginIpKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func loginEmailKey(email string) string {
+	return "login:email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func loginLockKey(email string) string {
+	return "login:lock:" + strings.ToLower(strings.TrimSpace(email))
+}
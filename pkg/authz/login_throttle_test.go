@@ -0,0 +1,161 @@
+package authz
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/audit"
+	"mcm-api/pkg/middleware"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newThrottleService(t *testing.T) *Service {
+	// audit entries of lockouts are only rendered, never sent
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Service{
+		redis:        newFakeRedis(t),
+		auditService: audit.InitializeService(audit.InitializeRepository(db)),
+	}
+}
+
+// retryAfterOf reads the seconds a too many attempts error asks the client to wait.
+func retryAfterOf(t *testing.T, err error) int {
+	if !apperror.Is(err, apperror.ErrTooMany) {
+		t.Fatalf("expected too many attempts, got %v", err)
+	}
+	data := reflect.ValueOf(err).Elem().FieldByName("Data").Interface()
+	return data.(map[string]int)["retryAfter"]
+}
+
+func TestAddToWindowSlides(t *testing.T) {
+	s := newThrottleService(t)
+	ctx := context.Background()
+	// scores are float64, whole seconds survive the round trip
+	start := time.Now().Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		count, oldest, err := s.addToWindow(ctx, "window", start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != int64(i+1) || !oldest.Equal(start) {
+			t.Errorf("attempt %v: got count %v oldest %v, want %v %v", i, count, oldest, i+1, start)
+		}
+	}
+	// the first attempt left the window, the second one is the oldest now
+	count, oldest, err := s.addToWindow(ctx, "window", start.Add(time.Minute*loginWindow+time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || !oldest.Equal(start.Add(time.Minute)) {
+		t.Errorf("got count %v oldest %v, want 3 %v", count, oldest, start.Add(time.Minute))
+	}
+}
+
+func TestCheckLoginAllowedThrottlesIp(t *testing.T) {
+	s := newThrottleService(t)
+	ctx := context.Background()
+	client := &ClientInfo{IpAddress: "198.51.100.1"}
+	for i := 0; i < loginMaxAttemptsPerIp; i++ {
+		if err := s.checkLoginAllowed(ctx, "student@example.edu", client); err != nil {
+			t.Fatalf("attempt %v: %v", i, err)
+		}
+	}
+	retryAfter := retryAfterOf(t, s.checkLoginAllowed(ctx, "other@example.edu", client))
+	if retryAfter < loginWindow*60-5 || retryAfter > loginWindow*60+1 {
+		t.Errorf("expected to retry after the window, got %v seconds", retryAfter)
+	}
+	if err := s.checkLoginAllowed(ctx, "student@example.edu", &ClientInfo{IpAddress: "198.51.100.2"}); err != nil {
+		t.Errorf("expected another ip to be allowed, got %v", err)
+	}
+}
+
+func TestRecordLoginFailureLocksEmail(t *testing.T) {
+	s := newThrottleService(t)
+	ctx := context.Background()
+	client := &ClientInfo{IpAddress: "198.51.100.1"}
+	for i := 0; i < loginMaxFailuresPerEmail; i++ {
+		if err := s.checkLoginAllowed(ctx, "Student@Example.edu ", client); err != nil {
+			t.Fatalf("attempt %v: %v", i, err)
+		}
+		if err := s.recordLoginFailure(ctx, "student@example.edu", client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the lock applies from any ip and ignores the case of the email
+	retryAfter := retryAfterOf(t, s.checkLoginAllowed(ctx, "STUDENT@example.edu", &ClientInfo{IpAddress: "198.51.100.2"}))
+	if retryAfter < loginLockoutDuration*60-5 || retryAfter > loginLockoutDuration*60+1 {
+		t.Errorf("expected to retry after the lockout, got %v seconds", retryAfter)
+	}
+	if err := s.checkLoginAllowed(ctx, "other@example.edu", client); err != nil {
+		t.Errorf("expected another email to be allowed, got %v", err)
+	}
+}
+
+func TestResetLoginFailures(t *testing.T) {
+	s := newThrottleService(t)
+	ctx := context.Background()
+	client := &ClientInfo{IpAddress: "198.51.100.1"}
+	for i := 0; i < loginMaxFailuresPerEmail-1; i++ {
+		if err := s.recordLoginFailure(ctx, "student@example.edu", client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a successful login forgets the failures before it
+	s.resetLoginFailures(ctx, "student@example.edu")
+	if err := s.recordLoginFailure(ctx, "student@example.edu", client); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkLoginAllowed(ctx, "student@example.edu", client); err != nil {
+		t.Errorf("expected the email not to be locked, got %v", err)
+	}
+}
+
+func TestLoginIpKeyIgnoresSpoofedForwardedFor(t *testing.T) {
+	cases := []struct {
+		name           string
+		trustedProxies string
+		remoteAddr     string
+		expected       string
+	}{
+		{"direct", "", "198.51.100.1:4000", loginIpKey("198.51.100.1")},
+		{"behind a trusted proxy", "10.0.0.0/8", "10.1.2.3:4000", loginIpKey("198.51.100.1")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ipExtractor, err := middleware.IpExtractor(c.trustedProxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := echo.New()
+			e.IPExtractor = ipExtractor
+			e.POST("/auth/login", func(ctx echo.Context) error {
+				return ctx.String(http.StatusOK, loginIpKey(clientInfo(ctx).IpAddress))
+			})
+			// every attempt claims to come from another client
+			for _, spoofed := range []string{"203.0.113.1", "203.0.113.2", "192.0.2.1, 203.0.113.3"} {
+				req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+				req.RemoteAddr = c.remoteAddr
+				forwardedFor := spoofed
+				if c.trustedProxies != "" {
+					// the proxy appends the peer it saw
+					forwardedFor += ", 198.51.100.1"
+				}
+				req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Body.String() != c.expected {
+					t.Errorf("X-Forwarded-For %q: expected the attempt to count against %s, got %s", spoofed, c.expected, rec.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package authz
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"mcm-api/config"
+	"mcm-api/pkg/enforcer"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oidcStateTtl is how long the user has to finish the login at the identity provider, unit: minutes
+const oidcStateTtl = 10
+
+var (
+	errOidcUnknownKey   = errors.New("oidc: unknown signing key")
+	errOidcInvalidToken = errors.New("oidc: invalid id token")
+)
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcJwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// oidcState is kept server side between the authorize redirect and the callback.
+type oidcState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+type oidcClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// oidcClient implements the relying party side of the authorization code flow
+// with PKCE. Discovery document and signing keys are cached for the process lifetime,
+// keys are refetched when a token is signed with an unknown key id.
+type oidcClient struct {
+	config     *config.Config
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+func newOidcClient(config *config.Config) *oidcClient {
+	return &oidcClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+func (c *oidcClient) Enabled() bool {
+	return c.config.OidcIssuer != "" && c.config.OidcClientId != ""
+}
+
+func (c *oidcClient) AuthorizationUrl(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.config.OidcClientId)
+	query.Set("redirect_uri", c.config.OidcRedirectUrl)
+	query.Set("scope", "openid email profile")
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", pkceChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the raw id token.
+func (c *oidcClient) Exchange(ctx context.Context, code string, verifier string) (string, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.config.OidcRedirectUrl)
+	form.Set("client_id", c.config.OidcClientId)
+	form.Set("code_verifier", verifier)
+	if c.config.OidcClientSecret != "" {
+		form.Set("client_secret", c.config.OidcClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	tokenResponse := new(oidcTokenResponse)
+	err = json.NewDecoder(res.Body).Decode(tokenResponse)
+	if err != nil {
+		return "", fmt.Errorf("oidc: decode token response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || tokenResponse.Error != "" {
+		return "", fmt.Errorf("oidc: token exchange failed: %s %s", tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if tokenResponse.IdToken == "" {
+		return "", errors.New("oidc: token response has no id_token")
+	}
+	return tokenResponse.IdToken, nil
+}
+
+// VerifyIdToken checks signature, issuer, audience, expiry and nonce of the id token.
+func (c *oidcClient) VerifyIdToken(ctx context.Context, rawToken string, nonce string) (*oidcClaims, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Parse(rawToken, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("oidc: unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return c.getKey(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errOidcInvalidToken
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errOidcInvalidToken
+	}
+	if iss, _ := claims["iss"].(string); iss != discovery.Issuer {
+		return nil, fmt.Errorf("oidc: unexpected issuer %q", iss)
+	}
+	if !hasAudience(claims["aud"], c.config.OidcClientId) {
+		return nil, errors.New("oidc: id token is not issued for this client")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); !tokenHashEqual(tokenNonce, nonce) {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+
+	result := &oidcClaims{}
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	// an address is only verified when the provider says so
+	result.EmailVerified, _ = claims["email_verified"].(bool)
+	groupsClaim := c.config.OidcGroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	switch groups := claims[groupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if g, ok := group.(string); ok {
+				result.Groups = append(result.Groups, g)
+			}
+		}
+	case string:
+		result.Groups = strings.Fields(groups)
+	}
+	return result, nil
+}
+
+func (c *oidcClient) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+	discovery := new(oidcDiscovery)
+	err := c.getJson(ctx, strings.TrimSuffix(c.config.OidcIssuer, "/")+"/.well-known/openid-configuration", discovery)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != c.config.OidcIssuer {
+		return nil, fmt.Errorf("oidc: discovery issuer %q does not match %q", discovery.Issuer, c.config.OidcIssuer)
+	}
+	c.discovery = discovery
+	return discovery, nil
+}
+
+func (c *oidcClient) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	discovery, err := c.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	jwks := new(oidcJwks)
+	err = c.getJson(ctx, discovery.JwksUri, jwks)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		key, err := parseRsaPublicKey(k.N, k.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = key
+	}
+	c.keys = keys
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errOidcUnknownKey
+}
+
+func (c *oidcClient) getJson(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s returned %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// mapOidcGroups resolves the role and faculty name of a user from the provider groups.
+// The first group found in OidcRoleMapping wins, so list the most privileged groups first.
+func mapOidcGroups(cfg *config.Config, groups []string) (enforcer.Role, string) {
+	memberOf := make(map[string]bool)
+	var facultyName string
+	for _, group := range groups {
+		memberOf[group] = true
+		if cfg.OidcFacultyGroupPrefix != "" && facultyName == "" && strings.HasPrefix(group, cfg.OidcFacultyGroupPrefix) {
+			facultyName = strings.TrimPrefix(group, cfg.OidcFacultyGroupPrefix)
+		}
+	}
+	var role enforcer.Role
+	for _, pair := range strings.Split(cfg.OidcRoleMapping, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		mapped := enforcer.Role(strings.TrimSpace(parts[1]))
+		// a mapping to a role that does not exist is ignored rather than provisioned
+		if !enforcer.RoleExists(mapped) {
+			continue
+		}
+		if memberOf[strings.TrimSpace(parts[0])] {
+			role = mapped
+			break
+		}
+	}
+	return role, facultyName
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func hasAudience(aud interface{}, clientId string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientId
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseRsaPublicKey(n string, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
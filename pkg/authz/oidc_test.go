@@ -0,0 +1,272 @@
+package authz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"mcm-api/config"
+	"mcm-api/pkg/enforcer"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// mockIdp is a minimal OpenID provider: discovery, jwks and a token endpoint
+// that checks the PKCE verifier against the challenge sent to the authorize url.
+type mockIdp struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockIdp(t *testing.T) *mockIdp {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdp{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("code") != "valid-code" || pkceChallenge(r.PostForm.Get("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.claims)
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	return idp
+}
+
+func (idp *mockIdp) config() *config.Config {
+	return &config.Config{
+		OidcIssuer:             idp.server.URL,
+		OidcClientId:           "mcm-web",
+		OidcRedirectUrl:        "http://localhost:4200/sso/callback",
+		OidcGroupsClaim:        "groups",
+		OidcRoleMapping:        "mcm-admins=admin,students=student",
+		OidcFacultyGroupPrefix: "faculty:",
+	}
+}
+
+// authorize simulates the browser leg: it records the PKCE challenge and returns the nonce.
+func (idp *mockIdp) authorize(t *testing.T, client *oidcClient, verifier string) string {
+	nonce := "test-nonce"
+	authorizationUrl, err := client.AuthorizationUrl(context.Background(), "test-state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "mcm-web" || query.Get("state") != "test-state" {
+		t.Fatalf("unexpected authorization url %s", authorizationUrl)
+	}
+	idp.challenge = query.Get("code_challenge")
+	return nonce
+}
+
+func (idp *mockIdp) validClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            "mcm-web",
+		"sub":            "student-1",
+		"email":          "student@example.edu",
+		"email_verified": true,
+		"name":           "Student One",
+		"groups":         []string{"students", "faculty:Computing"},
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+}
+
+func TestOidcClient_Login(t *testing.T) {
+	idp := newMockIdp(t)
+	defer idp.server.Close()
+	cfg := idp.config()
+	client := newOidcClient(cfg)
+	nonce := idp.authorize(t, client, "test-verifier")
+	idp.claims = idp.validClaims(nonce)
+
+	idToken, err := client.Exchange(context.Background(), "valid-code", "test-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := client.VerifyIdToken(context.Background(), idToken, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Email != "student@example.edu" || claims.Name != "Student One" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	role, facultyName := mapOidcGroups(cfg, claims.Groups)
+	if role != enforcer.Student || facultyName != "Computing" {
+		t.Errorf("got role %q faculty %q, want student Computing", role, facultyName)
+	}
+}
+
+func TestOidcClient_VerifyIdTokenEmailVerified(t *testing.T) {
+	idp := newMockIdp(t)
+	defer idp.server.Close()
+	client := newOidcClient(idp.config())
+	nonce := idp.authorize(t, client, "test-verifier")
+
+	cases := []struct {
+		name     string
+		verified interface{}
+		expected bool
+	}{
+		{"verified", true, true},
+		{"not verified", false, false},
+		{"missing", nil, false},
+		{"not a boolean", "true", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			idp.claims = idp.validClaims(nonce)
+			if c.verified == nil {
+				delete(idp.claims, "email_verified")
+			} else {
+				idp.claims["email_verified"] = c.verified
+			}
+			idToken, err := client.Exchange(context.Background(), "valid-code", "test-verifier")
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := client.VerifyIdToken(context.Background(), idToken, nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.EmailVerified != c.expected {
+				t.Errorf("expected email verified %v, got %v", c.expected, claims.EmailVerified)
+			}
+		})
+	}
+}
+
+func TestOidcClient_ExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdp(t)
+	defer idp.server.Close()
+	client := newOidcClient(idp.config())
+	nonce := idp.authorize(t, client, "test-verifier")
+	idp.claims = idp.validClaims(nonce)
+
+	_, err := client.Exchange(context.Background(), "valid-code", "another-verifier")
+	if err == nil {
+		t.Error("expected exchange with wrong verifier to fail")
+	}
+}
+
+func TestOidcClient_VerifyIdTokenRejectsInvalidTokens(t *testing.T) {
+	idp := newMockIdp(t)
+	defer idp.server.Close()
+	client := newOidcClient(idp.config())
+	nonce := idp.authorize(t, client, "test-verifier")
+
+	cases := []struct {
+		name   string
+		mutate func(claims jwt.MapClaims)
+		nonce  string
+	}{
+		{"wrong nonce", func(claims jwt.MapClaims) {}, "other-nonce"},
+		{"wrong audience", func(claims jwt.MapClaims) { claims["aud"] = "other-client" }, nonce},
+		{"wrong issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example" }, nonce},
+		{"expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() }, nonce},
+		{"missing expiry", func(claims jwt.MapClaims) { delete(claims, "exp") }, nonce},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			idp.claims = idp.validClaims(nonce)
+			c.mutate(idp.claims)
+			idToken, err := client.Exchange(context.Background(), "valid-code", "test-verifier")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.VerifyIdToken(context.Background(), idToken, c.nonce)
+			if err == nil {
+				t.Error("expected verification to fail")
+			}
+		})
+	}
+}
+
+func TestOidcClient_VerifyIdTokenRejectsForeignKey(t *testing.T) {
+	idp := newMockIdp(t)
+	defer idp.server.Close()
+	client := newOidcClient(idp.config())
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.validClaims("test-nonce"))
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.VerifyIdToken(context.Background(), signed, "test-nonce")
+	if err == nil {
+		t.Error("expected token signed by an unknown key to fail")
+	}
+}
+
+func TestMapOidcGroups(t *testing.T) {
+	cfg := &config.Config{
+		OidcRoleMapping:        "superusers=root,mcm-admins=admin, managers=marketing_manager,students=student",
+		OidcFacultyGroupPrefix: "faculty:",
+	}
+	cases := []struct {
+		groups      []string
+		role        enforcer.Role
+		facultyName string
+	}{
+		{[]string{"students", "faculty:Business"}, enforcer.Student, "Business"},
+		{[]string{"students", "mcm-admins"}, enforcer.Administrator, ""},
+		{[]string{"managers"}, enforcer.MarketingManager, ""},
+		{[]string{"staff"}, "", ""},
+		{[]string{"superusers"}, "", ""},
+		{[]string{"superusers", "students"}, enforcer.Student, ""},
+		{nil, "", ""},
+	}
+	for _, c := range cases {
+		role, facultyName := mapOidcGroups(cfg, c.groups)
+		if role != c.role || facultyName != c.facultyName {
+			t.Errorf("groups %v: got %q %q, want %q %q", c.groups, role, facultyName, c.role, c.facultyName)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package authz
+
+import (
+	"context"
+	"mcm-api/pkg/contributesession"
+	"mcm-api/pkg/contribution"
+	"mcm-api/pkg/enforcer"
+)
+
+// PermissionService exposes the enforcer matrix to the web app, so the ui never
+// keeps its own copy of it.
+type PermissionService struct {
+	contributionService      *contribution.Service
+	contributeSessionService *contributesession.Service
+}
+
+func InitializePermissionService(
+	contributionService *contribution.Service,
+	contributeSessionService *contributesession.Service,
+) *PermissionService {
+	return &PermissionService{
+		contributionService:      contributionService,
+		contributeSessionService: contributeSessionService,
+	}
+}
+
+func (s PermissionService) FindPermissions(ctx context.Context, query *PermissionsQuery) (*PermissionsResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &PermissionsResponse{
+		Role:        loggedInUser.Role,
+		Permissions: enforcer.Permissions(*loggedInUser),
+	}
+	if query.ContributionId != nil {
+		c, err := s.contributionService.FindById(ctx, *query.ContributionId)
+		if err != nil {
+			return nil, err
+		}
+		res.Contribution = &ResourcePermissions{
+			Id:          c.Id,
+			Permissions: enforcer.ContributionPermissions(*loggedInUser, c),
+		}
+	}
+	if query.ContributeSessionId != nil {
+		session, err := s.contributeSessionService.FindById(ctx, *query.ContributeSessionId)
+		if err != nil {
+			return nil, err
+		}
+		res.ContributeSession = &ResourcePermissions{
+			Id:          session.Id,
+			Permissions: enforcer.ContributeSessionPermissions(*loggedInUser, session),
+		}
+	}
+	return res, nil
+}
+
+func (s PermissionService) RoleMatrix() *RoleMatrixResponse {
+	roles := enforcer.Roles()
+	res := &RoleMatrixResponse{
+		Permissions: enforcer.AllPermissions(),
+		Roles:       make([]RoleMatrixEntry, len(roles)),
+	}
+	for i, role := range roles {
+		conditions := make(map[enforcer.Permission]string)
+		for p, condition := range role.Conditions {
+			if condition != "" {
+				conditions[p] = condition
+			}
+		}
+		res.Roles[i] = RoleMatrixEntry{
+			Name:              role.Name,
+			Description:       role.Description,
+			RequiresFaculty:   role.RequiresFaculty,
+			RequiresTwoFactor: role.RequiresTwoFactor,
+			System:            role.System,
+			Permissions:       role.Permissions,
+			Conditions:        conditions,
+		}
+	}
+	return res
+}
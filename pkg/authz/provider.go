@@ -1,5 +1,15 @@
 package authz
 
-import "github.com/google/wire"
+import (
+	"github.com/google/wire"
+	"mcm-api/pkg/middleware"
+)
 
-var Set = wire.NewSet(InitializeAuthService)
+var Set = wire.NewSet(
+	InitializeRepository,
+	InitializeAuthService,
+	InitializePermissionService,
+	wire.Bind(new(middleware.SessionValidator), new(*Service)),
+	wire.Bind(new(middleware.ImpersonationAuditor), new(*Service)),
+	wire.Bind(new(middleware.ApiKeyAuthenticator), new(*Service)),
+)
@@ -0,0 +1,163 @@
+package authz
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"mcm-api/pkg/user"
+	"time"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func InitializeRepository(db *gorm.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r repository) CreateSession(ctx context.Context, entity *SessionEntity) (*SessionEntity, error) {
+	db := r.db.WithContext(ctx).Omit("User").Create(entity)
+	return entity, db.Error
+}
+
+func (r repository) FindSessionById(ctx context.Context, id string) (*SessionEntity, error) {
+	result := new(SessionEntity)
+	db := r.db.WithContext(ctx).Joins("User").First(result, "auth_sessions.id = ?", id)
+	return result, db.Error
+}
+
+// RotateSession replaces the refresh token of the session when the presented one is still
+// its current token and the session was not revoked in between. Of concurrent refreshes
+// with the same token only one rotates, it returns false for the others.
+func (r repository) RotateSession(ctx context.Context, entity *SessionEntity, presentedHash string) (bool, error) {
+	db := r.db.WithContext(ctx).Model(&SessionEntity{}).
+		Where("id = ? and refresh_token_hash = ? and revoked_at is null and expires_at > ?",
+			entity.Id, presentedHash, time.Now()).
+		Updates(map[string]interface{}{
+			"previous_token_hash": presentedHash,
+			"refresh_token_hash":  entity.RefreshTokenHash,
+			"expires_at":          entity.ExpiresAt,
+			"user_agent":          entity.UserAgent,
+			"ip_address":          entity.IpAddress,
+			"updated_at":          time.Now(),
+		})
+	return db.RowsAffected > 0, db.Error
+}
+
+func (r repository) RevokeSession(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&SessionEntity{}).
+		Where("id = ? and revoked_at is null", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r repository) RevokeAllSessionsOfUser(ctx context.Context, userId int, except ...string) error {
+	builder := r.db.WithContext(ctx).Model(&SessionEntity{}).
+		Where("user_id = ? and revoked_at is null", userId)
+	if len(except) > 0 {
+		builder.Where("id not in ?", except)
+	}
+	return builder.Update("revoked_at", time.Now()).Error
+}
+
+func (r repository) CreatePasswordResetToken(ctx context.Context, entity *PasswordResetTokenEntity) (*PasswordResetTokenEntity, error) {
+	db := r.db.WithContext(ctx).Create(entity)
+	return entity, db.Error
+}
+
+func (r repository) FindPasswordResetTokenByHash(ctx context.Context, hash string) (*PasswordResetTokenEntity, error) {
+	result := new(PasswordResetTokenEntity)
+	db := r.db.WithContext(ctx).First(result, "token_hash = ?", hash)
+	return result, db.Error
+}
+
+// InvalidatePasswordResetTokens marks every unused token of the user as used,
+// so only the most recently issued link works.
+func (r repository) InvalidatePasswordResetTokens(ctx context.Context, userId int) error {
+	return r.db.WithContext(ctx).Model(&PasswordResetTokenEntity{}).
+		Where("user_id = ? and used_at is null", userId).
+		Update("used_at", time.Now()).Error
+}
+
+// ResetPassword redeems a reset token and sets the new password in a single transaction.
+// The token is consumed with a conditional update, so of concurrent requests with the
+// same token only one succeeds, it returns false when the token was already used or
+// has expired. Every session of the user is revoked along with it.
+func (r repository) ResetPassword(ctx context.Context, token *PasswordResetTokenEntity, hashedPassword string) (bool, error) {
+	consumed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		db := tx.Model(&PasswordResetTokenEntity{}).
+			Where("id = ? and used_at is null and expires_at > ?", token.Id, now).
+			Update("used_at", now)
+		if db.Error != nil || db.RowsAffected == 0 {
+			return db.Error
+		}
+		consumed = true
+		err := tx.Model(&PasswordResetTokenEntity{}).
+			Where("user_id = ? and used_at is null", token.UserId).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+		db = tx.Model(&user.Entity{}).
+			Where("id = ?", token.UserId).
+			Update("password", hashedPassword)
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&SessionEntity{}).
+			Where("user_id = ? and revoked_at is null", token.UserId).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return consumed, nil
+}
+
+func (r repository) CreateApiKey(ctx context.Context, entity *ApiKeyEntity) (*ApiKeyEntity, error) {
+	db := r.db.WithContext(ctx).Omit("User").Create(entity)
+	return entity, db.Error
+}
+
+func (r repository) FindApiKeyById(ctx context.Context, id int) (*ApiKeyEntity, error) {
+	result := new(ApiKeyEntity)
+	db := r.db.WithContext(ctx).Joins("User").First(result, "api_keys.id = ?", id)
+	return result, db.Error
+}
+
+func (r repository) FindApiKeyByPrefix(ctx context.Context, prefix string) (*ApiKeyEntity, error) {
+	result := new(ApiKeyEntity)
+	db := r.db.WithContext(ctx).Joins("User").First(result, "api_keys.prefix = ?", prefix)
+	return result, db.Error
+}
+
+// FindApiKeys lists the keys of a user, or of every user when userId is nil.
+func (r repository) FindApiKeys(ctx context.Context, userId *int) ([]*ApiKeyEntity, error) {
+	var entities []*ApiKeyEntity
+	builder := r.db.WithContext(ctx).Joins("User").Order("api_keys.created_at desc")
+	if userId != nil {
+		builder.Where("api_keys.user_id = ?", *userId)
+	}
+	result := builder.Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) UpdateApiKey(ctx context.Context, entity *ApiKeyEntity) (*ApiKeyEntity, error) {
+	db := r.db.WithContext(ctx).Omit("User").Save(entity)
+	return entity, db.Error
+}
+
+// TouchApiKey records the last use, at most once per interval so busy keys do not
+// write on every request.
+func (r repository) TouchApiKey(ctx context.Context, id int, ip string, interval time.Duration) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&ApiKeyEntity{}).
+		Where("id = ? and (last_used_at is null or last_used_at < ?)", id, now.Add(-interval)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
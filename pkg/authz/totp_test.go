@@ -0,0 +1,91 @@
+package authz
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// the shared secret of the SHA1 test vectors of RFC 6238 appendix B
+var rfc6238Key = []byte("12345678901234567890")
+
+func TestTotpCode(t *testing.T) {
+	// the RFC lists 8 digit codes, the last 6 of them are the 6 digit ones
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, c := range cases {
+		actual := totpCode(rfc6238Key, c.unix/totpPeriod)
+		if actual != c.expected[2:] {
+			t.Errorf("time %v: expected %v, got %v", c.unix, c.expected[2:], actual)
+		}
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	secret := base32NoPadding.EncodeToString(rfc6238Key)
+	issuedAt := time.Unix(1111111111, 0)
+	cases := []struct {
+		name   string
+		secret string
+		code   string
+		now    time.Time
+		ok     bool
+	}{
+		{"current period", secret, "050471", issuedAt, true},
+		{"lower case secret", strings.ToLower(secret), "050471", issuedAt, true},
+		{"one period late", secret, "050471", issuedAt.Add(time.Second * totpPeriod), true},
+		{"one period early", secret, "050471", issuedAt.Add(-time.Second * totpPeriod), true},
+		{"two periods late", secret, "050471", issuedAt.Add(2 * time.Second * totpPeriod), false},
+		{"two periods early", secret, "050471", issuedAt.Add(-2 * time.Second * totpPeriod), false},
+		{"wrong code", secret, "050472", issuedAt, false},
+		{"too short", secret, "05047", issuedAt, false},
+		{"too long", secret, "0504710", issuedAt, false},
+		{"rfc code with 8 digits", secret, "14050471", issuedAt, false},
+		{"invalid secret", "not base32!", "050471", issuedAt, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			step, ok := validateTotp(c.secret, c.code, c.now)
+			if ok != c.ok {
+				t.Fatalf("expected %v, got %v", c.ok, ok)
+			}
+			// the matched step is the one the code was issued in, not the current one
+			if ok && step != issuedAt.Unix()/totpPeriod {
+				t.Errorf("expected step %v, got %v", issuedAt.Unix()/totpPeriod, step)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("expected %v codes, got %v", recoveryCodeCount, len(codes))
+	}
+	for i, code := range codes {
+		// users retype codes without the dash, in upper case or with spaces around
+		typed := []string{
+			code,
+			strings.ToUpper(code),
+			strings.Replace(code, "-", "", 1),
+			" " + code[:4] + " " + code[5:] + " ",
+		}
+		for _, v := range typed {
+			if normalized := normalizeRecoveryCode(v); normalized != code || hashToken(normalized) != hashes[i] {
+				t.Errorf("code %q typed as %q: normalized to %q", code, v, normalized)
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package authz
+
+import (
+	"context"
+	"github.com/dgrijalva/jwt-go"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/user"
+	"strconv"
+	"time"
+)
+
+const (
+	// challenge token ttl, unit: minutes
+	challengeTokenTtl = 5
+	// wrong codes accepted per challenge token before the user has to log in again
+	challengeMaxAttempts = 5
+
+	challengeTokenType = "2fa_challenge"
+)
+
+// completeLogin issues the session tokens, or a challenge when the user has to
+// present a second factor first.
+func (s Service) completeLogin(ctx context.Context, userResponse *user.UserResponse, client *ClientInfo) (*LoginResponse, error) {
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userResponse.Id)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.EnabledAt == nil && !enforcer.RequiresTwoFactor(userResponse.Role) {
+		return s.createSession(ctx, userResponse, client)
+	}
+	setup := twoFactor.EnabledAt == nil
+	challengeToken, err := s.generateChallengeToken(userResponse.Id, setup)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		ChallengeToken:         challengeToken,
+		TwoFactorRequired:      !setup,
+		TwoFactorSetupRequired: setup,
+	}, nil
+}
+
+func (s Service) LoginTwoFactor(ctx context.Context, req *TwoFactorLoginRequest, client *ClientInfo) (*LoginResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	userId, setup, jti, err := s.parseChallengeToken(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if setup {
+		return nil, apperror.New(apperror.ErrForbidden, "two-factor authentication has to be set up first", nil)
+	}
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	err = s.verifySecondFactor(ctx, userId, twoFactor, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	s.consumeChallenge(ctx, jti)
+	userResponse, err := s.userService.FindById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if userResponse.Status == user.UserDisable {
+		return nil, apperror.New(apperror.ErrForbidden, "Your account is disabled", nil)
+	}
+	return s.createSession(ctx, userResponse, client)
+}
+
+// SetupTwoFactorWithChallenge lets a user whose role requires two-factor authentication
+// enrol during login, before they hold an access token.
+func (s Service) SetupTwoFactorWithChallenge(ctx context.Context, req *TwoFactorSetupRequest) (*TwoFactorSetupResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	userId, setup, _, err := s.parseChallengeToken(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !setup {
+		return nil, apperror.New(apperror.ErrConflict, "two-factor authentication is already enabled", nil)
+	}
+	return s.SetupTwoFactor(ctx, userId)
+}
+
+func (s Service) ActivateTwoFactorWithChallenge(ctx context.Context, req *TwoFactorLoginRequest, client *ClientInfo) (*TwoFactorActivateResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	userId, setup, jti, err := s.parseChallengeToken(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !setup {
+		return nil, apperror.New(apperror.ErrConflict, "two-factor authentication is already enabled", nil)
+	}
+	recoveryCodes, err := s.ActivateTwoFactor(ctx, userId, &TwoFactorCodeRequest{Code: req.Code})
+	if err != nil {
+		return nil, err
+	}
+	s.consumeChallenge(ctx, jti)
+	userResponse, err := s.userService.FindById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	loginResponse, err := s.createSession(ctx, userResponse, client)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorActivateResponse{
+		RecoveryCodes: recoveryCodes,
+		LoginResponse: loginResponse,
+	}, nil
+}
+
+// SetupTwoFactor generates a new authenticator secret, it is only used after
+// ActivateTwoFactor confirmed the user scanned it.
+func (s Service) SetupTwoFactor(ctx context.Context, userId int) (*TwoFactorSetupResponse, error) {
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "two-factor authentication is already enabled", nil)
+	}
+	userResponse, err := s.userService.FindById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.userService.UpdateTwoFactor(ctx, userId, &user.TwoFactor{Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningUri: totpProvisioningUri(secret, userResponse.Email),
+	}, nil
+}
+
+func (s Service) ActivateTwoFactor(ctx context.Context, userId int, req *TwoFactorCodeRequest) ([]string, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "two-factor authentication is already enabled", nil)
+	}
+	if twoFactor.Secret == "" {
+		return nil, apperror.New(apperror.ErrInvalid, "two-factor authentication has not been set up", nil)
+	}
+	if _, ok := validateTotp(twoFactor.Secret, req.Code, time.Now()); !ok {
+		return nil, apperror.New(apperror.ErrInvalid, "invalid code", nil)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	twoFactor.EnabledAt = &now
+	twoFactor.RecoveryCodes = hashes
+	err = s.userService.UpdateTwoFactor(ctx, userId, twoFactor)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s Service) DisableTwoFactor(ctx context.Context, userId int, role enforcer.Role, req *TwoFactorCodeRequest) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+	if enforcer.RequiresTwoFactor(role) {
+		return apperror.New(apperror.ErrForbidden, "two-factor authentication is mandatory for your role", nil)
+	}
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if twoFactor.EnabledAt == nil {
+		return apperror.New(apperror.ErrInvalid, "two-factor authentication is not enabled", nil)
+	}
+	err = s.verifySecondFactor(ctx, userId, twoFactor, req.Code)
+	if err != nil {
+		return err
+	}
+	return s.userService.UpdateTwoFactor(ctx, userId, &user.TwoFactor{})
+}
+
+func (s Service) RegenerateRecoveryCodes(ctx context.Context, userId int, req *TwoFactorCodeRequest) ([]string, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := s.userService.GetTwoFactor(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.EnabledAt == nil {
+		return nil, apperror.New(apperror.ErrInvalid, "two-factor authentication is not enabled", nil)
+	}
+	if _, ok := validateTotp(twoFactor.Secret, req.Code, time.Now()); !ok {
+		return nil, apperror.New(apperror.ErrInvalid, "invalid code", nil)
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.RecoveryCodes = hashes
+	err = s.userService.UpdateTwoFactor(ctx, userId, twoFactor)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor accepts a current authenticator code that has not been used yet,
+// or consumes one of the recovery codes.
+func (s Service) verifySecondFactor(ctx context.Context, userId int, twoFactor *user.TwoFactor, code string) error {
+	if twoFactor.EnabledAt == nil {
+		return apperror.New(apperror.ErrUnauthorized, "two-factor authentication is not enabled", nil)
+	}
+	if step, ok := validateTotp(twoFactor.Secret, code, time.Now()); ok {
+		key := "2fa:used:" + strconv.Itoa(userId) + ":" + strconv.FormatInt(step, 10)
+		fresh, err := s.redis.SetNX(ctx, key, 1, time.Second*totpPeriod*(2*totpSkew+1)).Result()
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return apperror.New(apperror.ErrUnauthorized, "code has already been used", nil)
+		}
+		return nil
+	}
+	used, err := s.userService.UseRecoveryCode(ctx, userId, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return apperror.New(apperror.ErrUnauthorized, "invalid code", nil)
+	}
+	return nil
+}
+
+// the challenge token has no session id, so the authentication middleware never accepts it
+func (s Service) generateChallengeToken(userId int, setup bool) (string, error) {
+	jti, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["sub"] = strconv.Itoa(userId)
+	claims["typ"] = challengeTokenType
+	claims["jti"] = jti
+	claims["setup"] = setup
+	claims["exp"] = time.Now().Add(time.Minute * challengeTokenTtl).Unix()
+	return token.SignedString([]byte(s.config.JwtSecret))
+}
+
+// parseChallengeToken also counts the attempt, so a challenge cannot be used to
+// brute force the six digit code.
+func (s Service) parseChallengeToken(ctx context.Context, challengeToken string) (int, bool, string, error) {
+	invalid := apperror.New(apperror.ErrUnauthorized, "invalid or expired challenge, please log in again", nil)
+	token, err := jwt.Parse(challengeToken, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(s.config.JwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, false, "", invalid
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if typ, _ := claims["typ"].(string); typ != challengeTokenType {
+		return 0, false, "", invalid
+	}
+	sub, _ := claims["sub"].(string)
+	userId, err := strconv.Atoi(sub)
+	if err != nil {
+		return 0, false, "", invalid
+	}
+	jti, _ := claims["jti"].(string)
+	setup, _ := claims["setup"].(bool)
+	key := challengeAttemptsKey(jti)
+	attempts, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, false, "", err
+	}
+	if attempts == 1 {
+		s.redis.Expire(ctx, key, time.Minute*challengeTokenTtl)
+	}
+	if attempts > challengeMaxAttempts {
+		return 0, false, "", invalid
+	}
+	return userId, setup, jti, nil
+}
+
+func (s Service) consumeChallenge(ctx context.Context, jti string) {
+	s.redis.Set(ctx, challengeAttemptsKey(jti), challengeMaxAttempts, time.Minute*challengeTokenTtl)
+}
+
+func challengeAttemptsKey(jti string) string {
+	return "2fa:challenge:" + jti
+}
@@ -19,3 +19,7 @@ type Entity struct {
 func (e *Entity) TableName() string {
 	return "comments"
 }
+
+func (e Entity) ResourceOwner() int {
+	return e.UserId
+}
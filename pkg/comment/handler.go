@@ -15,23 +15,25 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
 	group.GET("/sse", h.realTime,
-		middleware.RequireAuthenticationQuery(h.config.JwtSecret),
+		h.authenticator.RequireAuthenticationQuery(),
 		middleware.RequirePermission(enforcer.ReadComment),
 	)
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadComment))
 	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.ReadComment))
 	group.POST("", h.create, middleware.RequirePermission(enforcer.CreateComment))
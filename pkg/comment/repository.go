@@ -3,6 +3,7 @@ package comment
 import (
 	"context"
 	"gorm.io/gorm"
+	"mcm-api/pkg/contribution"
 )
 
 type repository struct {
@@ -51,9 +52,20 @@ func (r repository) FindCursor(ctx context.Context, query *IndexQuery) ([]*Entit
 	return entities, nextCursor, nil
 }
 
-func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Create(entity)
-	r.db.WithContext(ctx).First(&entity.User, entity.UserId)
+// Create saves the comment along with its event on the timeline of the contribution.
+func (r repository) Create(ctx context.Context, entity *Entity, event *contribution.EventEntity) (*Entity, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(entity).Error
+		if err != nil {
+			return err
+		}
+		event.After = &entity.Id
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return entity, err
+	}
+	db := r.db.WithContext(ctx).First(&entity.User, entity.UserId)
 	return entity, db.Error
 }
 
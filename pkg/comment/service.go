@@ -39,15 +39,17 @@ func InitializeService(
 }
 
 func (s Service) Find(ctx context.Context, query *IndexQuery) (*common.CursorResponse, error) {
-	u, _ := enforcer.GetLoggedInUser(ctx)
-	contrib, err := s.contributionService.FindById(ctx, query.ContributionId)
+	u, err := enforcer.GetLoggedInUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = canCommentOnContribution(u, contrib)
+	contrib, err := s.contributionService.FindById(ctx, query.ContributionId)
 	if err != nil {
 		return nil, err
 	}
+	if !enforcer.Enforce(*u, enforcer.ReadComment, contrib) {
+		return nil, apperror.New(apperror.ErrForbidden, "you cannot read comments of this contribution", nil)
+	}
 	entities, nextCursor, err := s.repository.FindCursor(ctx, query)
 	if err != nil {
 		return nil, err
@@ -85,40 +87,34 @@ func (s Service) Create(ctx context.Context, body *CommentCreateReq) (*CommentRe
 	if err != nil {
 		return nil, err
 	}
-	err = canCommentOnContribution(u, ctb)
-	if err != nil {
-		return nil, err
+	if !enforcer.Enforce(*u, enforcer.CreateComment, ctb) {
+		return nil, apperror.New(apperror.ErrForbidden, "you cannot comment on this contribution", nil)
 	}
 	entity, err := s.repository.Create(ctx, &Entity{
 		UserId:         u.Id,
 		ContributionId: ctb.Id,
 		Content:        body.Content,
-	})
+	}, contribution.NewCommentEvent(u, ctb.Id))
 	if err != nil {
 		return nil, err
 	}
+	// feedback comes from whoever reviews the contribution, whatever their role is called
+	if u.Id != ctb.User.Id && enforcer.Enforce(*u, enforcer.UpdateContributionStatus, ctb) {
+		err = s.contributionService.MarkFeedbackGiven(ctx, ctb.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
 	res := mapEntityToRes(entity)
 	s.publishComment(ctx, ctb.Id, res)
 	return res, nil
 }
 
-func canCommentOnContribution(user *enforcer.LoggedInUser, contrib *contribution.ContributionRes) error {
-	if user.Role == enforcer.Student &&
-		contrib.User.Id != user.Id {
-		return apperror.New(apperror.ErrForbidden,
-			"you are not owner of this contribution", nil)
-	}
-	if user.Role == enforcer.MarketingCoordinator &&
-		*contrib.User.FacultyId != *user.FacultyId {
-		return apperror.New(
-			apperror.ErrForbidden,
-			"you are not in same faculty with contribution", nil)
-	}
-	return nil
-}
-
 func (s Service) Update(ctx context.Context, id string, body *CommentUpdateReq) (*CommentRes, error) {
-	u, _ := enforcer.GetLoggedInUser(ctx)
+	u, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -126,7 +122,7 @@ func (s Service) Update(ctx context.Context, id string, body *CommentUpdateReq)
 		}
 		return nil, err
 	}
-	if entity.UserId != u.Id {
+	if !enforcer.Enforce(*u, enforcer.UpdateComment, entity) {
 		return nil, apperror.New(apperror.ErrForbidden, "not your comment", nil)
 	}
 	entity.Content = body.Content
@@ -138,7 +134,10 @@ func (s Service) Update(ctx context.Context, id string, body *CommentUpdateReq)
 }
 
 func (s Service) Delete(ctx context.Context, id string) error {
-	u, _ := enforcer.GetLoggedInUser(ctx)
+	u, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -146,17 +145,24 @@ func (s Service) Delete(ctx context.Context, id string) error {
 		}
 		return err
 	}
-	if entity.UserId != u.Id {
+	if !enforcer.Enforce(*u, enforcer.DeleteComment, entity) {
 		return apperror.New(apperror.ErrForbidden, "not your comment", nil)
 	}
 	return s.repository.Delete(ctx, id)
 }
 
 func (s Service) StreamingComment(ctx context.Context, contributionId int, channel chan CommentRes) error {
-	_, err := s.contributionService.FindById(ctx, contributionId)
+	u, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	contrib, err := s.contributionService.FindById(ctx, contributionId)
 	if err != nil {
 		return err
 	}
+	if !enforcer.Enforce(*u, enforcer.ReadComment, contrib) {
+		return apperror.New(apperror.ErrForbidden, "you cannot read comments of this contribution", nil)
+	}
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     s.cfg.RedisAddr,
 		Password: s.cfg.RedisPassword, // no password set
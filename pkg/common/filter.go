@@ -0,0 +1,259 @@
+package common
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mcm-api/pkg/apperror"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	filterMax  = 20
+	inValueMax = 100
+)
+
+// FilterQuery is embedded in index queries to search, filter and sort by the fields
+// an endpoint allows. A filter is written field:operator:value and can be repeated,
+// e.g. filter=createdAt:gte:2021-04-01&filter=status:in:active,pending. Sort is a comma
+// separated list of fields, a leading - sorts descending, e.g. sort=-createdAt,name.
+type FilterQuery struct {
+	Search string   `query:"search"`
+	Filter []string `query:"filter" example:"createdAt:gte:2021-04-01"`
+	Sort   string   `query:"sort" example:"-createdAt,name"`
+}
+
+type FieldType int
+
+const (
+	StringField FieldType = iota
+	IntField
+	BoolField
+	// TimeField values are RFC 3339 timestamps or dates like 2021-04-01, which mean midnight UTC
+	TimeField
+)
+
+type Operator string
+
+const (
+	OpEq       Operator = "eq"
+	OpNe       Operator = "ne"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+	OpIn       Operator = "in"
+	OpContains Operator = "contains"
+)
+
+var sqlOperators = map[Operator]string{
+	OpEq:  "=",
+	OpNe:  "<>",
+	OpGt:  ">",
+	OpGte: ">=",
+	OpLt:  "<",
+	OpLte: "<=",
+}
+
+var fieldOperators = map[FieldType][]Operator{
+	StringField: {OpEq, OpNe, OpIn, OpContains},
+	IntField:    {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn},
+	BoolField:   {OpEq, OpNe},
+	TimeField:   {OpGt, OpGte, OpLt, OpLte},
+}
+
+// Field is a field of the api that can be filtered, Column is the sql column it maps to.
+type Field struct {
+	Column   string
+	Type     FieldType
+	Sortable bool
+}
+
+// ListSpec lists what an endpoint allows. The search matches a substring of any of
+// SearchColumns and ranks by trigram similarity, so the columns need the pg_trgm
+// extension and should have a gin_trgm_ops index.
+type ListSpec struct {
+	Fields        map[string]Field
+	SearchColumns []string
+}
+
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// ListFilter is a FilterQuery checked against a ListSpec, its columns are safe to use in sql.
+type ListFilter struct {
+	Search        string
+	SearchColumns []string
+	Conditions    []Condition
+	Orders        []Order
+}
+
+// Parse validates the query against the spec, unknown fields and operators that do
+// not fit the type of a field are invalid.
+func (q FilterQuery) Parse(spec ListSpec) (*ListFilter, error) {
+	result := &ListFilter{}
+	if search := strings.TrimSpace(q.Search); search != "" && len(spec.SearchColumns) > 0 {
+		result.Search = search
+		result.SearchColumns = spec.SearchColumns
+	}
+	if len(q.Filter) > filterMax {
+		return nil, apperror.New(apperror.ErrInvalid, fmt.Sprintf("at most %v filters are allowed", filterMax), nil)
+	}
+	for _, filter := range q.Filter {
+		condition, err := parseCondition(filter, spec.Fields)
+		if err != nil {
+			return nil, err
+		}
+		result.Conditions = append(result.Conditions, *condition)
+	}
+	for _, name := range strings.Split(q.Sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		order := Order{}
+		if strings.HasPrefix(name, "-") {
+			order.Desc = true
+			name = name[1:]
+		}
+		field, ok := spec.Fields[name]
+		if !ok || !field.Sortable {
+			return nil, apperror.New(apperror.ErrInvalid, "cannot sort by "+name, nil)
+		}
+		order.Column = field.Column
+		result.Orders = append(result.Orders, order)
+	}
+	return result, nil
+}
+
+func parseCondition(filter string, fields map[string]Field) (*Condition, error) {
+	parts := strings.SplitN(filter, ":", 3)
+	if len(parts) != 3 {
+		return nil, apperror.New(apperror.ErrInvalid, "a filter must be written field:operator:value", nil)
+	}
+	name, operator, raw := parts[0], Operator(parts[1]), parts[2]
+	field, ok := fields[name]
+	if !ok {
+		return nil, apperror.New(apperror.ErrInvalid, "cannot filter by "+name, nil)
+	}
+	if !isOperatorAllowed(field.Type, operator) {
+		return nil, apperror.New(apperror.ErrInvalid,
+			fmt.Sprintf("operator %v cannot be used with %v", operator, name), nil)
+	}
+	condition := &Condition{Column: field.Column, Operator: operator}
+	if operator == OpIn {
+		raws := strings.Split(raw, ",")
+		if len(raws) > inValueMax {
+			return nil, apperror.New(apperror.ErrInvalid,
+				fmt.Sprintf("at most %v values are allowed in %v", inValueMax, name), nil)
+		}
+		values := make([]interface{}, len(raws))
+		for i, r := range raws {
+			value, err := parseValue(field.Type, r)
+			if err != nil {
+				return nil, apperror.New(apperror.ErrInvalid, "invalid value of "+name, err)
+			}
+			values[i] = value
+		}
+		condition.Value = values
+		return condition, nil
+	}
+	value, err := parseValue(field.Type, raw)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInvalid, "invalid value of "+name, err)
+	}
+	condition.Value = value
+	return condition, nil
+}
+
+func isOperatorAllowed(fieldType FieldType, operator Operator) bool {
+	for _, allowed := range fieldOperators[fieldType] {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func parseValue(fieldType FieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case IntField:
+		return strconv.Atoi(raw)
+	case BoolField:
+		return strconv.ParseBool(raw)
+	case TimeField:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", raw)
+	default:
+		return raw, nil
+	}
+}
+
+// ApplyWhere adds the search and the conditions to the query.
+func (f *ListFilter) ApplyWhere(db *gorm.DB) {
+	if f.Search != "" {
+		pattern := "%" + escapeLike(f.Search) + "%"
+		matches := make([]string, len(f.SearchColumns))
+		values := make([]interface{}, len(f.SearchColumns))
+		for i, column := range f.SearchColumns {
+			matches[i] = column + " ilike ?"
+			values[i] = pattern
+		}
+		db.Where(strings.Join(matches, " or "), values...)
+	}
+	for _, condition := range f.Conditions {
+		switch condition.Operator {
+		case OpIn:
+			db.Where(condition.Column+" in ?", condition.Value)
+		case OpContains:
+			db.Where(condition.Column+" ilike ?", "%"+escapeLike(condition.Value.(string))+"%")
+		default:
+			db.Where(fmt.Sprintf("%v %v ?", condition.Column, sqlOperators[condition.Operator]), condition.Value)
+		}
+	}
+}
+
+// ApplyOrder sorts the query by the requested fields then by defaultColumn. Without
+// requested fields, search results are ranked by similarity first.
+func (f *ListFilter) ApplyOrder(db *gorm.DB, defaultColumn string) {
+	var orders []string
+	var values []interface{}
+	if len(f.Orders) == 0 && f.Search != "" {
+		similarities := make([]string, len(f.SearchColumns))
+		for i, column := range f.SearchColumns {
+			similarities[i] = "similarity(" + column + ", ?)"
+			values = append(values, f.Search)
+		}
+		orders = append(orders, "greatest("+strings.Join(similarities, ", ")+") desc")
+	}
+	for _, order := range f.Orders {
+		if order.Desc {
+			orders = append(orders, order.Column+" desc")
+		} else {
+			orders = append(orders, order.Column)
+		}
+	}
+	orders = append(orders, defaultColumn)
+	// a single expression, gorm drops expressions when order by clauses are merged
+	db.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                strings.Join(orders, ", "),
+		Vars:               values,
+		WithoutParentheses: true,
+	}})
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
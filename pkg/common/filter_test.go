@@ -0,0 +1,109 @@
+package common
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"mcm-api/pkg/apperror"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testSpec = ListSpec{
+	Fields: map[string]Field{
+		"id":        {Column: "id", Type: IntField, Sortable: true},
+		"name":      {Column: "name", Type: StringField, Sortable: true},
+		"archived":  {Column: "archived", Type: BoolField},
+		"createdAt": {Column: "created_at", Type: TimeField, Sortable: true},
+	},
+	SearchColumns: []string{"name", "email"},
+}
+
+func TestFilterQueryParse(t *testing.T) {
+	query := FilterQuery{
+		Search: "  ann ",
+		Filter: []string{"id:in:1,2", "archived:eq:false", "createdAt:gte:2021-04-01", "createdAt:lt:2021-05-01T10:00:00+07:00"},
+		Sort:   "-createdAt, name",
+	}
+	filter, err := query.Parse(testSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ListFilter{
+		Search:        "ann",
+		SearchColumns: []string{"name", "email"},
+		Conditions: []Condition{
+			{Column: "id", Operator: OpIn, Value: []interface{}{1, 2}},
+			{Column: "archived", Operator: OpEq, Value: false},
+			{Column: "created_at", Operator: OpGte, Value: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{Column: "created_at", Operator: OpLt, Value: time.Date(2021, 5, 1, 3, 0, 0, 0, time.UTC)},
+		},
+		Orders: []Order{{Column: "created_at", Desc: true}, {Column: "name"}},
+	}
+	if len(filter.Conditions) != len(expected.Conditions) {
+		t.Fatalf("expected %v conditions, got %v", len(expected.Conditions), len(filter.Conditions))
+	}
+	for i, condition := range filter.Conditions {
+		if got, ok := condition.Value.(time.Time); ok {
+			if !got.Equal(expected.Conditions[i].Value.(time.Time)) {
+				t.Errorf("condition %v: expected %v, got %v", i, expected.Conditions[i].Value, got)
+			}
+			filter.Conditions[i].Value = expected.Conditions[i].Value
+		}
+	}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("expected %+v, got %+v", expected, filter)
+	}
+}
+
+func TestFilterQueryParseInvalid(t *testing.T) {
+	cases := map[string]FilterQuery{
+		"unknown field":      {Filter: []string{"password:eq:secret"}},
+		"missing value":      {Filter: []string{"name:eq"}},
+		"unknown operator":   {Filter: []string{"name:like:ann"}},
+		"operator of type":   {Filter: []string{"name:gt:ann"}},
+		"contains on int":    {Filter: []string{"id:contains:1"}},
+		"invalid int":        {Filter: []string{"id:eq:one"}},
+		"invalid in value":   {Filter: []string{"id:in:1,two"}},
+		"invalid bool":       {Filter: []string{"archived:eq:maybe"}},
+		"invalid time":       {Filter: []string{"createdAt:gte:yesterday"}},
+		"unsortable field":   {Sort: "archived"},
+		"unknown sort field": {Sort: "-password"},
+		"sql in sort":        {Sort: "name;drop table users"},
+	}
+	for name, query := range cases {
+		_, err := query.Parse(testSpec)
+		if !apperror.Is(err, apperror.ErrInvalid) {
+			t.Errorf("%v: expected an invalid error, got %v", name, err)
+		}
+	}
+}
+
+func TestListFilterSql(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := FilterQuery{
+		Search: "50%_",
+		Filter: []string{"name:contains:an", "id:gt:3"},
+	}
+	filter, err := query.Parse(testSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := db.Table("users")
+	filter.ApplyWhere(tx)
+	filter.ApplyOrder(tx, "id")
+	statement := tx.Find(&[]map[string]interface{}{}).Statement
+
+	expectedSql := `SELECT * FROM "users" WHERE (name ilike $1 or email ilike $2) AND name ilike $3 AND id > $4 ` +
+		`ORDER BY greatest(similarity(name, $5), similarity(email, $6)) desc, id`
+	if statement.SQL.String() != expectedSql {
+		t.Errorf("expected %v, got %v", expectedSql, statement.SQL.String())
+	}
+	expectedVars := []interface{}{`%50\%\_%`, `%50\%\_%`, "%an%", 3, "50%_", "50%_"}
+	if !reflect.DeepEqual(statement.Vars, expectedVars) {
+		t.Errorf("expected %v, got %v", expectedVars, statement.Vars)
+	}
+}
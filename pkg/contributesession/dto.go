@@ -13,6 +13,8 @@ type SessionRes struct {
 	FinalClosureTime time.Time `json:"finalClosureTime"`
 	ExportedAssets   string    `json:"exportedAssets"`
 	ExportAssetsCdn  string    `json:"exportedAssetsCdn,omitempty"`
+	// FacultyIds the session is open to, empty when it is open to every faculty
+	FacultyIds []int `json:"facultyIds"`
 	common.TrackTime
 }
 
@@ -20,6 +22,8 @@ type SessionCreateReq struct {
 	OpenTime         time.Time `json:"openTime"`
 	ClosureTime      time.Time `json:"closureTime"`
 	FinalClosureTime time.Time `json:"finalClosureTime"`
+	// FacultyIds opens the session to some faculties only, leave it empty for every faculty
+	FacultyIds []int `json:"facultyIds"`
 }
 
 func (s SessionCreateReq) Validate() error {
@@ -33,6 +37,7 @@ func (s SessionCreateReq) Validate() error {
 			validation.Required,
 			validation.Min(s.ClosureTime),
 		),
+		validation.Field(&s.FacultyIds, validation.Each(validation.Min(1))),
 	)
 }
 
@@ -40,6 +45,8 @@ type SessionUpdateReq struct {
 	OpenTime         time.Time `json:"openTime"`
 	ClosureTime      time.Time `json:"closureTime"`
 	FinalClosureTime time.Time `json:"finalClosureTIme"`
+	// FacultyIds opens the session to some faculties only, leave it empty for every faculty
+	FacultyIds []int `json:"facultyIds"`
 }
 
 func (s SessionUpdateReq) Validate() error {
@@ -53,11 +60,14 @@ func (s SessionUpdateReq) Validate() error {
 			validation.Required,
 			validation.Min(s.ClosureTime),
 		),
+		validation.Field(&s.FacultyIds, validation.Each(validation.Min(1))),
 	)
 }
 
 type IndexQuery struct {
 	common.PaginateQuery
+	// FacultyId lists the sessions open to the faculty
+	FacultyId *int `query:"facultyId"`
 }
 
 type PaginateComposition struct {
@@ -8,10 +8,52 @@ type Entity struct {
 	ClosureTime      time.Time
 	FinalClosureTime time.Time
 	ExportedAssets   string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// Faculties the session is open to, a session without faculties is open to every faculty
+	Faculties []FacultyEntity `gorm:"foreignKey:ContributeSessionId"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 func (e *Entity) TableName() string {
 	return "contribute_sessions"
 }
+
+func (e *Entity) FacultyIds() []int {
+	ids := make([]int, 0, len(e.Faculties))
+	for _, f := range e.Faculties {
+		ids = append(ids, f.FacultyId)
+	}
+	return ids
+}
+
+type FacultyEntity struct {
+	ContributeSessionId int `gorm:"primaryKey"`
+	FacultyId           int `gorm:"primaryKey"`
+}
+
+func (e *FacultyEntity) TableName() string {
+	return "contribute_session_faculties"
+}
+
+// Event is a point of the lifecycle of a session the scheduler acts on.
+type Event string
+
+const (
+	// EventClosingReminder reminds the students who did not contribute that the closure is near
+	EventClosingReminder Event = "closing_reminder"
+	// EventClosureReached tells the coordinators the contributions can be reviewed
+	EventClosureReached Event = "closure_reached"
+	// EventFinalClosureReached exports the accepted contributions
+	EventFinalClosureReached Event = "final_closure_reached"
+)
+
+// EventEntity records an event the scheduler fired so it fires once per session.
+type EventEntity struct {
+	ContributeSessionId int   `gorm:"primaryKey"`
+	Event               Event `gorm:"primaryKey"`
+	FiredAt             time.Time
+}
+
+func (e *EventEntity) TableName() string {
+	return "contribute_session_events"
+}
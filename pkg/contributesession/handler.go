@@ -11,19 +11,21 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadContributeSession))
 	group.GET("/current", h.getCurrentSession)
 	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.ReadContributeSession))
@@ -78,7 +80,7 @@ func (h *Handler) getById(context echo.Context) error {
 
 // @Tags Contribute Sessions
 // @Summary Create a Contribute Session
-// @Description Create a Contribute Session
+// @Description Create a Contribute Session, a session overlapping another session of one of its faculties is a conflict listing the overlapping sessionIds in data
 // @Accept  json
 // @Produce  json
 // @Param body body contributesession.SessionCreateReq true "create"
@@ -100,7 +102,7 @@ func (h *Handler) create(context echo.Context) error {
 
 // @Tags Contribute Sessions
 // @Summary Update a Contribute Session
-// @Description Update a Contribute Session
+// @Description Update a Contribute Session, a session overlapping another session of one of its faculties is a conflict listing the overlapping sessionIds in data
 // @Accept  json
 // @Produce  json
 // @Param id path int true "ID"
@@ -169,14 +171,18 @@ func (h *Handler) export(context echo.Context) error {
 
 // @Tags Contribute Sessions
 // @Summary Get Current Contribute Session
-// @Description Get Current Contribute Session
+// @Description Get the session running now for the faculty of the logged in user, users without faculty get any running session
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} contributesession.SessionRes
 // @Security ApiKeyAuth
 // @Router /contribute-sessions/current [get]
 func (h *Handler) getCurrentSession(context echo.Context) error {
-	result, err := h.service.GetCurrentSession(context.Request().Context())
+	loggedInUser, err := enforcer.GetLoggedInUser(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.GetCurrentSession(context.Request().Context(), loggedInUser.FacultyId)
 	if err != nil {
 		return apperror.HandleError(err, context)
 	}
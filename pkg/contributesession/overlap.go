@@ -0,0 +1,38 @@
+package contributesession
+
+// overlaps reports whether two sessions run at the same time, a session runs from its
+// open time until its final closure time excluded so sessions may touch.
+func overlaps(a *Entity, b *Entity) bool {
+	return a.OpenTime.Before(b.FinalClosureTime) && b.OpenTime.Before(a.FinalClosureTime)
+}
+
+// sharesFaculty reports whether a faculty could take part in both sessions, a session
+// without faculties is open to every faculty.
+func sharesFaculty(a *Entity, b *Entity) bool {
+	if len(a.Faculties) == 0 || len(b.Faculties) == 0 {
+		return true
+	}
+	for _, fa := range a.Faculties {
+		for _, fb := range b.Faculties {
+			if fa.FacultyId == fb.FacultyId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlappingSessions returns the ids of the sessions running at the same time as the
+// candidate for one of its faculties, the candidate itself is ignored.
+func overlappingSessions(candidate *Entity, sessions []*Entity) []int {
+	var result []int
+	for _, session := range sessions {
+		if session.Id != 0 && session.Id == candidate.Id {
+			continue
+		}
+		if overlaps(candidate, session) && sharesFaculty(candidate, session) {
+			result = append(result, session.Id)
+		}
+	}
+	return result
+}
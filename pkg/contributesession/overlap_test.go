@@ -0,0 +1,114 @@
+package contributesession
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func session(id int, openDay int, finalClosureDay int, facultyIds ...int) *Entity {
+	entity := &Entity{
+		Id:               id,
+		OpenTime:         day.AddDate(0, 0, openDay),
+		ClosureTime:      day.AddDate(0, 0, openDay),
+		FinalClosureTime: day.AddDate(0, 0, finalClosureDay),
+	}
+	for _, facultyId := range facultyIds {
+		entity.Faculties = append(entity.Faculties, FacultyEntity{ContributeSessionId: id, FacultyId: facultyId})
+	}
+	return entity
+}
+
+func TestOverlaps(t *testing.T) {
+	existing := session(1, 10, 20)
+	cases := []struct {
+		name     string
+		session  *Entity
+		expected bool
+	}{
+		{"before", session(0, 0, 5), false},
+		{"after", session(0, 25, 30), false},
+		{"ends when the other opens", session(0, 0, 10), false},
+		{"opens when the other ends", session(0, 20, 30), false},
+		{"ends one nanosecond after the other opens", &Entity{
+			OpenTime:         day,
+			FinalClosureTime: existing.OpenTime.Add(time.Nanosecond),
+		}, true},
+		{"opens one nanosecond before the other ends", &Entity{
+			OpenTime:         existing.FinalClosureTime.Add(-time.Nanosecond),
+			FinalClosureTime: day.AddDate(0, 0, 30),
+		}, true},
+		{"overlaps the start", session(0, 5, 15), true},
+		{"overlaps the end", session(0, 15, 25), true},
+		{"inside", session(0, 12, 18), true},
+		{"contains", session(0, 5, 25), true},
+		{"same period", session(0, 10, 20), true},
+		{"same open time", session(0, 10, 12), true},
+		{"same final closure time", session(0, 18, 20), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := overlaps(c.session, existing); got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+			if got := overlaps(existing, c.session); got != c.expected {
+				t.Errorf("expected %v when swapped, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestSharesFaculty(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        *Entity
+		b        *Entity
+		expected bool
+	}{
+		{"every faculty", session(1, 0, 1), session(2, 0, 1), true},
+		{"every faculty and some", session(1, 0, 1), session(2, 0, 1, 3), true},
+		{"some and every faculty", session(1, 0, 1, 3), session(2, 0, 1), true},
+		{"same faculty", session(1, 0, 1, 3), session(2, 0, 1, 3), true},
+		{"one shared faculty", session(1, 0, 1, 3, 4), session(2, 0, 1, 5, 4), true},
+		{"different faculties", session(1, 0, 1, 3, 4), session(2, 0, 1, 5, 6), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := sharesFaculty(c.a, c.b); got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestOverlappingSessions(t *testing.T) {
+	sessions := []*Entity{
+		session(1, 0, 10),
+		session(2, 10, 20, 1),
+		session(3, 10, 20, 2),
+		session(4, 20, 30, 1, 2),
+		session(5, 15, 25, 3),
+	}
+	cases := []struct {
+		name      string
+		candidate *Entity
+		expected  []int
+	}{
+		{"new session of every faculty", session(0, 5, 16), []int{1, 2, 3, 5}},
+		{"new session of a faculty", session(0, 12, 22, 1), []int{2, 4}},
+		{"new session of a faculty without session", session(0, 0, 30, 4), []int{1}},
+		{"new session touching the others", session(0, 30, 40), nil},
+		{"updated session keeping its period", session(2, 10, 20, 1), nil},
+		{"updated session moved onto another", session(2, 20, 30, 1), []int{4}},
+		{"updated session opened to every faculty", session(2, 10, 20), []int{3, 5}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := overlappingSessions(c.candidate, sessions); !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
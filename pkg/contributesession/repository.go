@@ -2,10 +2,18 @@ package contributesession
 
 import (
 	"context"
+	"errors"
 	"gorm.io/gorm"
 	"time"
 )
 
+// exclusionViolation is the sqlstate of an insert rejected by an exclusion constraint
+const exclusionViolation = "23P01"
+
+// ErrOverlap is returned when the slots of a session overlap the slots of another
+// session, the service checks it first so it only happens on concurrent writes.
+var ErrOverlap = errors.New("contribute session overlaps another session")
+
 type repository struct {
 	db *gorm.DB
 }
@@ -18,7 +26,7 @@ func InitializeRepository(db *gorm.DB) *repository {
 
 func (r repository) FindById(ctx context.Context, id int) (*Entity, error) {
 	result := new(Entity)
-	db := r.db.WithContext(ctx).First(result, id)
+	db := r.db.WithContext(ctx).Preload("Faculties").First(result, id)
 	return result, db.Error
 }
 
@@ -32,48 +40,159 @@ func (r repository) Find(ctx context.Context, query *IndexQuery) ([]*Entity, err
 }
 
 func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Create(entity)
-	return entity, db.Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(entity).Error
+		if err != nil {
+			return err
+		}
+		return saveSlots(tx, entity)
+	})
+	return entity, err
 }
 
+// Update saves the session and replaces its faculties.
 func (r repository) Update(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Save(entity)
-	return entity, db.Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("contribute_session_id = ?", entity.Id).Delete(&FacultyEntity{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Save(entity).Error
+		if err != nil {
+			return err
+		}
+		return saveSlots(tx, entity)
+	})
+	return entity, err
+}
+
+// saveSlots replaces the slots of the session, the exclusion constraint of the slots
+// rejects sessions sharing a faculty at the same time.
+func saveSlots(tx *gorm.DB, entity *Entity) error {
+	err := tx.Exec("delete from contribute_session_slots where contribute_session_id = ?", entity.Id).Error
+	if err != nil {
+		return err
+	}
+	facultyIds := []*int{nil}
+	if len(entity.Faculties) > 0 {
+		facultyIds = facultyIds[:0]
+		for i := range entity.Faculties {
+			facultyIds = append(facultyIds, &entity.Faculties[i].FacultyId)
+		}
+	}
+	for _, facultyId := range facultyIds {
+		err = tx.Exec("insert into contribute_session_slots (contribute_session_id, faculty_scope, period) "+
+			"values (?, int4range(?, ?, '[]'), tstzrange(?, ?, '[)'))",
+			entity.Id, facultyId, facultyId, entity.OpenTime, entity.FinalClosureTime).Error
+		var pgErr interface{ SQLState() string }
+		if errors.As(err, &pgErr) && pgErr.SQLState() == exclusionViolation {
+			return ErrOverlap
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r repository) Delete(ctx context.Context, id int) error {
 	return r.db.WithContext(ctx).Delete(&Entity{Id: id}).Error
 }
 
-func (r repository) FindSessionFromTime(ctx context.Context, time time.Time) (*Entity, error) {
+// FindSessionFromTime finds the session running at the time for the faculty, sessions
+// open to the faculty only come before sessions open to every faculty. Without faculty
+// any running session is returned.
+func (r repository) FindSessionFromTime(ctx context.Context, time time.Time, facultyId *int) (*Entity, error) {
 	var entity Entity
-	db := r.db.WithContext(ctx).Where("open_time < ?", time).
-		Where("final_closure_time > ?", time).
+	builder := r.db.WithContext(ctx).Preload("Faculties").
+		Where("open_time < ?", time).
+		Where("final_closure_time > ?", time)
+	if facultyId != nil {
+		openToFaculty(builder, []int{*facultyId})
+	}
+	db := builder.
+		Order("exists(select 1 from contribute_session_faculties f " +
+			"where f.contribute_session_id = contribute_sessions.id) desc").
+		Order("open_time desc").
 		First(&entity)
 	return &entity, db.Error
 }
 
+// FindSessionsFromTime finds every session running at the time.
+func (r repository) FindSessionsFromTime(ctx context.Context, time time.Time) ([]*Entity, error) {
+	var entities []*Entity
+	db := r.db.WithContext(ctx).Preload("Faculties").
+		Where("open_time < ?", time).
+		Where("final_closure_time > ?", time).
+		Order("open_time").
+		Find(&entities)
+	return entities, db.Error
+}
+
+// openToFaculty keeps the sessions open to one of the faculties, including the sessions
+// open to every faculty.
+func openToFaculty(db *gorm.DB, facultyIds []int) {
+	db.Where("not exists(select 1 from contribute_session_faculties f "+
+		"where f.contribute_session_id = contribute_sessions.id) "+
+		"or exists(select 1 from contribute_session_faculties f "+
+		"where f.contribute_session_id = contribute_sessions.id and f.faculty_id in ?)", facultyIds)
+}
+
 func (r repository) FindAndCount(ctx context.Context, query *IndexQuery) ([]*Entity, int64, error) {
 	var entities []*Entity
 	builder := r.db.WithContext(ctx).Model(&Entity{})
+	if query.FacultyId != nil {
+		openToFaculty(builder, []int{*query.FacultyId})
+	}
 	var count int64
 	result := builder.Count(&count)
 	if result.Error != nil {
 		return nil, 0, nil
 	}
 	builder.Offset(query.GetOffSet()).Limit(query.GetLimit())
-	result = builder.Find(&entities)
+	result = builder.Preload("Faculties").Find(&entities)
 	return entities, count, result.Error
 }
 
-func (r repository) GetLastSession(ctx context.Context, except ...int) (*Entity, error) {
-	var entity Entity
-	builder := r.db.WithContext(ctx).Debug().Order("final_closure_time DESC")
-	if except != nil {
-		builder.Not(except)
+// FindAll returns every session with its faculties.
+func (r repository) FindAll(ctx context.Context) ([]*Entity, error) {
+	var entities []*Entity
+	db := r.db.WithContext(ctx).Preload("Faculties").Order("open_time").Find(&entities)
+	return entities, db.Error
+}
+
+// FindDueForEvent finds the sessions the event has not fired for yet whose time came,
+// an event whose time passed without firing is skipped: the closing reminder after
+// the closure and the closure after the final closure.
+func (r repository) FindDueForEvent(ctx context.Context, event Event, now time.Time, reminderLead time.Duration) ([]*Entity, error) {
+	var entities []*Entity
+	builder := r.db.WithContext(ctx).Preload("Faculties").
+		Where("not exists(select 1 from contribute_session_events e "+
+			"where e.contribute_session_id = contribute_sessions.id and e.event = ?)", event)
+	switch event {
+	case EventClosingReminder:
+		builder.Where("closure_time <= ? and closure_time > ?", now.Add(reminderLead), now)
+	case EventClosureReached:
+		builder.Where("closure_time <= ? and final_closure_time > ?", now, now)
+	case EventFinalClosureReached:
+		builder.Where("final_closure_time <= ?", now)
+	default:
+		return nil, errors.New("unknown contribute session event " + string(event))
 	}
-	db := builder.First(&entity)
-	return &entity, db.Error
+	db := builder.Order("id").Find(&entities)
+	return entities, db.Error
+}
+
+func (r repository) HasEvent(ctx context.Context, id int, event Event) (bool, error) {
+	var count int64
+	db := r.db.WithContext(ctx).Model(&EventEntity{}).
+		Where("contribute_session_id = ? and event = ?", id, event).
+		Count(&count)
+	return count > 0, db.Error
+}
+
+func (r repository) CreateEvent(ctx context.Context, entity *EventEntity) error {
+	return r.db.WithContext(ctx).Create(entity).Error
 }
 
 func (r repository) HasContribution(ctx context.Context, id int) (bool, error) {
@@ -9,6 +9,7 @@ import (
 	"mcm-api/pkg/apperror"
 	"mcm-api/pkg/common"
 	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/log"
 	"mcm-api/pkg/media"
 	"mcm-api/pkg/queue"
@@ -16,10 +17,11 @@ import (
 )
 
 type Service struct {
-	cfg        *config.Config
-	repository *repository
-	queue      queue.Queue
-	media      media.Service
+	cfg            *config.Config
+	repository     *repository
+	queue          queue.Queue
+	media          media.Service
+	facultyService *faculty.Service
 }
 
 func InitializeService(
@@ -27,12 +29,14 @@ func InitializeService(
 	repository *repository,
 	queue queue.Queue,
 	media media.Service,
+	facultyService *faculty.Service,
 ) *Service {
 	return &Service{
-		cfg:        cfg,
-		repository: repository,
-		queue:      queue,
-		media:      media,
+		cfg:            cfg,
+		repository:     repository,
+		queue:          queue,
+		media:          media,
+		facultyService: facultyService,
 	}
 }
 
@@ -45,7 +49,7 @@ func (s Service) Find(ctx context.Context, query *IndexQuery) (*common.PaginateR
 	if err != nil {
 		return nil, err
 	}
-	res := s.mapEntitiesToRes(entities, enforcer.Can(user.Role, enforcer.ExportContributeSession))
+	res := s.mapEntitiesToRes(entities, enforcer.Enforce(*user, enforcer.ExportContributeSession))
 	return common.NewPaginateResponse(res, count, query.Page, query.GetLimit()), nil
 }
 
@@ -63,7 +67,7 @@ func (s Service) FindById(ctx context.Context, id int) (*SessionRes, error) {
 	}
 	return s.mapEntityToRes(
 		entity,
-		enforcer.Can(user.Role, enforcer.ExportContributeSession),
+		enforcer.Enforce(*user, enforcer.ExportContributeSession),
 	), nil
 }
 
@@ -71,35 +75,31 @@ func (s Service) Create(ctx context.Context, body *SessionCreateReq) (*SessionRe
 	if err := body.Validate(); err != nil {
 		return nil, err
 	}
-	lastSession, err := s.repository.GetLastSession(ctx)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	faculties, err := s.mapFaculties(ctx, body.FacultyIds)
+	if err != nil {
 		return nil, err
 	}
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		if err == nil {
-			if (body.OpenTime.Before(lastSession.FinalClosureTime) || body.OpenTime.Equal(lastSession.FinalClosureTime)) &&
-				body.OpenTime.After(lastSession.OpenTime) || body.OpenTime.Equal(lastSession.OpenTime) {
-				return nil, apperror.New(apperror.ErrConflict, "conflict with last contribute session", nil)
-			}
-
-			if (body.FinalClosureTime.Before(lastSession.OpenTime) || body.FinalClosureTime.Equal(lastSession.OpenTime)) &&
-				body.FinalClosureTime.After(lastSession.FinalClosureTime) || body.FinalClosureTime.Equal(lastSession.FinalClosureTime) {
-				return nil, apperror.New(apperror.ErrConflict, "conflict with last contribute session", nil)
-			}
-		}
-	}
-	entity, err := s.repository.Create(ctx, &Entity{
+	entity := &Entity{
 		OpenTime:         body.OpenTime,
 		ClosureTime:      body.ClosureTime,
 		FinalClosureTime: body.FinalClosureTime,
-	})
+		Faculties:        faculties,
+	}
+	err = s.checkOverlap(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
+	entity, err = s.repository.Create(ctx, entity)
+	if err != nil {
+		return nil, mapSaveError(err)
+	}
 	return s.mapEntityToRes(entity, false), nil
 }
 
 func (s Service) Update(ctx context.Context, id int, body *SessionUpdateReq) (*SessionRes, error) {
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -107,28 +107,25 @@ func (s Service) Update(ctx context.Context, id int, body *SessionUpdateReq) (*S
 		}
 		return nil, err
 	}
-	lastSession, err := s.repository.GetLastSession(ctx, id)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	faculties, err := s.mapFaculties(ctx, body.FacultyIds)
+	if err != nil {
 		return nil, err
 	}
-	if err == nil {
-		if (body.OpenTime.Before(lastSession.FinalClosureTime) || body.OpenTime.Equal(lastSession.FinalClosureTime)) &&
-			body.OpenTime.After(lastSession.OpenTime) || body.OpenTime.Equal(lastSession.OpenTime) {
-			return nil, apperror.New(apperror.ErrConflict, "conflict with last contribute session", nil)
-		}
-
-		if (body.FinalClosureTime.Before(lastSession.OpenTime) || body.FinalClosureTime.Equal(lastSession.OpenTime)) &&
-			body.FinalClosureTime.After(lastSession.FinalClosureTime) || body.FinalClosureTime.Equal(lastSession.FinalClosureTime) {
-			return nil, apperror.New(apperror.ErrConflict, "conflict with last contribute session", nil)
-		}
-	}
 	entity.OpenTime = body.OpenTime
 	entity.ClosureTime = body.ClosureTime
 	entity.FinalClosureTime = body.FinalClosureTime
-	entity, err = s.repository.Update(ctx, entity)
+	entity.Faculties = faculties
+	for i := range entity.Faculties {
+		entity.Faculties[i].ContributeSessionId = entity.Id
+	}
+	err = s.checkOverlap(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
+	entity, err = s.repository.Update(ctx, entity)
+	if err != nil {
+		return nil, mapSaveError(err)
+	}
 	return s.mapEntityToRes(entity, false), nil
 }
 
@@ -148,8 +145,10 @@ func (s Service) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
-func (s Service) GetCurrentSession(ctx context.Context) (*SessionRes, error) {
-	entity, err := s.repository.FindSessionFromTime(ctx, time.Now())
+// GetCurrentSession returns the session running now for the faculty, without faculty
+// it returns any running session.
+func (s Service) GetCurrentSession(ctx context.Context, facultyId *int) (*SessionRes, error) {
+	entity, err := s.repository.FindSessionFromTime(ctx, time.Now(), facultyId)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, apperror.New(
 			apperror.ErrNotFound,
@@ -161,6 +160,39 @@ func (s Service) GetCurrentSession(ctx context.Context) (*SessionRes, error) {
 	return s.mapEntityToRes(entity, false), nil
 }
 
+// GetCurrentSessions returns every session running now, faculties on different
+// calendars may each have one.
+func (s Service) GetCurrentSessions(ctx context.Context) ([]*SessionRes, error) {
+	entities, err := s.repository.FindSessionsFromTime(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.mapEntitiesToRes(entities, false), nil
+}
+
+// FindDueForEvent returns the sessions the scheduler has to fire the event for, the
+// closing reminder is due reminderLead before the closure.
+func (s Service) FindDueForEvent(ctx context.Context, event Event, reminderLead time.Duration) ([]*SessionRes, error) {
+	entities, err := s.repository.FindDueForEvent(ctx, event, time.Now(), reminderLead)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapEntitiesToRes(entities, false), nil
+}
+
+func (s Service) IsEventFired(ctx context.Context, id int, event Event) (bool, error) {
+	return s.repository.HasEvent(ctx, id, event)
+}
+
+// MarkEventFired records the event so it is not fired again for the session.
+func (s Service) MarkEventFired(ctx context.Context, id int, event Event) error {
+	return s.repository.CreateEvent(ctx, &EventEntity{
+		ContributeSessionId: id,
+		Event:               event,
+		FiredAt:             time.Now(),
+	})
+}
+
 func (s Service) UpdateExportedAsset(ctx context.Context, id int, key string) error {
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
@@ -185,6 +217,51 @@ func (s Service) ExportAsset(ctx context.Context, id int) error {
 	})
 }
 
+// checkOverlap rejects a session running at the same time as another session of one
+// of its faculties, sessions of different faculties may run at the same time.
+func (s Service) checkOverlap(ctx context.Context, entity *Entity) error {
+	sessions, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	conflicts := overlappingSessions(entity, sessions)
+	if len(conflicts) > 0 {
+		return apperror.New(apperror.ErrConflict, "the session overlaps other sessions of its faculties", nil).
+			WithData(map[string][]int{"sessionIds": conflicts})
+	}
+	return nil
+}
+
+// mapSaveError reports the overlap caught by the database when a session was saved
+// concurrently.
+func mapSaveError(err error) error {
+	if errors.Is(err, ErrOverlap) {
+		return apperror.New(apperror.ErrConflict, "the session overlaps other sessions of its faculties", err)
+	}
+	return err
+}
+
+// mapFaculties checks the faculties a session is opened to exist and are not archived.
+func (s Service) mapFaculties(ctx context.Context, facultyIds []int) ([]FacultyEntity, error) {
+	var result []FacultyEntity
+	added := make(map[int]bool)
+	for _, id := range facultyIds {
+		if added[id] {
+			continue
+		}
+		added[id] = true
+		f, err := s.facultyService.FindById(ctx, id)
+		if err != nil {
+			return nil, apperror.New(apperror.ErrInvalid, "invalid faculty", err)
+		}
+		if f.IsArchived() {
+			return nil, apperror.New(apperror.ErrInvalid, "faculty is archived", nil)
+		}
+		result = append(result, FacultyEntity{FacultyId: id})
+	}
+	return result, nil
+}
+
 func (s Service) mapEntityToRes(entity *Entity, withCdn bool) *SessionRes {
 	session := &SessionRes{
 		Id:               entity.Id,
@@ -192,6 +269,7 @@ func (s Service) mapEntityToRes(entity *Entity, withCdn bool) *SessionRes {
 		ClosureTime:      entity.ClosureTime,
 		FinalClosureTime: entity.FinalClosureTime,
 		ExportedAssets:   entity.ExportedAssets,
+		FacultyIds:       entity.FacultyIds(),
 		TrackTime: common.TrackTime{
 			CreatedAt: entity.CreatedAt,
 			UpdatedAt: entity.UpdatedAt,
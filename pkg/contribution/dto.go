@@ -4,27 +4,55 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"mcm-api/pkg/common"
 	"mcm-api/pkg/enforcer"
+	"time"
 )
 
 type IndexQuery struct {
 	common.PaginateQuery
-	FacultyId             *int   `json:"facultyId"`
-	StudentId             *int   `json:"studentId"`
-	ContributionSessionId *int   `json:"contributionSessionId"`
-	Status                Status `json:"status" enums:"accepted,rejected,reviewing"`
+	FacultyId             *int          `json:"facultyId"`
+	StudentId             *int          `json:"studentId"`
+	ContributionSessionId *int          `json:"contributionSessionId"`
+	Status                Status        `json:"status" enums:"draft,submitted,under_review,changes_requested,resubmitted,accepted,rejected,selected,withdrawn"`
+	Feedback              FeedbackState `json:"feedback" enums:"overdue,at_risk"`
 }
 
+// FeedbackState filters the contributions still waiting for a coordinator comment.
+type FeedbackState string
+
+const (
+	// FeedbackOverdue contributions passed their feedback deadline without a coordinator comment
+	FeedbackOverdue FeedbackState = "overdue"
+	// FeedbackAtRisk contributions reach their feedback deadline in the next days
+	FeedbackAtRisk FeedbackState = "at_risk"
+)
+
 type ContributionRes struct {
-	Id                  int     `json:"id"`
-	User                UserRes `json:"user"`
-	ContributeSessionId int     `json:"contributeSessionId"`
-	ArticleId           *int    `json:"articleId"`
-	Title               string  `json:"title"`
-	Description         string  `json:"description"`
-	Status              Status  `json:"status"`
+	Id                  int        `json:"id"`
+	User                UserRes    `json:"user"`
+	ContributeSessionId int        `json:"contributeSessionId"`
+	FacultyId           *int       `json:"facultyId"`
+	ArticleId           *int       `json:"articleId"`
+	Title               string     `json:"title"`
+	Description         string     `json:"description"`
+	Status              Status     `json:"status"`
+	StatusReason        *string    `json:"statusReason"`
+	FeedbackDueAt       time.Time  `json:"feedbackDueAt"`
+	FeedbackGivenAt     *time.Time `json:"feedbackGivenAt"`
 	common.TrackTime
 }
 
+func (c ContributionRes) ResourceOwner() int {
+	return c.User.Id
+}
+
+func (c ContributionRes) ResourceFaculty() *int {
+	return c.FacultyId
+}
+
+func (c ContributionRes) ResourcePublished() bool {
+	return c.Status.IsPublished()
+}
+
 type UserRes struct {
 	Id        int           `json:"id"`
 	Name      string        `json:"name"`
@@ -54,17 +82,37 @@ type ContributionCreateReq struct {
 	Images      []ImageCreateReq `json:"images"`
 	Title       string           `json:"title"`
 	Description string           `json:"description"`
+	// Draft saves the contribution without submitting it, the article, images and
+	// title can be completed later
+	Draft bool `json:"draft"`
+	// AcceptedTermVersionId is the version of the term and condition the student
+	// accepted, it is required to submit
+	AcceptedTermVersionId int `json:"acceptedTermVersionId"`
+	// IpAddress is set by the handler
+	IpAddress string `json:"-"`
 }
 
 func (r *ContributionCreateReq) Validate() error {
 	return validation.ValidateStruct(r,
-		validation.Field(&r.Article, validation.Required.When(r.Images == nil)),
-		validation.Field(&r.Images, validation.Required.When(r.Article == nil)),
-		validation.Field(&r.Title, validation.Required, validation.Length(10, 255)),
-		validation.Field(&r.Description, validation.Length(15, 512)),
+		validation.Field(&r.Article, validation.Required.When(r.Images == nil && !r.Draft)),
+		validation.Field(&r.Images, validation.Required.When(r.Article == nil && !r.Draft)),
+		validation.Field(&r.Title, titleRule(r.Draft)),
+		validation.Field(&r.Description, descriptionRule(r.Draft)),
+		validation.Field(&r.AcceptedTermVersionId, validation.Required.When(!r.Draft)),
 	)
 }
 
+// titleRule lets a draft keep a partial title, the title is complete at submission.
+func titleRule(draft bool) validation.Rule {
+	return validation.When(!draft, validation.Required, validation.Length(10, 255)).
+		Else(validation.Length(0, 255))
+}
+
+func descriptionRule(draft bool) validation.Rule {
+	return validation.When(!draft, validation.Length(15, 512)).
+		Else(validation.Length(0, 512))
+}
+
 type ImageCreateReq struct {
 	Key   string `json:"key"`
 	Title string `json:"title"`
@@ -85,24 +133,44 @@ type ContributionUpdateReq struct {
 }
 
 func (r *ContributionUpdateReq) Validate() error {
+	return r.validate(false)
+}
+
+// ValidateDraft validates the autosave of a draft, which may be incomplete.
+func (r *ContributionUpdateReq) ValidateDraft() error {
+	return r.validate(true)
+}
+
+func (r *ContributionUpdateReq) validate(draft bool) error {
 	return validation.ValidateStruct(r,
-		validation.Field(&r.Article, validation.Required.When(r.Images == nil)),
-		validation.Field(&r.Images, validation.Required.When(r.Article == nil)),
-		validation.Field(&r.Title, validation.Required, validation.Length(10, 255)),
-		validation.Field(&r.Description, validation.Length(15, 512)),
+		validation.Field(&r.Article, validation.Required.When(r.Images == nil && !draft)),
+		validation.Field(&r.Images, validation.Required.When(r.Article == nil && !draft)),
+		validation.Field(&r.Title, titleRule(draft)),
+		validation.Field(&r.Description, descriptionRule(draft)),
 	)
 }
 
 type ContributionStatusReq struct {
-	Status Status `json:"status"`
+	Status Status `json:"status" enums:"submitted,under_review,changes_requested,resubmitted,accepted,rejected,selected,withdrawn"`
+	// Reason is required when rejecting or requesting changes
+	Reason string `json:"reason"`
+	// AcceptedTermVersionId is required to submit a draft, see ContributionCreateReq
+	AcceptedTermVersionId int `json:"acceptedTermVersionId"`
+	// IpAddress is set by the handler
+	IpAddress string `json:"-"`
 }
 
 func (c ContributionStatusReq) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.Status,
 			validation.Required,
-			validation.In(Accepted, Reviewing, Rejected),
+			validation.In(Submitted, UnderReview, ChangesRequested, Resubmitted, Accepted, Rejected, Selected, Withdrawn),
+		),
+		validation.Field(&c.Reason,
+			validation.Required.When(reasonRequired(c.Status)),
+			validation.Length(0, 1000),
 		),
+		validation.Field(&c.AcceptedTermVersionId, validation.Required.When(c.Status == Submitted)),
 	)
 }
 
@@ -110,3 +178,13 @@ type PaginateComposition struct {
 	common.PaginateResponse
 	Data []ContributionRes `json:"data"`
 }
+
+type TimelineEventRes struct {
+	Id        int       `json:"id"`
+	Type      EventType `json:"type" enums:"created,status_changed,article_version_uploaded,images_changed,title_changed,description_changed,commented"`
+	Actor     *UserRes  `json:"actor"`
+	Before    *string   `json:"before"`
+	After     *string   `json:"after"`
+	Note      *string   `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -9,22 +9,46 @@ import (
 type Status string
 
 const (
-	Accepted  Status = "accepted"
-	Rejected  Status = "rejected"
-	Reviewing Status = "reviewing"
+	Draft            Status = "draft"
+	Submitted        Status = "submitted"
+	UnderReview      Status = "under_review"
+	ChangesRequested Status = "changes_requested"
+	Resubmitted      Status = "resubmitted"
+	Accepted         Status = "accepted"
+	Rejected         Status = "rejected"
+	Selected         Status = "selected"
+	Withdrawn        Status = "withdrawn"
 )
 
+// publishedStatuses are the statuses of the contributions everyone in the faculty may read,
+// keep in sync with accessMapping.
+var publishedStatuses = []Status{Accepted, Selected}
+
+func (s Status) IsPublished() bool {
+	return hasStatus(publishedStatuses, s)
+}
+
+// IsEditable tells whether the author may still change the title, article and images.
+func (s Status) IsEditable() bool {
+	return s == Draft || s == Submitted || s == ChangesRequested
+}
+
 type Entity struct {
 	Id                  int
 	UserId              int
 	User                user.Entity `gorm:"foreignKey:UserId"`
 	ContributeSessionId int
+	FacultyId           *int
 	ArticleId           *int
 	Article             *article.Entity `gorm:"foreignKey:ArticleId"`
 	Title               string
 	Description         string
 	Status              Status
+	StatusReason        *string
 	Images              []ImageEntity `gorm:"foreignKey:ContributionId"`
+	FeedbackDueAt       time.Time
+	FeedbackGivenAt     *time.Time
+	FeedbackEscalatedAt *time.Time
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
@@ -33,6 +57,20 @@ func (e Entity) TableName() string {
 	return "contributions"
 }
 
+func (e Entity) ResourceOwner() int {
+	return e.UserId
+}
+
+// ResourceFaculty is the faculty of the author at submission time, later transfers of
+// the author do not move the contribution.
+func (e Entity) ResourceFaculty() *int {
+	return e.FacultyId
+}
+
+func (e Entity) ResourcePublished() bool {
+	return e.Status.IsPublished()
+}
+
 type ImageEntity struct {
 	Key            string `gorm:"primaryKey"`
 	ContributionId int
@@ -42,3 +80,34 @@ type ImageEntity struct {
 func (i ImageEntity) TableName() string {
 	return "images"
 }
+
+type EventType string
+
+const (
+	EventCreated                EventType = "created"
+	EventStatusChanged          EventType = "status_changed"
+	EventArticleVersionUploaded EventType = "article_version_uploaded"
+	EventImagesChanged          EventType = "images_changed"
+	EventTitleChanged           EventType = "title_changed"
+	EventDescriptionChanged     EventType = "description_changed"
+	EventCommented              EventType = "commented"
+)
+
+// EventEntity is an entry of the timeline of a contribution. Before and After hold the
+// status, title, description, article link or image keys, a comment event only
+// references the comment so erasing it does not leave a copy here.
+type EventEntity struct {
+	Id             int
+	ContributionId int
+	ActorId        *int
+	Actor          *user.Entity `gorm:"foreignKey:ActorId"`
+	Type           EventType
+	Before         *string
+	After          *string
+	Note           *string
+	CreatedAt      time.Time
+}
+
+func (e EventEntity) TableName() string {
+	return "contribution_events"
+}
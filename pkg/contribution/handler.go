@@ -11,24 +11,28 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadContribution))
 	group.GET("/:id/images", h.images, middleware.RequirePermission(enforcer.ReadContribution))
+	group.GET("/:id/timeline", h.timeline, middleware.RequirePermission(enforcer.ReadContribution))
 	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.ReadContribution))
 	group.POST("", h.create, middleware.RequirePermission(enforcer.CreateContribution))
-	group.POST("/:id/status", h.updateStatus, middleware.RequirePermission(enforcer.UpdateContributionStatus))
+	group.POST("/:id/status", h.updateStatus,
+		middleware.RequireAnyPermission(enforcer.UpdateContribution, enforcer.UpdateContributionStatus))
 	group.PUT("/:id", h.update, middleware.RequirePermission(enforcer.UpdateContribution))
 	group.DELETE("/:id", h.delete, middleware.RequirePermission(enforcer.DeleteContribution))
 }
@@ -78,7 +82,8 @@ func (h *Handler) getById(context echo.Context) error {
 
 // @Tags Contributions
 // @Summary Create a contribution
-// @Description Create a contribution
+// @Description Submit a contribution, or save it as a draft only its author sees and submits later
+// @Description through the status endpoint
 // @Accept  json
 // @Produce  json
 // @Param body body contribution.ContributionCreateReq true "create"
@@ -91,6 +96,7 @@ func (h *Handler) create(context echo.Context) error {
 	if err != nil {
 		return apperror.HandleError(err, context)
 	}
+	body.IpAddress = context.RealIP()
 	result, err := h.service.Create(context.Request().Context(), body)
 	if err != nil {
 		return apperror.HandleError(err, context)
@@ -167,9 +173,31 @@ func (h *Handler) images(context echo.Context) error {
 	return context.JSON(http.StatusOK, images)
 }
 
+// @Tags Contributions
+// @Summary Get contribution timeline
+// @Description Status changes, edits, article versions and comments of a contribution, oldest first
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {array} contribution.TimelineEventRes
+// @Security ApiKeyAuth
+// @Router /contributions/{id}/timeline [get]
+func (h *Handler) timeline(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	events, err := h.service.Timeline(context.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, events)
+}
+
 // @Tags Contributions
 // @Summary Update contribution status
-// @Description Update contribution status
+// @Description Move the contribution along its workflow, the author submits, resubmits and withdraws
+// @Description and the coordinator reviews. A conflict lists the allowed transitions.
 // @Accept  json
 // @Produce  json
 // @Param id path int true "ID"
@@ -187,6 +215,7 @@ func (h *Handler) updateStatus(context echo.Context) error {
 	if err != nil {
 		return apperror.HandleError(err, context)
 	}
+	body.IpAddress = context.RealIP()
 	err = h.service.UpdateStatus(context.Request().Context(), id, body)
 	if err != nil {
 		return apperror.HandleError(err, context)
@@ -3,6 +3,9 @@ package contribution
 import (
 	"context"
 	"gorm.io/gorm"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/user"
+	"time"
 )
 
 type repository struct {
@@ -15,12 +18,23 @@ func InitializeRepository(db *gorm.DB) *repository {
 	}
 }
 
+// withTx runs the queries of the repository in the transaction of another service.
+func (r repository) withTx(tx *gorm.DB) *repository {
+	return &repository{db: tx}
+}
+
 func (r repository) FindById(ctx context.Context, id int) (*Entity, error) {
 	result := new(Entity)
 	db := r.db.WithContext(ctx).Preload("User").First(result, id)
 	return result, db.Error
 }
 
+func (r repository) FindUser(ctx context.Context, id int) (*user.Entity, error) {
+	result := new(user.Entity)
+	db := r.db.WithContext(ctx).First(result, id)
+	return result, db.Error
+}
+
 func (r repository) Find(ctx context.Context, query *IndexQuery) ([]*Entity, error) {
 	var results []*Entity
 	r.db.WithContext(ctx)
@@ -30,14 +44,30 @@ func (r repository) Find(ctx context.Context, query *IndexQuery) ([]*Entity, err
 	return results, db.Error
 }
 
-func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Create(entity)
-	return entity, db.Error
+// Create saves the contribution along with its timeline events.
+func (r repository) Create(ctx context.Context, entity *Entity, events []*EventEntity) (*Entity, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(entity).Error
+		if err != nil {
+			return err
+		}
+		return createEvents(tx, entity.Id, events)
+	})
+	return entity, err
 }
 
-func (r repository) Update(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Save(entity)
-	return entity, db.Error
+// Update saves the contribution along with its timeline events, the feedback dates are
+// only set through SetFeedbackGiven and SetFeedbackEscalated so a concurrent comment is
+// not overwritten.
+func (r repository) Update(ctx context.Context, entity *Entity, events []*EventEntity) (*Entity, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("FeedbackGivenAt", "FeedbackEscalatedAt").Save(entity).Error
+		if err != nil {
+			return err
+		}
+		return createEvents(tx, entity.Id, events)
+	})
+	return entity, err
 }
 
 func (r repository) Delete(ctx context.Context, id int) error {
@@ -48,22 +78,34 @@ func (r repository) DeleteImages(ctx context.Context, contributionId int) error
 	return r.db.WithContext(ctx).Where("contribution_id = ?", contributionId).Delete(&ImageEntity{}).Error
 }
 
-func (r repository) FindAndCount(ctx context.Context, query *IndexQuery) ([]*Entity, int64, error) {
+// FindAndCount lists the contributions matching the query, restricted by the access filter.
+func (r repository) FindAndCount(ctx context.Context, query *IndexQuery, filter *enforcer.SqlFilter, userId int, window feedbackWindow) ([]*Entity, int64, error) {
 	var entities []*Entity
 	builder := r.db.WithContext(ctx).Model(&Entity{})
+	if filter.Where != "" {
+		builder.Where(filter.Where, filter.Args...)
+	}
+	// drafts are only listed to their author
+	builder.Where("(contributions.status <> ? or contributions.user_id = ?)", Draft, userId)
 	if query.Status != "" {
 		builder.Where("contributions.status = ?", query.Status)
 	}
 	if query.FacultyId != nil {
-		builder.Joins("join users on users.id = contributions.user_id").
-			Where("users.faculty_id = ?", query.FacultyId)
+		builder.Where("contributions.faculty_id = ?", query.FacultyId)
 	}
 	if query.StudentId != nil {
-		builder.Where("user_id = ?", query.StudentId)
+		builder.Where("contributions.user_id = ?", query.StudentId)
 	}
 	if query.ContributionSessionId != nil {
 		builder.Where("contribute_session_id = ?", query.ContributionSessionId)
 	}
+	switch query.Feedback {
+	case FeedbackOverdue:
+		builder.Where(feedbackPendingPredicate+" and contributions.feedback_due_at <= ?", window.now)
+	case FeedbackAtRisk:
+		builder.Where(feedbackPendingPredicate+" and contributions.feedback_due_at > ? and contributions.feedback_due_at <= ?",
+			window.now, window.atRiskUntil)
+	}
 	var count int64
 	result := builder.Count(&count)
 	if result.Error != nil {
@@ -74,6 +116,44 @@ func (r repository) FindAndCount(ctx context.Context, query *IndexQuery) ([]*Ent
 	return entities, count, result.Error
 }
 
+// feedbackPendingPredicate matches the contributions a coordinator still has to comment on.
+const feedbackPendingPredicate = "contributions.feedback_given_at is null and contributions.status not in ('draft', 'withdrawn')"
+
+// SetFeedbackGiven records the first coordinator comment, later comments keep the first date.
+func (r repository) SetFeedbackGiven(ctx context.Context, id int, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Entity{}).
+		Where("id = ? and feedback_given_at is null", id).
+		UpdateColumn("feedback_given_at", at).Error
+}
+
+// FindFeedbackToEscalate lists the overdue contributions not escalated yet.
+func (r repository) FindFeedbackToEscalate(ctx context.Context, now time.Time) ([]*Entity, error) {
+	var entities []*Entity
+	result := r.db.WithContext(ctx).
+		Preload("User").
+		Where(feedbackPendingPredicate+" and contributions.feedback_escalated_at is null and contributions.feedback_due_at <= ?", now).
+		Order("feedback_due_at").
+		Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) SetFeedbackEscalated(ctx context.Context, id int, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Entity{}).
+		Where("id = ?", id).
+		UpdateColumn("feedback_escalated_at", at).Error
+}
+
+// CountOfUserInSession counts the contributions a student submitted to the session,
+// drafts and withdrawn contributions do not count.
+func (r repository) CountOfUserInSession(ctx context.Context, userId int, contributeSessionId int) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&Entity{}).
+		Where("user_id = ? and contribute_session_id = ?", userId, contributeSessionId).
+		Where("status not in ?", []Status{Draft, Withdrawn}).
+		Count(&count)
+	return count, result.Error
+}
+
 func (r repository) GetImagesById(ctx context.Context, id int) ([]*ImageEntity, error) {
 	var entities []*ImageEntity
 	result := r.db.WithContext(ctx).Where("contribution_id = ?", id).Find(&entities)
@@ -83,7 +163,28 @@ func (r repository) GetImagesById(ctx context.Context, id int) ([]*ImageEntity,
 func (r repository) GetAllAcceptedContributions(ctx context.Context, contributeSessionId int) ([]*Entity, error) {
 	var entities []*Entity
 	result := r.db.WithContext(ctx).
-		Where("status = ? and contribute_session_id = ?", Accepted, contributeSessionId).
+		Where("status in ? and contribute_session_id = ?", publishedStatuses, contributeSessionId).
 		Find(&entities)
 	return entities, result.Error
 }
+
+func createEvents(tx *gorm.DB, contributionId int, events []*EventEntity) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, e := range events {
+		e.ContributionId = contributionId
+	}
+	return tx.Create(events).Error
+}
+
+// FindEvents lists the timeline of a contribution, oldest first.
+func (r repository) FindEvents(ctx context.Context, contributionId int) ([]*EventEntity, error) {
+	var entities []*EventEntity
+	db := r.db.WithContext(ctx).
+		Preload("Actor").
+		Where("contribution_id = ?", contributionId).
+		Order("created_at, id").
+		Find(&entities)
+	return entities, db.Error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"mcm-api/config"
@@ -12,12 +13,23 @@ import (
 	"mcm-api/pkg/common"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/log"
 	"mcm-api/pkg/media"
 	"mcm-api/pkg/queue"
+	"mcm-api/pkg/systemdata"
+	"mcm-api/pkg/user"
+	"strings"
 	"time"
 )
 
+// accessMapping tells the enforcer where the attributes of a contribution are stored.
+var accessMapping = enforcer.SqlMapping{
+	OwnerColumn:        "contributions.user_id",
+	FacultyColumn:      "contributions.faculty_id",
+	PublishedPredicate: "contributions.status in ('accepted', 'selected')",
+}
+
 type Service struct {
 	cfg                      *config.Config
 	repository               *repository
@@ -25,6 +37,8 @@ type Service struct {
 	contributeSessionService *contributesession.Service
 	articleService           *article.Service
 	mediaService             media.Service
+	facultyService           *faculty.Service
+	systemDataService        *systemdata.Service
 }
 
 func InitializeService(
@@ -34,6 +48,8 @@ func InitializeService(
 	cs *contributesession.Service,
 	articleService *article.Service,
 	mediaService media.Service,
+	facultyService *faculty.Service,
+	systemDataService *systemdata.Service,
 ) *Service {
 	return &Service{
 		queue:                    queue,
@@ -42,6 +58,8 @@ func InitializeService(
 		contributeSessionService: cs,
 		articleService:           articleService,
 		mediaService:             mediaService,
+		facultyService:           facultyService,
+		systemDataService:        systemDataService,
 	}
 }
 
@@ -50,58 +68,17 @@ func (s Service) Find(ctx context.Context, query *IndexQuery) (*common.PaginateR
 	if err != nil {
 		return nil, err
 	}
-	var result []*Entity
-	var count int64
-	switch loggedInUser.Role {
-	case enforcer.MarketingManager:
-		result, count, err = s.repository.FindAndCount(ctx, &IndexQuery{
-			PaginateQuery:         query.PaginateQuery,
-			FacultyId:             query.FacultyId,
-			StudentId:             query.StudentId,
-			ContributionSessionId: query.ContributionSessionId,
-			Status:                Accepted,
-		})
-		if err != nil {
-			return nil, err
-		}
-		break
-	case enforcer.Guest:
-		result, count, err = s.repository.FindAndCount(ctx, &IndexQuery{
-			PaginateQuery:         query.PaginateQuery,
-			FacultyId:             loggedInUser.FacultyId,
-			StudentId:             query.StudentId,
-			ContributionSessionId: query.ContributionSessionId,
-			Status:                Accepted,
-		})
-		if err != nil {
-			return nil, err
-		}
-		break
-	case enforcer.MarketingCoordinator:
-		result, count, err = s.repository.FindAndCount(ctx, &IndexQuery{
-			PaginateQuery:         query.PaginateQuery,
-			FacultyId:             loggedInUser.FacultyId,
-			StudentId:             query.StudentId,
-			ContributionSessionId: query.ContributionSessionId,
-			Status:                query.Status,
-		})
-		if err != nil {
-			return nil, err
-		}
-		break
-	case enforcer.Student:
-		result, count, err = s.repository.FindAndCount(ctx, &IndexQuery{
-			PaginateQuery:         query.PaginateQuery,
-			FacultyId:             loggedInUser.FacultyId,
-			StudentId:             &loggedInUser.Id,
-			ContributionSessionId: query.ContributionSessionId,
-			Status:                query.Status,
-		})
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, apperror.New(apperror.ErrForbidden, "", nil)
+	err = s.checkGuestAccess(ctx, loggedInUser)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := enforcer.Filter(*loggedInUser, enforcer.ReadContribution, accessMapping)
+	if err != nil {
+		return nil, err
+	}
+	result, count, err := s.repository.FindAndCount(ctx, query, filter, loggedInUser.Id, s.feedbackWindow(time.Now()))
+	if err != nil {
+		return nil, err
 	}
 
 	return common.NewPaginateResponse(
@@ -113,13 +90,76 @@ func (s Service) Find(ctx context.Context, query *IndexQuery) (*common.PaginateR
 }
 
 func (s Service) FindById(ctx context.Context, id int) (*ContributionRes, error) {
-	entity, err := s.findById(ctx, id)
+	entity, err := s.findAuthorized(ctx, id, enforcer.ReadContribution)
 	if err != nil {
 		return nil, err
 	}
 	return mapContributionToRes(entity), nil
 }
 
+// findAuthorized loads a contribution and checks the logged in user holds the permission on it.
+func (s Service) findAuthorized(ctx context.Context, id int, permission enforcer.Permission) (*Entity, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkGuestAccess(ctx, loggedInUser)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// drafts are private to their author until submitted
+	if entity.Status == Draft && entity.UserId != loggedInUser.Id {
+		return nil, apperror.New(apperror.ErrNotFound, "contribution not found", nil)
+	}
+	if !enforcer.Enforce(*loggedInUser, permission, entity) {
+		return nil, apperror.New(apperror.ErrForbidden, "you are not allowed to access this contribution", nil)
+	}
+	return entity, nil
+}
+
+// checkGuestAccess rejects the guests of a faculty that turned guest access off.
+func (s Service) checkGuestAccess(ctx context.Context, loggedInUser *enforcer.LoggedInUser) error {
+	if loggedInUser.Role != enforcer.Guest || loggedInUser.FacultyId == nil {
+		return nil
+	}
+	f, err := s.facultyService.FindById(ctx, *loggedInUser.FacultyId)
+	if err != nil {
+		return err
+	}
+	if !f.Settings.GuestAccess {
+		return apperror.New(apperror.ErrForbidden, "guest access to your faculty is disabled", nil)
+	}
+	return nil
+}
+
+// checkContributionLimit applies the maximum contributions per student in a session
+// set by the faculty of the author.
+func (s Service) checkContributionLimit(ctx context.Context, author *user.Entity, sessionId int) error {
+	if author.FacultyId == nil {
+		return nil
+	}
+	f, err := s.facultyService.FindById(ctx, *author.FacultyId)
+	if err != nil {
+		return err
+	}
+	if f.Settings.MaxContributionsPerStudent == nil {
+		return nil
+	}
+	count, err := s.repository.CountOfUserInSession(ctx, author.Id, sessionId)
+	if err != nil {
+		return err
+	}
+	if count >= int64(*f.Settings.MaxContributionsPerStudent) {
+		return apperror.New(apperror.ErrForbidden,
+			fmt.Sprintf("you can submit at most %v contributions in this session", *f.Settings.MaxContributionsPerStudent), nil)
+	}
+	return nil
+}
+
 func (s Service) findById(ctx context.Context, id int) (*Entity, error) {
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
@@ -131,12 +171,22 @@ func (s Service) findById(ctx context.Context, id int) (*Entity, error) {
 	return entity, nil
 }
 
+// Create submits a contribution, or saves it as a draft submitted later through UpdateStatus.
 func (s Service) Create(ctx context.Context, body *ContributionCreateReq) (*ContributionRes, error) {
 	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
 	if err != nil {
 		return nil, err
 	}
-	session, err := s.contributeSessionService.GetCurrentSession(ctx)
+	err = body.Validate()
+	if err != nil {
+		return nil, err
+	}
+	// the faculty of the token may be outdated after a transfer
+	author, err := s.repository.FindUser(ctx, loggedInUser.Id)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.contributeSessionService.GetCurrentSession(ctx, author.FacultyId)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +194,19 @@ func (s Service) Create(ctx context.Context, body *ContributionCreateReq) (*Cont
 	if now.After(session.ClosureTime) {
 		return nil, apperror.New(apperror.ErrForbidden, "cant create new contribution after closure time", nil)
 	}
+	status := Submitted
+	if body.Draft {
+		status = Draft
+	} else {
+		err = s.checkContributionLimit(ctx, author, session.Id)
+		if err != nil {
+			return nil, err
+		}
+		err = s.systemDataService.CheckTermVersion(ctx, body.AcceptedTermVersionId)
+		if err != nil {
+			return nil, err
+		}
+	}
 	var a *article.ArticleRes
 	if body.Article != nil {
 		a, err = s.articleService.Create(ctx, &article.ArticleReq{
@@ -156,23 +219,50 @@ func (s Service) Create(ctx context.Context, body *ContributionCreateReq) (*Cont
 	entity := &Entity{
 		UserId:              loggedInUser.Id,
 		ContributeSessionId: session.Id,
+		FacultyId:           author.FacultyId,
 		Title:               body.Title,
 		Description:         body.Description,
-		Status:              Reviewing,
+		Status:              status,
 		Images:              mapImageReqToEntity(body.Images...),
+		FeedbackDueAt:       now.AddDate(0, 0, s.feedbackDeadlineDays()),
 	}
 	if a != nil {
 		entity.ArticleId = &a.Id
 	}
-	entity, err = s.repository.Create(ctx, entity)
+	events := []*EventEntity{
+		newEvent(loggedInUser, entity.Id, EventCreated, "", string(entity.Status)),
+	}
+	if entity.Status == Submitted {
+		// a submission is only saved along with the acceptance of the term and condition
+		err = s.systemDataService.AcceptTermAndCondition(ctx, &systemdata.TermAcceptance{
+			UserId:         loggedInUser.Id,
+			VersionId:      body.AcceptedTermVersionId,
+			ContributionId: &entity.Id,
+			IpAddress:      body.IpAddress,
+		}, func(tx *gorm.DB) error {
+			_, err := s.repository.withTx(tx).Create(ctx, entity, events)
+			return err
+		})
+	} else {
+		entity, err = s.repository.Create(ctx, entity, events)
+	}
 	if err != nil {
 		return nil, err
 	}
-	go s.addToQueue(*loggedInUser, entity)
+	entity.User = *author
+	if entity.Status == Submitted {
+		go s.addToQueue(*loggedInUser, entity)
+	}
 	return mapContributionToRes(entity), nil
 }
 
 func (s Service) addToQueue(user enforcer.LoggedInUser, contribution *Entity) {
+	// the coordinators to notify are those of the faculty, without one nobody is notified
+	if contribution.FacultyId == nil {
+		log.Logger.Warn("contribution has no faculty, coordinators are not notified",
+			zap.Int("contributionId", contribution.Id))
+		return
+	}
 	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*2)
 	defer cancelFunc()
 	err := s.queue.Add(ctx, &queue.Message{
@@ -181,7 +271,7 @@ func (s Service) addToQueue(user enforcer.LoggedInUser, contribution *Entity) {
 			ContributionId: contribution.Id,
 			UserId:         user.Id,
 			UserName:       user.Name,
-			FacultyId:      *user.FacultyId,
+			FacultyId:      *contribution.FacultyId,
 			User:           user,
 		},
 	})
@@ -191,8 +281,11 @@ func (s Service) addToQueue(user enforcer.LoggedInUser, contribution *Entity) {
 }
 
 func (s Service) Update(ctx context.Context, id int, body *ContributionUpdateReq) (*ContributionRes, error) {
-	entity, err := s.findById(ctx, id)
-	fmt.Println(entity)
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.findAuthorized(ctx, id, enforcer.UpdateContribution)
 	if err != nil {
 		return nil, err
 	}
@@ -203,24 +296,70 @@ func (s Service) Update(ctx context.Context, id int, body *ContributionUpdateReq
 	if time.Now().After(session.FinalClosureTime) {
 		return nil, apperror.New(apperror.ErrForbidden, "contribution session ended", nil)
 	}
+	if !entity.Status.IsEditable() {
+		return nil, apperror.New(apperror.ErrConflict,
+			fmt.Sprintf("contribution cannot be edited while it is %v", entity.Status), nil)
+	}
+	if entity.Status == Draft {
+		err = body.ValidateDraft()
+	} else {
+		err = body.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []*EventEntity
 	if body.Article != nil {
-		_, err = s.articleService.Update(ctx, *entity.ArticleId, article.ArticleReq{
-			Link: body.Article.Link,
-		})
-		if err != nil {
-			return nil, err
+		// a draft may be saved before its article is uploaded
+		if entity.ArticleId == nil {
+			a, err := s.articleService.Create(ctx, &article.ArticleReq{
+				Link: body.Article.Link,
+			})
+			if err != nil {
+				return nil, err
+			}
+			entity.ArticleId = &a.Id
+		} else {
+			_, err = s.articleService.Update(ctx, *entity.ArticleId, article.ArticleReq{
+				Link: body.Article.Link,
+			})
+			if err != nil {
+				return nil, err
+			}
 		}
+		events = append(events, newEvent(loggedInUser, entity.Id, EventArticleVersionUploaded, "", body.Article.Link))
 	}
 	if body.Images != nil {
+		previousImages, err := s.repository.GetImagesById(ctx, entity.Id)
+		if err != nil {
+			return nil, err
+		}
 		err = s.repository.DeleteImages(ctx, entity.Id)
 		if err != nil {
 			return nil, err
 		}
 		entity.Images = mapImageReqToEntity(body.Images...)
+		var before, after []string
+		for _, v := range previousImages {
+			before = append(before, v.Key)
+		}
+		for _, v := range entity.Images {
+			after = append(after, v.Key)
+		}
+		if strings.Join(before, ",") != strings.Join(after, ",") {
+			events = append(events, newEvent(loggedInUser, entity.Id, EventImagesChanged,
+				strings.Join(before, ","), strings.Join(after, ",")))
+		}
+	}
+	if entity.Title != body.Title {
+		events = append(events, newEvent(loggedInUser, entity.Id, EventTitleChanged, entity.Title, body.Title))
+	}
+	if entity.Description != body.Description {
+		events = append(events, newEvent(loggedInUser, entity.Id, EventDescriptionChanged, entity.Description, body.Description))
 	}
 	entity.Title = body.Title
 	entity.Description = body.Description
-	_, err = s.repository.Update(ctx, entity)
+	_, err = s.repository.Update(ctx, entity, events)
 	if err != nil {
 		return nil, err
 	}
@@ -228,6 +367,10 @@ func (s Service) Update(ctx context.Context, id int, body *ContributionUpdateReq
 }
 
 func (s Service) Delete(ctx context.Context, id int) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -235,6 +378,9 @@ func (s Service) Delete(ctx context.Context, id int) error {
 		}
 		return err
 	}
+	if !enforcer.Enforce(*loggedInUser, enforcer.DeleteContribution, entity) {
+		return apperror.New(apperror.ErrForbidden, "you are not allowed to delete this contribution", nil)
+	}
 	session, err := s.contributeSessionService.FindById(ctx, entity.ContributeSessionId)
 	if err != nil {
 		return err
@@ -250,10 +396,17 @@ func (s Service) Delete(ctx context.Context, id int) error {
 	if err != nil {
 		return err
 	}
+	if entity.ArticleId == nil {
+		return nil
+	}
 	return s.articleService.Delete(ctx, *entity.ArticleId)
 }
 
 func (s Service) GetImages(ctx context.Context, id int) ([]*ImageRes, error) {
+	_, err := s.findAuthorized(ctx, id, enforcer.ReadContribution)
+	if err != nil {
+		return nil, err
+	}
 	entities, err := s.repository.GetImagesById(ctx, id)
 	if err != nil {
 		return nil, err
@@ -273,6 +426,7 @@ func (s Service) GetAllAcceptedContributions(ctx context.Context, contributeSess
 	return s.repository.GetAllAcceptedContributions(ctx, contributeSessionId)
 }
 
+// UpdateStatus moves the contribution along the workflow, see transitions.
 func (s Service) UpdateStatus(ctx context.Context, id int, body *ContributionStatusReq) error {
 	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
 	if err != nil {
@@ -281,21 +435,220 @@ func (s Service) UpdateStatus(ctx context.Context, id int, body *ContributionSta
 	if err = body.Validate(); err != nil {
 		return err
 	}
-	entity, err := s.repository.FindById(ctx, id)
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return err
+	}
+	actors := actorsOf(*loggedInUser, entity)
+	if len(actors) == 0 {
+		return apperror.New(apperror.ErrForbidden, "you are not allowed to change the status of this contribution", nil)
+	}
+	session, err := s.contributeSessionService.FindById(ctx, entity.ContributeSessionId)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil
-		}
 		return err
 	}
-	fmt.Println(loggedInUser)
-	fmt.Println(entity.User)
-	if *loggedInUser.FacultyId != *entity.User.FacultyId {
-		return apperror.New(apperror.ErrForbidden, "cant not change status of other faculty", nil)
+	allowed := allowedTransitions(entity.Status, actors, session, time.Now())
+	if !hasStatus(allowed, body.Status) {
+		return apperror.New(apperror.ErrConflict,
+			fmt.Sprintf("contribution cannot move from %v to %v", entity.Status, body.Status), nil).
+			WithData(map[string]interface{}{
+				"status":             entity.Status,
+				"allowedTransitions": allowed,
+			})
+	}
+	submission := entity.Status == Draft && body.Status == Submitted
+	if submission {
+		err = s.checkSubmission(ctx, entity)
+		if err != nil {
+			return err
+		}
+		entity.FeedbackDueAt = time.Now().AddDate(0, 0, s.feedbackDeadlineDays())
 	}
+	event := newEvent(loggedInUser, entity.Id, EventStatusChanged, string(entity.Status), string(body.Status))
 	entity.Status = body.Status
-	_, err = s.repository.Update(ctx, entity)
-	return err
+	entity.StatusReason = nil
+	if reasonRequired(body.Status) {
+		entity.StatusReason = &body.Reason
+		event.Note = &body.Reason
+	}
+	if submission {
+		// the draft is only submitted along with the acceptance of the term and condition
+		err = s.systemDataService.AcceptTermAndCondition(ctx, &systemdata.TermAcceptance{
+			UserId:         loggedInUser.Id,
+			VersionId:      body.AcceptedTermVersionId,
+			ContributionId: &entity.Id,
+			IpAddress:      body.IpAddress,
+		}, func(tx *gorm.DB) error {
+			_, err := s.repository.withTx(tx).Update(ctx, entity, []*EventEntity{event})
+			return err
+		})
+	} else {
+		_, err = s.repository.Update(ctx, entity, []*EventEntity{event})
+	}
+	if err != nil {
+		return err
+	}
+	if submission {
+		go s.addToQueue(*loggedInUser, entity)
+	}
+	return nil
+}
+
+// checkSubmission makes sure a draft is complete and within the limit of its faculty
+// before it is submitted.
+func (s Service) checkSubmission(ctx context.Context, entity *Entity) error {
+	// the same rules as a submission through Create
+	err := validation.Errors{
+		"title":       validation.Validate(entity.Title, titleRule(false)),
+		"description": validation.Validate(entity.Description, descriptionRule(false)),
+	}.Filter()
+	if err != nil {
+		return err
+	}
+	if entity.ArticleId == nil {
+		images, err := s.repository.GetImagesById(ctx, entity.Id)
+		if err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			return apperror.New(apperror.ErrInvalid, "an article or images are required", nil)
+		}
+	}
+	author, err := s.repository.FindUser(ctx, entity.UserId)
+	if err != nil {
+		return err
+	}
+	return s.checkContributionLimit(ctx, author, entity.ContributeSessionId)
+}
+
+// NewCommentEvent creates the timeline event of a comment, the comment repository saves
+// it along with the comment and sets the comment id.
+func NewCommentEvent(actor *enforcer.LoggedInUser, contributionId int) *EventEntity {
+	return newEvent(actor, contributionId, EventCommented, "", "")
+}
+
+// Timeline lists what happened to the contribution: status changes, edits, article
+// versions and comments, oldest first.
+func (s Service) Timeline(ctx context.Context, id int) ([]*TimelineEventRes, error) {
+	_, err := s.findAuthorized(ctx, id, enforcer.ReadContribution)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := s.repository.FindEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*TimelineEventRes, 0, len(entities))
+	for _, e := range entities {
+		res := &TimelineEventRes{
+			Id:        e.Id,
+			Type:      e.Type,
+			Before:    e.Before,
+			After:     e.After,
+			Note:      e.Note,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.Actor != nil {
+			res.Actor = &UserRes{
+				Id:        e.Actor.Id,
+				Name:      e.Actor.Name,
+				Email:     e.Actor.Email,
+				FacultyId: e.Actor.FacultyId,
+				Role:      e.Actor.Role,
+			}
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
+// newEvent creates a timeline event acted by the logged in user, an empty before or
+// after is stored as null.
+func newEvent(actor *enforcer.LoggedInUser, contributionId int, eventType EventType, before string, after string) *EventEntity {
+	event := &EventEntity{
+		ContributionId: contributionId,
+		ActorId:        &actor.Id,
+		Type:           eventType,
+	}
+	if before != "" {
+		event.Before = &before
+	}
+	if after != "" {
+		event.After = &after
+	}
+	return event
+}
+
+func actorsOf(loggedInUser enforcer.LoggedInUser, entity *Entity) []actor {
+	var result []actor
+	if entity.UserId == loggedInUser.Id && enforcer.Enforce(loggedInUser, enforcer.UpdateContribution, entity) {
+		result = append(result, author)
+	}
+	if enforcer.Enforce(loggedInUser, enforcer.UpdateContributionStatus, entity) {
+		result = append(result, reviewer)
+	}
+	return result
+}
+
+// feedbackWindow is when the feedback of a pending contribution is overdue or at risk.
+type feedbackWindow struct {
+	now         time.Time
+	atRiskUntil time.Time
+}
+
+func (s Service) feedbackWindow(now time.Time) feedbackWindow {
+	days := s.cfg.FeedbackAtRiskDays
+	if days <= 0 {
+		days = 3
+	}
+	return feedbackWindow{now: now, atRiskUntil: now.AddDate(0, 0, days)}
+}
+
+func (s Service) feedbackDeadlineDays() int {
+	if s.cfg.FeedbackDeadlineDays <= 0 {
+		return 14
+	}
+	return s.cfg.FeedbackDeadlineDays
+}
+
+// MarkFeedbackGiven satisfies the feedback deadline of the contribution, it is called
+// when a reviewer of the contribution comments on it.
+func (s Service) MarkFeedbackGiven(ctx context.Context, id int) error {
+	return s.repository.SetFeedbackGiven(ctx, id, time.Now())
+}
+
+// EscalateOverdueFeedback queues an escalation for each contribution that passed its
+// feedback deadline, the worker emails the coordinators and the marketing managers.
+// A contribution is escalated once.
+func (s Service) EscalateOverdueFeedback(ctx context.Context) error {
+	now := time.Now()
+	entities, err := s.repository.FindFeedbackToEscalate(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if entity.FacultyId == nil {
+			continue
+		}
+		err = s.queue.Add(ctx, &queue.Message{
+			Topic: queue.FeedbackOverdue,
+			Data: &queue.FeedbackOverduePayload{
+				ContributionId: entity.Id,
+				Title:          entity.Title,
+				StudentName:    entity.User.Name,
+				FacultyId:      *entity.FacultyId,
+				FeedbackDueAt:  entity.FeedbackDueAt.Format("02 Jan 2006 15:04 MST"),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		err = s.repository.SetFeedbackEscalated(ctx, entity.Id, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func mapImageReqToEntity(images ...ImageCreateReq) []ImageEntity {
@@ -320,10 +673,14 @@ func mapContributionToRes(c *Entity) *ContributionRes {
 			Role:      c.User.Role,
 		},
 		ContributeSessionId: c.ContributeSessionId,
+		FacultyId:           c.FacultyId,
 		ArticleId:           c.ArticleId,
 		Title:               c.Title,
 		Description:         c.Description,
 		Status:              c.Status,
+		StatusReason:        c.StatusReason,
+		FeedbackDueAt:       c.FeedbackDueAt,
+		FeedbackGivenAt:     c.FeedbackGivenAt,
 		TrackTime: common.TrackTime{
 			CreatedAt: c.CreatedAt,
 			UpdatedAt: c.UpdatedAt,
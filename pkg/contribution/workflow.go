@@ -0,0 +1,97 @@
+package contribution
+
+import (
+	"mcm-api/pkg/contributesession"
+	"time"
+)
+
+// actor is the part a user plays on a contribution, a user may play both.
+type actor string
+
+const (
+	// author is the student who owns the contribution
+	author actor = "author"
+	// reviewer holds enforcer.UpdateContributionStatus on the contribution
+	reviewer actor = "reviewer"
+)
+
+// deadline is the last moment of the session a transition may happen.
+type deadline int
+
+const (
+	anytime deadline = iota
+	untilClosure
+	untilFinalClosure
+)
+
+func (d deadline) passed(session *contributesession.SessionRes, now time.Time) bool {
+	switch d {
+	case untilClosure:
+		return !now.Before(session.ClosureTime)
+	case untilFinalClosure:
+		return !now.Before(session.FinalClosureTime)
+	}
+	return false
+}
+
+type transition struct {
+	from     Status
+	to       Status
+	by       actor
+	deadline deadline
+}
+
+// transitions is the workflow of a contribution. New article versions are only
+// possible until the final closure, so requesting changes and resubmitting stop there.
+var transitions = []transition{
+	{Draft, Submitted, author, untilClosure},
+	{Draft, Withdrawn, author, anytime},
+	{Submitted, UnderReview, reviewer, anytime},
+	{Submitted, Withdrawn, author, untilClosure},
+	{UnderReview, ChangesRequested, reviewer, untilFinalClosure},
+	{UnderReview, Accepted, reviewer, anytime},
+	{UnderReview, Rejected, reviewer, anytime},
+	{ChangesRequested, Resubmitted, author, untilFinalClosure},
+	{ChangesRequested, Withdrawn, author, untilFinalClosure},
+	{Resubmitted, UnderReview, reviewer, anytime},
+	{Resubmitted, Withdrawn, author, untilFinalClosure},
+	{Accepted, UnderReview, reviewer, untilFinalClosure},
+	{Rejected, UnderReview, reviewer, untilFinalClosure},
+	{Accepted, Selected, reviewer, anytime},
+	{Selected, Accepted, reviewer, anytime},
+}
+
+// allowedTransitions lists the statuses the actors may move the contribution to at the
+// given time of its session.
+func allowedTransitions(from Status, actors []actor, session *contributesession.SessionRes, now time.Time) []Status {
+	result := []Status{}
+	for _, t := range transitions {
+		if t.from != from || !hasActor(actors, t.by) || t.deadline.passed(session, now) {
+			continue
+		}
+		result = append(result, t.to)
+	}
+	return result
+}
+
+func reasonRequired(to Status) bool {
+	return to == Rejected || to == ChangesRequested
+}
+
+func hasActor(actors []actor, a actor) bool {
+	for _, v := range actors {
+		if v == a {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStatus(statuses []Status, s Status) bool {
+	for _, v := range statuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package contribution
+
+import (
+	"mcm-api/pkg/contributesession"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAllowedTransitions(t *testing.T) {
+	day := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	session := &contributesession.SessionRes{
+		OpenTime:         day,
+		ClosureTime:      day.AddDate(0, 0, 10),
+		FinalClosureTime: day.AddDate(0, 0, 20),
+	}
+	beforeClosure := day.AddDate(0, 0, 5)
+	atClosure := session.ClosureTime
+	atFinalClosure := session.FinalClosureTime
+	cases := []struct {
+		name     string
+		from     Status
+		actors   []actor
+		now      time.Time
+		expected []Status
+	}{
+		{"author submits a draft", Draft, []actor{author}, beforeClosure, []Status{Submitted, Withdrawn}},
+		{"author cannot submit a draft at closure", Draft, []actor{author}, atClosure, []Status{Withdrawn}},
+		{"reviewer cannot touch a draft", Draft, []actor{reviewer}, beforeClosure, []Status{}},
+		{"author withdraws before closure", Submitted, []actor{author}, beforeClosure, []Status{Withdrawn}},
+		{"author cannot withdraw at closure", Submitted, []actor{author}, atClosure, []Status{}},
+		{"reviewer decides", UnderReview, []actor{reviewer}, atClosure, []Status{ChangesRequested, Accepted, Rejected}},
+		{"no changes requested at final closure", UnderReview, []actor{reviewer}, atFinalClosure, []Status{Accepted, Rejected}},
+		{"author resubmits", ChangesRequested, []actor{author}, atClosure, []Status{Resubmitted, Withdrawn}},
+		{"no resubmission at final closure", ChangesRequested, []actor{author}, atFinalClosure, []Status{}},
+		{"author and reviewer", Resubmitted, []actor{author, reviewer}, beforeClosure, []Status{UnderReview, Withdrawn}},
+		{"selection after final closure", Accepted, []actor{reviewer}, atFinalClosure, []Status{Selected}},
+		{"withdrawn is final", Withdrawn, []actor{author, reviewer}, beforeClosure, []Status{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := allowedTransitions(c.from, c.actors, session, c.now)
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
@@ -1,9 +1,35 @@
 package enforcer
 
+import (
+	"mcm-api/pkg/apperror"
+)
+
+// Enforce checks that the role of the subject is granted the permission and, when a
+// resource is given, that the resource matches the condition of the grant.
 func Enforce(subject LoggedInUser, permission Permission, object ...interface{}) bool {
+	if !subject.InScope(permission) {
+		return false
+	}
+	r, ok := getRole(subject.Role)
+	if !ok || !r.permissions[permission] {
+		return false
+	}
 	if len(object) == 0 {
-		return Can(subject.Role, permission)
+		return true
 	}
+	return r.conditions[permission].Evaluate(subject, object[0])
+}
 
-	return Can(subject.Role, permission) && AbacEvaluate(subject, permission, object[0])
+// Filter turns the condition of a grant into a where clause, so list queries only
+// return the resources Enforce would allow.
+func Filter(subject LoggedInUser, permission Permission, mapping SqlMapping) (*SqlFilter, error) {
+	r, ok := getRole(subject.Role)
+	if !ok || !r.permissions[permission] || !subject.InScope(permission) {
+		return nil, apperror.New(apperror.ErrForbidden, "", nil)
+	}
+	filter, err := r.conditions[permission].Sql(subject, mapping)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "grant condition cannot be applied", err)
+	}
+	return filter, nil
 }
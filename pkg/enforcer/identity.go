@@ -12,6 +12,26 @@ type LoggedInUser struct {
 	Name      string
 	Role      Role
 	FacultyId *int
+	SessionId string
+	// ImpersonatedBy is the id of the administrator acting as this user, nil outside impersonation
+	ImpersonatedBy *int
+	// ApiKeyId is set when the request is authenticated with an api key instead of a session
+	ApiKeyId *int
+	// Scopes limit the permissions of the role for api keys, nil means every permission of the role
+	Scopes []Permission
+}
+
+// InScope tells whether the credential used for the request may use the permission at all.
+func (u LoggedInUser) InScope(permission Permission) bool {
+	if u.Scopes == nil {
+		return true
+	}
+	for _, p := range u.Scopes {
+		if p == permission {
+			return true
+		}
+	}
+	return false
 }
 
 const contextKey = "user"
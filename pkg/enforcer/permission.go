@@ -1,39 +1,123 @@
 package enforcer
 
-type Permission int
+// Permission identifiers are stored in the role_permissions table,
+// never rename an existing one.
+type Permission string
 
 const (
-	ReadUser Permission = iota
-	CreateUser
-	UpdateUser
-	DeleteUser
-
-	ReadFaculty
-	CreateFaculty
-	UpdateFaculty
-	DeleteFaculty
-
-	ReadContributeSession
-	CreateContributeSession
-	UpdateContributeSession
-	DeleteContributeSession
-	ExportContributeSession
-
-	CreateMedia
-
-	ReadContribution
-	CreateContribution
-	UpdateContribution
-	DeleteContribution
-	UpdateContributionStatus
-
-	ReadComment
-	CreateComment
-	UpdateComment
-	DeleteComment
-
-	ReadSystemData
-	UpdateSystemData
-
-	ReadStatistic
+	ReadUser   Permission = "user.read"
+	CreateUser Permission = "user.create"
+	UpdateUser Permission = "user.update"
+	DeleteUser Permission = "user.delete"
+	// act as another user, see authz.Service.Impersonate
+	ImpersonateUser Permission = "user.impersonate"
+	// export the personal data of a user, see privacy.Service.RequestExport
+	ExportUserData Permission = "user.export_data"
+	// anonymize a user, see privacy.Service.Erase
+	EraseUser Permission = "user.erase"
+
+	ReadFaculty   Permission = "faculty.read"
+	CreateFaculty Permission = "faculty.create"
+	UpdateFaculty Permission = "faculty.update"
+	DeleteFaculty Permission = "faculty.delete"
+
+	ReadContributeSession   Permission = "contribute_session.read"
+	CreateContributeSession Permission = "contribute_session.create"
+	UpdateContributeSession Permission = "contribute_session.update"
+	DeleteContributeSession Permission = "contribute_session.delete"
+	ExportContributeSession Permission = "contribute_session.export"
+
+	CreateMedia Permission = "media.create"
+
+	ReadContribution         Permission = "contribution.read"
+	CreateContribution       Permission = "contribution.create"
+	UpdateContribution       Permission = "contribution.update"
+	DeleteContribution       Permission = "contribution.delete"
+	UpdateContributionStatus Permission = "contribution.update_status"
+
+	ReadComment   Permission = "comment.read"
+	CreateComment Permission = "comment.create"
+	UpdateComment Permission = "comment.update"
+	DeleteComment Permission = "comment.delete"
+
+	ReadSystemData   Permission = "system_data.read"
+	UpdateSystemData Permission = "system_data.update"
+
+	ReadStatistic Permission = "statistic.read"
+
+	ReadRole   Permission = "role.read"
+	CreateRole Permission = "role.create"
+	UpdateRole Permission = "role.update"
+	DeleteRole Permission = "role.delete"
+
+	// create and revoke own api keys
+	CreateApiKey Permission = "api_key.create"
+	// manage api keys of every user and service accounts
+	ManageApiKey Permission = "api_key.manage"
 )
+
+var allPermissions = []Permission{
+	ReadUser, CreateUser, UpdateUser, DeleteUser, ImpersonateUser, ExportUserData, EraseUser,
+	ReadFaculty, CreateFaculty, UpdateFaculty, DeleteFaculty,
+	ReadContributeSession, CreateContributeSession, UpdateContributeSession, DeleteContributeSession, ExportContributeSession,
+	CreateMedia,
+	ReadContribution, CreateContribution, UpdateContribution, DeleteContribution, UpdateContributionStatus,
+	ReadComment, CreateComment, UpdateComment, DeleteComment,
+	ReadSystemData, UpdateSystemData,
+	ReadStatistic,
+	ReadRole, CreateRole, UpdateRole, DeleteRole,
+	CreateApiKey, ManageApiKey,
+}
+
+// permissions checked against a single contribution, comments are read and created on it
+var contributionPermissions = []Permission{
+	ReadContribution, UpdateContribution, DeleteContribution, UpdateContributionStatus,
+	ReadComment, CreateComment,
+}
+
+// permissions checked against a single contribute session, contributions are created in it
+var contributeSessionPermissions = []Permission{
+	ReadContributeSession, UpdateContributeSession, DeleteContributeSession, ExportContributeSession,
+	CreateContribution,
+}
+
+// AllPermissions lists every permission the code checks.
+func AllPermissions() []Permission {
+	result := make([]Permission, len(allPermissions))
+	copy(result, allPermissions)
+	return result
+}
+
+func IsPermission(permission Permission) bool {
+	for _, p := range allPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists the permissions granted to the role of the subject, regardless of resource conditions.
+func Permissions(subject LoggedInUser) []Permission {
+	return effectivePermissions(subject, allPermissions)
+}
+
+// ContributionPermissions lists what the subject may do with the contribution.
+func ContributionPermissions(subject LoggedInUser, contribution interface{}) []Permission {
+	return effectivePermissions(subject, contributionPermissions, contribution)
+}
+
+// ContributeSessionPermissions lists what the subject may do with the contribute session.
+func ContributeSessionPermissions(subject LoggedInUser, session interface{}) []Permission {
+	return effectivePermissions(subject, contributeSessionPermissions, session)
+}
+
+func effectivePermissions(subject LoggedInUser, candidates []Permission, object ...interface{}) []Permission {
+	result := make([]Permission, 0)
+	for _, p := range candidates {
+		if Enforce(subject, p, object...) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
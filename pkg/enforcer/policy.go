@@ -0,0 +1,310 @@
+package enforcer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Resources expose the attributes a grant condition can refer to. A resource only
+// implements the interfaces that make sense for it, a condition on a missing
+// attribute denies access.
+type (
+	// Owned resources belong to the user who created them
+	Owned interface {
+		ResourceOwner() int
+	}
+	// FacultyScoped resources belong to a faculty, nil when the faculty is unknown
+	FacultyScoped interface {
+		ResourceFaculty() *int
+	}
+	// Publishable resources may be visible beyond their faculty once published
+	Publishable interface {
+		ResourcePublished() bool
+	}
+)
+
+// Attribute is an atom of the condition language.
+type Attribute string
+
+const (
+	// the subject owns the resource
+	AttrOwner Attribute = "owner"
+	// the subject belongs to the faculty of the resource
+	AttrFaculty Attribute = "faculty"
+	// the resource is published
+	AttrPublished Attribute = "published"
+)
+
+// Condition is a compiled grant condition such as "faculty && published".
+// The grammar is
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | primary
+//	primary = attribute | "(" expr ")"
+type Condition struct {
+	source string
+	root   conditionNode
+}
+
+// ParseCondition compiles a condition, an empty source always holds.
+func ParseCondition(source string) (*Condition, error) {
+	if strings.TrimSpace(source) == "" {
+		return &Condition{}, nil
+	}
+	p := &conditionParser{tokens: tokenize(source)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in condition", p.tokens[p.pos])
+	}
+	return &Condition{source: source, root: root}, nil
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Evaluate checks the condition against one resource.
+func (c *Condition) Evaluate(subject LoggedInUser, resource interface{}) bool {
+	if c.root == nil {
+		return true
+	}
+	return c.root.evaluate(subject, resource)
+}
+
+// SqlMapping tells how the attributes of a resource are stored, so a condition can
+// be turned into a where clause for list queries.
+type SqlMapping struct {
+	OwnerColumn   string
+	FacultyColumn string
+	// a complete predicate, e.g. "contributions.status = 'accepted'"
+	PublishedPredicate string
+}
+
+// SqlFilter is a where clause with its arguments, an empty Where matches every row.
+type SqlFilter struct {
+	Where string
+	Args  []interface{}
+}
+
+func (c *Condition) Sql(subject LoggedInUser, mapping SqlMapping) (*SqlFilter, error) {
+	if c.root == nil {
+		return &SqlFilter{}, nil
+	}
+	where, args, err := c.root.sql(subject, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return &SqlFilter{Where: where, Args: args}, nil
+}
+
+type conditionNode interface {
+	evaluate(subject LoggedInUser, resource interface{}) bool
+	sql(subject LoggedInUser, mapping SqlMapping) (string, []interface{}, error)
+}
+
+type attributeNode Attribute
+
+func (n attributeNode) evaluate(subject LoggedInUser, resource interface{}) bool {
+	switch Attribute(n) {
+	case AttrOwner:
+		r, ok := resource.(Owned)
+		return ok && r.ResourceOwner() == subject.Id
+	case AttrFaculty:
+		r, ok := resource.(FacultyScoped)
+		if !ok || subject.FacultyId == nil {
+			return false
+		}
+		facultyId := r.ResourceFaculty()
+		return facultyId != nil && *facultyId == *subject.FacultyId
+	case AttrPublished:
+		r, ok := resource.(Publishable)
+		return ok && r.ResourcePublished()
+	default:
+		return false
+	}
+}
+
+func (n attributeNode) sql(subject LoggedInUser, mapping SqlMapping) (string, []interface{}, error) {
+	switch Attribute(n) {
+	case AttrOwner:
+		if mapping.OwnerColumn == "" {
+			return "", nil, errors.New("resource has no owner")
+		}
+		return mapping.OwnerColumn + " = ?", []interface{}{subject.Id}, nil
+	case AttrFaculty:
+		if mapping.FacultyColumn == "" {
+			return "", nil, errors.New("resource has no faculty")
+		}
+		if subject.FacultyId == nil {
+			return "false", nil, nil
+		}
+		return mapping.FacultyColumn + " = ?", []interface{}{*subject.FacultyId}, nil
+	case AttrPublished:
+		if mapping.PublishedPredicate == "" {
+			return "", nil, errors.New("resource cannot be published")
+		}
+		return mapping.PublishedPredicate, nil, nil
+	default:
+		return "", nil, fmt.Errorf("unknown attribute %q", string(n))
+	}
+}
+
+type notNode struct {
+	operand conditionNode
+}
+
+func (n notNode) evaluate(subject LoggedInUser, resource interface{}) bool {
+	return !n.operand.evaluate(subject, resource)
+}
+
+func (n notNode) sql(subject LoggedInUser, mapping SqlMapping) (string, []interface{}, error) {
+	where, args, err := n.operand.sql(subject, mapping)
+	if err != nil {
+		return "", nil, err
+	}
+	return "not (" + where + ")", args, nil
+}
+
+type binaryNode struct {
+	and         bool
+	left, right conditionNode
+}
+
+func (n binaryNode) evaluate(subject LoggedInUser, resource interface{}) bool {
+	if n.and {
+		return n.left.evaluate(subject, resource) && n.right.evaluate(subject, resource)
+	}
+	return n.left.evaluate(subject, resource) || n.right.evaluate(subject, resource)
+}
+
+func (n binaryNode) sql(subject LoggedInUser, mapping SqlMapping) (string, []interface{}, error) {
+	left, leftArgs, err := n.left.sql(subject, mapping)
+	if err != nil {
+		return "", nil, err
+	}
+	right, rightArgs, err := n.right.sql(subject, mapping)
+	if err != nil {
+		return "", nil, err
+	}
+	operator := " or "
+	if n.and {
+		operator = " and "
+	}
+	return "(" + left + operator + right + ")", append(leftArgs, rightArgs...), nil
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.peek() == "!" {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *conditionParser) parsePrimary() (conditionNode, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, errors.New("unexpected end of condition")
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing ) in condition")
+		}
+		p.pos++
+		return node, nil
+	}
+	switch Attribute(token) {
+	case AttrOwner, AttrFaculty, AttrPublished:
+		p.pos++
+		return attributeNode(token), nil
+	}
+	return nil, fmt.Errorf("unexpected %q in condition", token)
+}
+
+func tokenize(source string) []string {
+	var tokens []string
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '!':
+			tokens = append(tokens, string(r))
+			i++
+		case (r == '&' || r == '|') && i+1 < len(runes) && runes[i+1] == r:
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			// a single invalid character becomes its own token and fails parsing
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
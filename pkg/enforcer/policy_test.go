@@ -0,0 +1,323 @@
+package enforcer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testResource struct {
+	owner     int
+	faculty   *int
+	published bool
+}
+
+func (r testResource) ResourceOwner() int {
+	return r.owner
+}
+
+func (r testResource) ResourceFaculty() *int {
+	return r.faculty
+}
+
+func (r testResource) ResourcePublished() bool {
+	return r.published
+}
+
+// ownedOnly has an owner but neither faculty nor publication state
+type ownedOnly struct {
+	owner int
+}
+
+func (r ownedOnly) ResourceOwner() int {
+	return r.owner
+}
+
+const (
+	subjectId      = 1
+	subjectFaculty = 10
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// resourceCases is every combination of the attributes a condition can look at
+func resourceCases() map[string]testResource {
+	owners := map[string]int{"own": subjectId, "other's": 2}
+	faculties := map[string]*int{"same faculty": intPtr(subjectFaculty), "other faculty": intPtr(20), "no faculty": nil}
+	published := map[string]bool{"published": true, "unpublished": false}
+	result := make(map[string]testResource)
+	for ownerName, owner := range owners {
+		for facultyName, faculty := range faculties {
+			for publishedName, p := range published {
+				name := fmt.Sprintf("%v %v %v", ownerName, facultyName, publishedName)
+				result[name] = testResource{owner: owner, faculty: faculty, published: p}
+			}
+		}
+	}
+	return result
+}
+
+type rule func(r testResource) bool
+
+var (
+	always      rule = func(r testResource) bool { return true }
+	ownerRule   rule = func(r testResource) bool { return r.owner == subjectId }
+	facultyRule rule = func(r testResource) bool {
+		return r.faculty != nil && *r.faculty == subjectFaculty
+	}
+	publishedRule rule = func(r testResource) bool { return r.published }
+)
+
+// expectedPolicy is the access the default roles are meant to give, written down
+// independently of DefaultRoles. A permission missing from a role is never granted.
+var expectedPolicy = map[Role]map[Permission]rule{
+	Administrator: {
+		ReadUser: always, CreateUser: always, UpdateUser: always, DeleteUser: always, ImpersonateUser: always,
+		ExportUserData: always, EraseUser: always,
+		ReadFaculty: always, CreateFaculty: always, UpdateFaculty: always, DeleteFaculty: always,
+		ReadContributeSession: always, CreateContributeSession: always,
+		UpdateContributeSession: always, DeleteContributeSession: always,
+		ReadSystemData: always, UpdateSystemData: always,
+		ReadRole: always, CreateRole: always, UpdateRole: always, DeleteRole: always,
+		CreateApiKey: always, ManageApiKey: always,
+	},
+	MarketingManager: {
+		ReadContribution:        publishedRule,
+		ReadContributeSession:   always,
+		ExportContributeSession: always,
+		ReadStatistic:           always,
+		ReadFaculty:             always,
+		CreateApiKey:            always,
+	},
+	MarketingCoordinator: {
+		ReadContribution:         facultyRule,
+		UpdateContributionStatus: facultyRule,
+		ReadComment:              facultyRule,
+		CreateComment:            facultyRule,
+		UpdateComment:            ownerRule,
+		DeleteComment:            ownerRule,
+		ReadFaculty:              always,
+		ReadContributeSession:    always,
+	},
+	Student: {
+		ReadContribution:      ownerRule,
+		CreateContribution:    always,
+		UpdateContribution:    ownerRule,
+		DeleteContribution:    ownerRule,
+		CreateMedia:           always,
+		ReadComment:           ownerRule,
+		CreateComment:         ownerRule,
+		UpdateComment:         ownerRule,
+		DeleteComment:         ownerRule,
+		ReadSystemData:        always,
+		ReadFaculty:           always,
+		ReadContributeSession: always,
+	},
+	Guest: {
+		ReadContribution: func(r testResource) bool {
+			return facultyRule(r) && publishedRule(r)
+		},
+	},
+}
+
+func TestDefaultRolesPolicy(t *testing.T) {
+	SetRoles(DefaultRoles())
+	resources := resourceCases()
+	for _, definition := range DefaultRoles() {
+		expected, ok := expectedPolicy[definition.Name]
+		if !ok {
+			t.Fatalf("no expected policy for role %v", definition.Name)
+		}
+		subject := LoggedInUser{Id: subjectId, Role: definition.Name, FacultyId: intPtr(subjectFaculty)}
+		for _, permission := range AllPermissions() {
+			r, granted := expected[permission]
+			t.Run(fmt.Sprintf("%v/%v/without resource", definition.Name, permission), func(t *testing.T) {
+				if got := Enforce(subject, permission); got != granted {
+					t.Errorf("Enforce() = %v, want %v", got, granted)
+				}
+			})
+			for name, resource := range resources {
+				want := granted && r(resource)
+				t.Run(fmt.Sprintf("%v/%v/%v", definition.Name, permission, name), func(t *testing.T) {
+					if got := Enforce(subject, permission, resource); got != want {
+						t.Errorf("Enforce() = %v, want %v", got, want)
+					}
+					// services hand over pointers as often as values
+					if got := Enforce(subject, permission, &resource); got != want {
+						t.Errorf("Enforce() with pointer = %v, want %v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEnforceWithoutFaculty(t *testing.T) {
+	SetRoles(DefaultRoles())
+	subject := LoggedInUser{Id: subjectId, Role: MarketingCoordinator}
+	resource := testResource{owner: 2, faculty: nil}
+	if Enforce(subject, ReadContribution, resource) {
+		t.Error("a subject without faculty must not match a resource without faculty")
+	}
+}
+
+func TestEnforceMissingAttribute(t *testing.T) {
+	SetRoles(DefaultRoles())
+	subject := LoggedInUser{Id: subjectId, Role: Guest, FacultyId: intPtr(subjectFaculty)}
+	if Enforce(subject, ReadContribution, ownedOnly{owner: subjectId}) {
+		t.Error("a condition on an attribute the resource does not expose must deny")
+	}
+	if Enforce(subject, ReadContribution, nil) {
+		t.Error("a nil resource must not pass a condition")
+	}
+	student := LoggedInUser{Id: subjectId, Role: Student, FacultyId: intPtr(subjectFaculty)}
+	if !Enforce(student, UpdateComment, ownedOnly{owner: subjectId}) {
+		t.Error("owner condition should hold on an owned resource")
+	}
+}
+
+func TestEnforceUnknownRole(t *testing.T) {
+	SetRoles(DefaultRoles())
+	subject := LoggedInUser{Id: subjectId, Role: "unknown"}
+	if Enforce(subject, ReadContribution) || Enforce(subject, ReadContribution, testResource{owner: subjectId}) {
+		t.Error("unknown role must not be granted anything")
+	}
+}
+
+func TestInvalidConditionRevokesGrant(t *testing.T) {
+	defer SetRoles(DefaultRoles())
+	SetRoles([]RoleDefinition{{
+		Name:        "broken",
+		Permissions: []Permission{ReadContribution, ReadFaculty},
+		Conditions:  map[Permission]string{ReadContribution: "owner &&"},
+	}})
+	subject := LoggedInUser{Id: subjectId, Role: "broken"}
+	if Enforce(subject, ReadContribution) {
+		t.Error("a grant with an invalid condition must not be granted")
+	}
+	if !Enforce(subject, ReadFaculty) {
+		t.Error("other grants of the role must stay")
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	own := testResource{owner: subjectId, faculty: intPtr(20)}
+	sameFaculty := testResource{owner: 2, faculty: intPtr(subjectFaculty)}
+	published := testResource{owner: 2, faculty: intPtr(20), published: true}
+	subject := LoggedInUser{Id: subjectId, FacultyId: intPtr(subjectFaculty)}
+	tests := []struct {
+		source string
+		want   []bool // own, sameFaculty, published
+	}{
+		{"", []bool{true, true, true}},
+		{"   ", []bool{true, true, true}},
+		{"owner", []bool{true, false, false}},
+		{"!owner", []bool{false, true, true}},
+		{"!!owner", []bool{true, false, false}},
+		{"owner || faculty", []bool{true, true, false}},
+		{"owner || faculty && published", []bool{true, false, false}},
+		{"(owner || faculty) && !published", []bool{true, true, false}},
+		{"owner||published", []bool{true, false, true}},
+		{"((published))", []bool{false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			c, err := ParseCondition(tt.source)
+			if err != nil {
+				t.Fatalf("ParseCondition() error = %v", err)
+			}
+			got := []bool{
+				c.Evaluate(subject, own),
+				c.Evaluate(subject, sameFaculty),
+				c.Evaluate(subject, published),
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for _, source := range []string{
+		"owner &&",
+		"&& owner",
+		"owner faculty",
+		"(owner",
+		"owner)",
+		"!",
+		"author",
+		"owner & faculty",
+		"owner == 1",
+	} {
+		t.Run(source, func(t *testing.T) {
+			_, err := ParseCondition(source)
+			if err == nil {
+				t.Error("ParseCondition() expected an error")
+			}
+		})
+	}
+}
+
+func TestConditionSql(t *testing.T) {
+	mapping := SqlMapping{
+		OwnerColumn:        "c.user_id",
+		FacultyColumn:      "u.faculty_id",
+		PublishedPredicate: "c.status = 'accepted'",
+	}
+	subject := LoggedInUser{Id: subjectId, FacultyId: intPtr(subjectFaculty)}
+	tests := []struct {
+		source string
+		where  string
+		args   []interface{}
+	}{
+		{"", "", nil},
+		{"owner", "c.user_id = ?", []interface{}{subjectId}},
+		{"faculty && published", "(u.faculty_id = ? and c.status = 'accepted')", []interface{}{subjectFaculty}},
+		{"owner || !faculty", "(c.user_id = ? or not (u.faculty_id = ?))", []interface{}{subjectId, subjectFaculty}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			c, err := ParseCondition(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, err := c.Sql(subject, mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filter.Where != tt.where || !reflect.DeepEqual(filter.Args, tt.args) {
+				t.Errorf("Sql() = %q %v, want %q %v", filter.Where, filter.Args, tt.where, tt.args)
+			}
+		})
+	}
+
+	c, _ := ParseCondition("faculty")
+	filter, err := c.Sql(LoggedInUser{Id: subjectId}, mapping)
+	if err != nil || filter.Where != "false" {
+		t.Errorf("subject without faculty should match nothing, got %q %v", filter.Where, err)
+	}
+	_, err = c.Sql(subject, SqlMapping{OwnerColumn: "c.user_id"})
+	if err == nil {
+		t.Error("a condition on an unmapped attribute must fail")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	SetRoles(DefaultRoles())
+	mapping := SqlMapping{
+		OwnerColumn:        "c.user_id",
+		FacultyColumn:      "u.faculty_id",
+		PublishedPredicate: "c.status = 'accepted'",
+	}
+	_, err := Filter(LoggedInUser{Id: subjectId, Role: Administrator}, ReadContribution, mapping)
+	if err == nil {
+		t.Error("Filter() must fail without the permission")
+	}
+	filter, err := Filter(LoggedInUser{Id: subjectId, Role: Student, FacultyId: intPtr(subjectFaculty)}, ReadContribution, mapping)
+	if err != nil || filter.Where != "c.user_id = ?" {
+		t.Errorf("Filter() = %v %v", filter, err)
+	}
+}
@@ -1,7 +1,15 @@
 package enforcer
 
+import (
+	"go.uber.org/zap"
+	"mcm-api/pkg/log"
+	"sort"
+	"sync"
+)
+
 type Role string
 
+// built-in roles, the code relies on them so they cannot be deleted
 const (
 	Administrator        Role = "admin"
 	MarketingManager     Role = "marketing_manager"
@@ -10,90 +18,237 @@ const (
 	Guest                Role = "guest"
 )
 
-var permissionsOfRole map[Role][]Permission
-
-func init() {
-	permissionsOfRole = make(map[Role][]Permission)
-
-	addPermissions(Administrator,
-		ReadUser,
-		UpdateUser,
-		CreateUser,
-		DeleteUser,
-
-		ReadFaculty,
-		UpdateFaculty,
-		CreateFaculty,
-		DeleteFaculty,
-
-		ReadContributeSession,
-		UpdateContributeSession,
-		CreateContributeSession,
-		DeleteContributeSession,
-
-		ReadSystemData,
-		UpdateSystemData,
-	)
-
-	addPermissions(MarketingManager,
-		ReadContribution,
-		ReadContributeSession,
-		ExportContributeSession,
-		ReadStatistic,
-
-		ReadFaculty,
-
-		ReadContributeSession,
-	)
-
-	addPermissions(MarketingCoordinator,
-		ReadContribution,
-		UpdateContributionStatus,
-
-		ReadComment,
-		UpdateComment,
-		CreateComment,
-		DeleteComment,
+type RoleDefinition struct {
+	Name        Role
+	Description string
+	// users of the role belong to a faculty
+	RequiresFaculty bool
+	// users of the role must log in with a second factor
+	RequiresTwoFactor bool
+	System            bool
+	Permissions       []Permission
+	// Conditions restrict a granted permission to the resources matching the
+	// condition, see ParseCondition. Permissions without condition apply to every resource.
+	Conditions map[Permission]string
+}
 
-		ReadFaculty,
+type compiledRole struct {
+	definition  RoleDefinition
+	permissions map[Permission]bool
+	conditions  map[Permission]*Condition
+}
 
-		ReadContributeSession,
-	)
+// roles is the in-process cache of the role matrix. It starts with DefaultRoles and
+// is replaced through SetRoles whenever the roles table changes.
+var (
+	rolesMu sync.RWMutex
+	roles   map[Role]*compiledRole
+)
 
-	addPermissions(Student,
-		ReadContribution,
-		UpdateContribution,
-		CreateContribution,
-		DeleteContribution,
+func init() {
+	SetRoles(DefaultRoles())
+}
 
-		CreateMedia,
+// DefaultRoles is the matrix the roles table is seeded with.
+func DefaultRoles() []RoleDefinition {
+	return []RoleDefinition{
+		{
+			Name:              Administrator,
+			Description:       "Manages accounts, faculties, sessions and system data",
+			RequiresTwoFactor: true,
+			System:            true,
+			Permissions: []Permission{
+				ReadUser,
+				UpdateUser,
+				CreateUser,
+				DeleteUser,
+				ImpersonateUser,
+				ExportUserData,
+				EraseUser,
+
+				ReadFaculty,
+				UpdateFaculty,
+				CreateFaculty,
+				DeleteFaculty,
+
+				ReadContributeSession,
+				UpdateContributeSession,
+				CreateContributeSession,
+				DeleteContributeSession,
+
+				ReadSystemData,
+				UpdateSystemData,
+
+				ReadRole,
+				CreateRole,
+				UpdateRole,
+				DeleteRole,
+
+				CreateApiKey,
+				ManageApiKey,
+			},
+		},
+		{
+			Name:              MarketingManager,
+			Description:       "Oversees every faculty and exports accepted contributions",
+			RequiresTwoFactor: true,
+			System:            true,
+			Permissions: []Permission{
+				ReadContribution,
+				ReadContributeSession,
+				ExportContributeSession,
+				ReadStatistic,
+
+				ReadFaculty,
+
+				CreateApiKey,
+			},
+			Conditions: map[Permission]string{
+				ReadContribution: string(AttrPublished),
+			},
+		},
+		{
+			Name:            MarketingCoordinator,
+			Description:     "Reviews contributions of their faculty",
+			RequiresFaculty: true,
+			System:          true,
+			Permissions: []Permission{
+				ReadContribution,
+				UpdateContributionStatus,
+
+				ReadComment,
+				UpdateComment,
+				CreateComment,
+				DeleteComment,
+
+				ReadFaculty,
+
+				ReadContributeSession,
+			},
+			Conditions: map[Permission]string{
+				ReadContribution:         string(AttrFaculty),
+				UpdateContributionStatus: string(AttrFaculty),
+				// comments are read and created on a contribution, updated and deleted on the comment itself
+				ReadComment:   string(AttrFaculty),
+				CreateComment: string(AttrFaculty),
+				UpdateComment: string(AttrOwner),
+				DeleteComment: string(AttrOwner),
+			},
+		},
+		{
+			Name:            Student,
+			Description:     "Submits contributions",
+			RequiresFaculty: true,
+			System:          true,
+			Permissions: []Permission{
+				ReadContribution,
+				UpdateContribution,
+				CreateContribution,
+				DeleteContribution,
+
+				CreateMedia,
+
+				ReadComment,
+				UpdateComment,
+				CreateComment,
+				DeleteComment,
+
+				ReadSystemData,
+
+				ReadFaculty,
+
+				ReadContributeSession,
+			},
+			Conditions: map[Permission]string{
+				ReadContribution:   string(AttrOwner),
+				UpdateContribution: string(AttrOwner),
+				DeleteContribution: string(AttrOwner),
+				ReadComment:        string(AttrOwner),
+				CreateComment:      string(AttrOwner),
+				UpdateComment:      string(AttrOwner),
+				DeleteComment:      string(AttrOwner),
+			},
+		},
+		{
+			Name:            Guest,
+			Description:     "Reads accepted contributions of their faculty",
+			RequiresFaculty: true,
+			System:          true,
+			Permissions:     []Permission{ReadContribution},
+			Conditions: map[Permission]string{
+				ReadContribution: "faculty && published",
+			},
+		},
+	}
+}
 
-		ReadComment,
-		UpdateComment,
-		CreateComment,
-		DeleteComment,
+// SetRoles replaces the cached role matrix.
+func SetRoles(definitions []RoleDefinition) {
+	compiled := make(map[Role]*compiledRole, len(definitions))
+	for _, definition := range definitions {
+		c := &compiledRole{
+			definition:  definition,
+			permissions: make(map[Permission]bool, len(definition.Permissions)),
+			conditions:  make(map[Permission]*Condition, len(definition.Permissions)),
+		}
+		for _, p := range definition.Permissions {
+			condition, err := ParseCondition(definition.Conditions[p])
+			if err != nil {
+				// a grant that cannot be understood is not granted
+				log.Logger.Error("invalid grant condition",
+					zap.String("role", string(definition.Name)),
+					zap.String("permission", string(p)),
+					zap.Error(err))
+				continue
+			}
+			c.permissions[p] = true
+			c.conditions[p] = condition
+		}
+		compiled[definition.Name] = c
+	}
+	rolesMu.Lock()
+	roles = compiled
+	rolesMu.Unlock()
+}
 
-		ReadSystemData,
+func getRole(role Role) (*compiledRole, bool) {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	r, ok := roles[role]
+	return r, ok
+}
 
-		ReadFaculty,
+// Roles returns the cached role matrix ordered by name.
+func Roles() []RoleDefinition {
+	rolesMu.RLock()
+	result := make([]RoleDefinition, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, r.definition)
+	}
+	rolesMu.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
 
-		ReadContributeSession,
-	)
+func RoleExists(role Role) bool {
+	_, ok := getRole(role)
+	return ok
+}
 
-	addPermissions(Guest, ReadContribution)
+func RequiresFaculty(role Role) bool {
+	r, ok := getRole(role)
+	// unknown roles are treated as the most restricted
+	return !ok || r.definition.RequiresFaculty
 }
 
-func addPermissions(role Role, permission ...Permission) {
-	for _, p := range permission {
-		permissionsOfRole[role] = append(permissionsOfRole[role], p)
-	}
+func RequiresTwoFactor(role Role) bool {
+	r, ok := getRole(role)
+	return ok && r.definition.RequiresTwoFactor
 }
 
 func Can(role Role, permission Permission) bool {
-	for _, v := range permissionsOfRole[role] {
-		if v == permission {
-			return true
-		}
-	}
-	return false
+	r, ok := getRole(role)
+	return ok && r.permissions[permission]
 }
@@ -1,20 +1,46 @@
 package faculty
 
 import (
+	"errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"mcm-api/pkg/common"
+	"time"
 )
 
 type IndexQuery struct {
 	common.PaginateQuery
+	// IncludeArchived lists the archived faculties too
+	IncludeArchived bool `query:"includeArchived"`
 }
 
 type FacultyResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id         int             `json:"id"`
+	Name       string          `json:"name"`
+	Settings   FacultySettings `json:"settings"`
+	ArchivedAt *time.Time      `json:"archivedAt,omitempty"`
 	common.TrackTime
 }
 
+func (f FacultyResponse) IsArchived() bool {
+	return f.ArchivedAt != nil
+}
+
+type FacultySettings struct {
+	ContactEmail string `json:"contactEmail"`
+	// GuestAccess lets the guests of the faculty read its accepted contributions
+	GuestAccess bool `json:"guestAccess"`
+	// MaxContributionsPerStudent limits the contributions of a student in a session, null is unlimited
+	MaxContributionsPerStudent *int `json:"maxContributionsPerStudent"`
+}
+
+func (f *FacultySettings) Validate() error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.ContactEmail, is.Email, validation.Length(0, 255)),
+		validation.Field(&f.MaxContributionsPerStudent, validation.Min(1)),
+	)
+}
+
 type FacultyCreateReq struct {
 	Name string `json:"name"`
 }
@@ -33,6 +59,38 @@ func (f *FacultyUpdateReq) Validate() error {
 		validation.Field(&f.Name, validation.Required, validation.Length(6, 100)))
 }
 
+// CoordinatorsReq replaces the coordinators of a faculty, every user must be a
+// marketing coordinator of the faculty.
+type CoordinatorsReq struct {
+	CoordinatorIds []int `json:"coordinatorIds"`
+	DeputyId       *int  `json:"deputyId"`
+}
+
+func (r *CoordinatorsReq) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.CoordinatorIds, validation.Required, validation.Each(validation.Min(1))),
+		validation.Field(&r.DeputyId, validation.Min(1), validation.By(func(value interface{}) error {
+			if r.DeputyId == nil {
+				return nil
+			}
+			for _, id := range r.CoordinatorIds {
+				if id == *r.DeputyId {
+					return errors.New("the deputy cannot be one of the coordinators")
+				}
+			}
+			return nil
+		})),
+	)
+}
+
+type CoordinatorResponse struct {
+	UserId     int       `json:"userId"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Deputy     bool      `json:"deputy"`
+	AssignedAt time.Time `json:"assignedAt"`
+}
+
 type PaginateComposition struct {
 	common.PaginateResponse
 	Data []FacultyResponse `json:"data"`
@@ -0,0 +1,57 @@
+package faculty
+
+import (
+	"context"
+	"mcm-api/pkg/apperror"
+)
+
+func (s Service) FindCoordinators(ctx context.Context, id int) ([]*CoordinatorResponse, error) {
+	_, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.FindCoordinators(ctx, id)
+}
+
+// SetCoordinators replaces the coordinators and the deputy of a faculty, they are
+// notified of the new contributions instead of every marketing coordinator of the faculty.
+func (s Service) SetCoordinators(ctx context.Context, id int, body *CoordinatorsReq) ([]*CoordinatorResponse, error) {
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.ArchivedAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "faculty is archived", nil)
+	}
+	assigned := make(map[int]bool)
+	var coordinators []*CoordinatorEntity
+	for _, userId := range body.CoordinatorIds {
+		if assigned[userId] {
+			continue
+		}
+		assigned[userId] = true
+		coordinators = append(coordinators, &CoordinatorEntity{FacultyId: id, UserId: userId})
+	}
+	if body.DeputyId != nil {
+		coordinators = append(coordinators, &CoordinatorEntity{FacultyId: id, UserId: *body.DeputyId, Deputy: true})
+	}
+	userIds := make([]int, 0, len(coordinators))
+	for _, c := range coordinators {
+		userIds = append(userIds, c.UserId)
+	}
+	count, err := s.repository.CountCoordinatorCandidates(ctx, id, userIds)
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(userIds)) {
+		return nil, apperror.New(apperror.ErrInvalid, "coordinators must be marketing coordinators of the faculty", nil)
+	}
+	err = s.repository.ReplaceCoordinators(ctx, id, coordinators)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.FindCoordinators(ctx, id)
+}
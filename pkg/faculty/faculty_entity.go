@@ -3,12 +3,33 @@ package faculty
 import "time"
 
 type Entity struct {
-	Id        int
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id   int
+	Name string
+	// ContactEmail is shown to the students and guests of the faculty
+	ContactEmail string
+	GuestAccess  bool
+	// MaxContributionsPerStudent limits the contributions of a student in a session, nil is unlimited
+	MaxContributionsPerStudent *int
+	// ArchivedAt is set when the faculty is closed, it keeps its users and contributions
+	// but accepts no new members
+	ArchivedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 func (e *Entity) TableName() string {
 	return "faculties"
 }
+
+// CoordinatorEntity assigns a marketing coordinator of the faculty to review its
+// contributions, a faculty has at most one deputy.
+type CoordinatorEntity struct {
+	FacultyId int `gorm:"primaryKey"`
+	UserId    int `gorm:"primaryKey"`
+	Deputy    bool
+	CreatedAt time.Time
+}
+
+func (e *CoordinatorEntity) TableName() string {
+	return "faculty_coordinators"
+}
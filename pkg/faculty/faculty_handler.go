@@ -11,29 +11,36 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadFaculty))
 	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.ReadFaculty))
-	group.POST("", h.create, middleware.RequirePermission(enforcer.ReadFaculty))
-	group.PUT("/:id", h.update, middleware.RequirePermission(enforcer.ReadFaculty))
-	group.DELETE("/:id", h.delete, middleware.RequirePermission(enforcer.ReadFaculty))
+	group.POST("", h.create, middleware.RequirePermission(enforcer.CreateFaculty))
+	group.PUT("/:id", h.update, middleware.RequirePermission(enforcer.UpdateFaculty))
+	group.DELETE("/:id", h.delete, middleware.RequirePermission(enforcer.DeleteFaculty))
+	group.PUT("/:id/settings", h.updateSettings, middleware.RequirePermission(enforcer.UpdateFaculty))
+	group.POST("/:id/archive", h.archive, middleware.RequirePermission(enforcer.UpdateFaculty))
+	group.DELETE("/:id/archive", h.restore, middleware.RequirePermission(enforcer.UpdateFaculty))
+	group.GET("/:id/coordinators", h.getCoordinators, middleware.RequirePermission(enforcer.ReadFaculty))
+	group.PUT("/:id/coordinators", h.setCoordinators, middleware.RequirePermission(enforcer.UpdateFaculty))
 }
 
 // @Tags Faculties
 // @Summary List faculties
-// @Description List faculties
+// @Description List faculties, archived faculties are hidden unless includeArchived is set
 // @Accept  json
 // @Produce  json
 // @Param params query faculty.IndexQuery false "index query"
@@ -125,7 +132,7 @@ func (h *Handler) update(context echo.Context) error {
 
 // @Tags Faculties
 // @Summary Delete a faculty
-// @Description Delete a faculty
+// @Description Delete a faculty that never had users or contributions, archive the others
 // @Accept  json
 // @Produce  json
 // @Param id path int true "ID"
@@ -143,3 +150,121 @@ func (h *Handler) delete(context echo.Context) error {
 	}
 	return context.NoContent(http.StatusNoContent)
 }
+
+// @Tags Faculties
+// @Summary Update the settings of a faculty
+// @Description Replace the contact email, the guest access and the maximum contributions per student in a session
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Param body body faculty.FacultySettings true "settings"
+// @Success 200 {object} faculty.FacultyResponse
+// @Security ApiKeyAuth
+// @Router /faculties/{id}/settings [put]
+func (h *Handler) updateSettings(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), context)
+	}
+	body := new(FacultySettings)
+	err = context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.UpdateSettings(context.Request().Context(), id, body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Faculties
+// @Summary Archive a faculty
+// @Description Archive a faculty, its users and contributions are kept but no user can join it
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} faculty.FacultyResponse
+// @Security ApiKeyAuth
+// @Router /faculties/{id}/archive [post]
+func (h *Handler) archive(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), context)
+	}
+	result, err := h.service.Archive(context.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Faculties
+// @Summary Restore an archived faculty
+// @Description Restore an archived faculty
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} faculty.FacultyResponse
+// @Security ApiKeyAuth
+// @Router /faculties/{id}/archive [delete]
+func (h *Handler) restore(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), context)
+	}
+	result, err := h.service.Restore(context.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Faculties
+// @Summary List the coordinators of a faculty
+// @Description List the assigned marketing coordinators and the deputy of a faculty
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {array} faculty.CoordinatorResponse
+// @Security ApiKeyAuth
+// @Router /faculties/{id}/coordinators [get]
+func (h *Handler) getCoordinators(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), context)
+	}
+	result, err := h.service.FindCoordinators(context.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Faculties
+// @Summary Assign the coordinators of a faculty
+// @Description Replace the coordinators and the deputy of a faculty, they must be marketing coordinators of the faculty.
+// @Description New contributions are notified to them, or to every marketing coordinator of the faculty when none is assigned.
+// @Accept  json
+// @Produce  json
+// @Param id path int true "ID"
+// @Param body body faculty.CoordinatorsReq true "coordinators"
+// @Success 200 {array} faculty.CoordinatorResponse
+// @Security ApiKeyAuth
+// @Router /faculties/{id}/coordinators [put]
+func (h *Handler) setCoordinators(context echo.Context) error {
+	id, err := strconv.Atoi(context.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), context)
+	}
+	body := new(CoordinatorsReq)
+	err = context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.SetCoordinators(context.Request().Context(), id, body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
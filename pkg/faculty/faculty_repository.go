@@ -3,6 +3,8 @@ package faculty
 import (
 	"context"
 	"gorm.io/gorm"
+	"mcm-api/pkg/enforcer"
+	"time"
 )
 
 type repository struct {
@@ -21,10 +23,22 @@ func (r repository) FindById(ctx context.Context, id int) (*Entity, error) {
 	return result, db.Error
 }
 
+func (r repository) FindByName(ctx context.Context, name string) (*Entity, error) {
+	result := new(Entity)
+	db := r.db.WithContext(ctx).First(result, "lower(name) = lower(?)", name)
+	return result, db.Error
+}
+
 func (r repository) Find(ctx context.Context, query IndexQuery) ([]*Entity, error) {
 	return nil, nil
 }
 
+func (r repository) FindAll(ctx context.Context) ([]*Entity, error) {
+	var entities []*Entity
+	result := r.db.WithContext(ctx).Order("name").Find(&entities)
+	return entities, result.Error
+}
+
 func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
 	db := r.db.WithContext(ctx).Create(entity)
 	return entity, db.Error
@@ -42,6 +56,9 @@ func (r repository) Delete(ctx context.Context, id int) error {
 func (r repository) FindAndCount(ctx context.Context, query *IndexQuery) ([]*Entity, int64, error) {
 	var entities []*Entity
 	builder := r.db.WithContext(ctx).Model(&Entity{})
+	if !query.IncludeArchived {
+		builder.Where("archived_at is null")
+	}
 	var count int64
 	result := builder.Count(&count)
 	if result.Error != nil {
@@ -51,3 +68,49 @@ func (r repository) FindAndCount(ctx context.Context, query *IndexQuery) ([]*Ent
 	result = builder.Find(&entities)
 	return entities, count, result.Error
 }
+
+// IsInUse reports whether users or contributions belong or belonged to the faculty.
+func (r repository) IsInUse(ctx context.Context, id int) (bool, error) {
+	var inUse bool
+	db := r.db.WithContext(ctx).Raw(`select exists(select 1 from users where faculty_id = @id)
+		or exists(select 1 from contributions where faculty_id = @id)
+		or exists(select 1 from faculty_memberships where faculty_id = @id)`,
+		map[string]interface{}{"id": id}).Scan(&inUse)
+	return inUse, db.Error
+}
+
+func (r repository) SetArchivedAt(ctx context.Context, id int, archivedAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&Entity{Id: id}).Update("archived_at", archivedAt).Error
+}
+
+func (r repository) FindCoordinators(ctx context.Context, facultyId int) ([]*CoordinatorResponse, error) {
+	var result []*CoordinatorResponse
+	db := r.db.WithContext(ctx).Model(&CoordinatorEntity{}).
+		Select("users.id as user_id, users.name, users.email, faculty_coordinators.deputy, "+
+			"faculty_coordinators.created_at as assigned_at").
+		Joins("join users on users.id = faculty_coordinators.user_id").
+		Where("faculty_coordinators.faculty_id = ?", facultyId).
+		Order("faculty_coordinators.deputy, users.name").
+		Find(&result)
+	return result, db.Error
+}
+
+// CountCoordinatorCandidates counts the users that are marketing coordinators of the faculty.
+func (r repository) CountCoordinatorCandidates(ctx context.Context, facultyId int, userIds []int) (int64, error) {
+	var count int64
+	db := r.db.WithContext(ctx).Table("users").
+		Where("id in ? and role = ? and faculty_id = ?", userIds, enforcer.MarketingCoordinator, facultyId).
+		Count(&count)
+	return count, db.Error
+}
+
+// ReplaceCoordinators removes the coordinators of the faculty and assigns the given ones.
+func (r repository) ReplaceCoordinators(ctx context.Context, facultyId int, entities []*CoordinatorEntity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("faculty_id = ?", facultyId).Delete(&CoordinatorEntity{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(entities).Error
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"mcm-api/config"
 	"mcm-api/pkg/apperror"
 	"mcm-api/pkg/common"
+	"time"
 )
 
 type Service struct {
@@ -33,6 +34,14 @@ func (s Service) Find(ctx context.Context, query *IndexQuery) (*common.PaginateR
 	return common.NewPaginateResponse(res, count, query.Page, query.GetLimit()), nil
 }
 
+func (s Service) FindAll(ctx context.Context) ([]*FacultyResponse, error) {
+	entities, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mapEntitiesToRes(entities), nil
+}
+
 func (s Service) FindById(ctx context.Context, id int) (*FacultyResponse, error) {
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
@@ -44,12 +53,24 @@ func (s Service) FindById(ctx context.Context, id int) (*FacultyResponse, error)
 	return mapEntityToRes(entity), nil
 }
 
+func (s Service) FindByName(ctx context.Context, name string) (*FacultyResponse, error) {
+	entity, err := s.repository.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "faculty not found", err)
+		}
+		return nil, err
+	}
+	return mapEntityToRes(entity), nil
+}
+
 func (s Service) Create(ctx context.Context, body *FacultyCreateReq) (*FacultyResponse, error) {
 	if err := body.Validate(); err != nil {
 		return nil, err
 	}
 	entity, err := s.repository.Create(ctx, &Entity{
-		Name: body.Name,
+		Name:        body.Name,
+		GuestAccess: true,
 	})
 	if err != nil {
 		return nil, err
@@ -61,11 +82,8 @@ func (s Service) Update(ctx context.Context, id int, body *FacultyUpdateReq) (*F
 	if err := body.Validate(); err != nil {
 		return nil, err
 	}
-	entity, err := s.repository.FindById(ctx, id)
+	entity, err := s.findById(ctx, id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, apperror.New(apperror.ErrNotFound, "faculty not found", err)
-		}
 		return nil, err
 	}
 	entity.Name = body.Name
@@ -76,14 +94,96 @@ func (s Service) Update(ctx context.Context, id int, body *FacultyUpdateReq) (*F
 	return mapEntityToRes(entity), nil
 }
 
+// UpdateSettings replaces the settings of a faculty.
+func (s Service) UpdateSettings(ctx context.Context, id int, body *FacultySettings) (*FacultyResponse, error) {
+	if err := body.Validate(); err != nil {
+		return nil, err
+	}
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	entity.ContactEmail = body.ContactEmail
+	entity.GuestAccess = body.GuestAccess
+	entity.MaxContributionsPerStudent = body.MaxContributionsPerStudent
+	entity, err = s.repository.Update(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return mapEntityToRes(entity), nil
+}
+
+// Archive closes a faculty, its users and contributions are kept but no user can join it.
+func (s Service) Archive(ctx context.Context, id int) (*FacultyResponse, error) {
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.ArchivedAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "faculty is already archived", nil)
+	}
+	now := time.Now()
+	err = s.repository.SetArchivedAt(ctx, id, &now)
+	if err != nil {
+		return nil, err
+	}
+	entity.ArchivedAt = &now
+	return mapEntityToRes(entity), nil
+}
+
+func (s Service) Restore(ctx context.Context, id int) (*FacultyResponse, error) {
+	entity, err := s.findById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity.ArchivedAt == nil {
+		return nil, apperror.New(apperror.ErrConflict, "faculty is not archived", nil)
+	}
+	err = s.repository.SetArchivedAt(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	entity.ArchivedAt = nil
+	return mapEntityToRes(entity), nil
+}
+
+// Delete removes a faculty that was never used, other faculties are archived instead.
 func (s Service) Delete(ctx context.Context, id int) error {
+	_, err := s.findById(ctx, id)
+	if err != nil {
+		return err
+	}
+	inUse, err := s.repository.IsInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return apperror.New(apperror.ErrConflict, "faculty still has users or contributions, archive it instead", nil)
+	}
 	return s.repository.Delete(ctx, id)
 }
 
+func (s Service) findById(ctx context.Context, id int) (*Entity, error) {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "faculty not found", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
 func mapEntityToRes(entity *Entity) *FacultyResponse {
 	return &FacultyResponse{
 		Id:   entity.Id,
 		Name: entity.Name,
+		Settings: FacultySettings{
+			ContactEmail:               entity.ContactEmail,
+			GuestAccess:                entity.GuestAccess,
+			MaxContributionsPerStudent: entity.MaxContributionsPerStudent,
+		},
+		ArchivedAt: entity.ArchivedAt,
 		TrackTime: common.TrackTime{
 			CreatedAt: entity.CreatedAt,
 			UpdatedAt: entity.UpdatedAt,
@@ -37,3 +37,8 @@ type ContributionUploadReq struct {
 	File                io.Reader
 	ContributeSessionId int
 }
+
+type DataExportUploadReq struct {
+	File     io.Reader
+	ExportId int
+}
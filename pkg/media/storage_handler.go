@@ -11,19 +11,21 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       Service
 }
 
-func NewHandler(config *config.Config, service Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.POST("/upload", h.upload, middleware.RequirePermission(enforcer.CreateMedia))
 }
 
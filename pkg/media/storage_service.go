@@ -50,6 +50,8 @@ type Service interface {
 	UploadDocumentPreview(ctx context.Context, req *FileUploadPreviewReq) (*UploadResult, error)
 	UploadImage(ctx context.Context, req *FileUploadOriginalReq) (*UploadResult, error)
 	UploadContribution(ctx context.Context, req *ContributionUploadReq) (*UploadResult, error)
+	UploadDataExport(ctx context.Context, req *DataExportUploadReq) (*UploadResult, error)
+	DeleteFile(ctx context.Context, key string) error
 }
 
 type S3StorageService struct {
@@ -122,6 +124,31 @@ func (s S3StorageService) UploadContribution(ctx context.Context, req *Contribut
 	return &UploadResult{Key: key}, nil
 }
 
+func (s S3StorageService) UploadDataExport(ctx context.Context, req *DataExportUploadReq) (*UploadResult, error) {
+	key := fmt.Sprintf("data-export-%v-%v.zip", req.ExportId, uuid.NewString())
+	output, err := s.s3manager.UploadWithContext(ctx, &s3manager.UploadInput{
+		ACL:         aws.String(s3.ObjectCannedACLPrivate),
+		Body:        req.File,
+		Bucket:      aws.String(s.config.MediaBucket),
+		Key:         aws.String(key),
+		ContentType: aws.String("application/zip"),
+		Metadata:    nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Logger.Info("upload file completed", zap.Any("output", output))
+	return &UploadResult{Key: key}, nil
+}
+
+func (s S3StorageService) DeleteFile(ctx context.Context, key string) error {
+	_, err := s.s3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Key:    aws.String(key),
+		Bucket: aws.String(s.config.MediaBucket),
+	})
+	return err
+}
+
 func (s S3StorageService) GetUrl(ctx context.Context, key string) (string, error) {
 	select {
 	case <-ctx.Done():
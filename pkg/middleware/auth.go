@@ -1,61 +1,226 @@
 package middleware
 
 import (
+	"context"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
+	"mcm-api/config"
 	"mcm-api/pkg/apperror"
 	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/log"
+	"net/http"
 	"strconv"
+	"strings"
 )
 
-func RequireAuthentication(jwtSecret string) echo.MiddlewareFunc {
+// SessionValidator checks that the session an access token belongs to is still
+// usable, e.g. it has not been revoked and its owner has not been disabled.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionId string, userId int) error
+}
+
+// ImpersonatedRequest describes one request made with an impersonation token.
+type ImpersonatedRequest struct {
+	ImpersonationId string
+	ActorId         int
+	UserId          int
+	Method          string
+	Path            string
+	Status          int
+	IpAddress       string
+	UserAgent       string
+}
+
+// ImpersonationAuditor records every request made under impersonation.
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(request *ImpersonatedRequest)
+}
+
+// ApiKeyAuthenticator resolves the user behind an api key.
+type ApiKeyAuthenticator interface {
+	AuthenticateApiKey(ctx context.Context, key string, ipAddress string) (*enforcer.LoggedInUser, error)
+}
+
+const apiKeyHeader = "X-API-Key"
+
+// Authenticator builds the middlewares that authenticate a request.
+type Authenticator struct {
+	jwtSecret            []byte
+	sessionValidator     SessionValidator
+	impersonationAuditor ImpersonationAuditor
+	apiKeyAuthenticator  ApiKeyAuthenticator
+}
+
+// NewAuthenticator panics when a dependency is missing, a request must never be
+// authenticated with a check silently left out.
+func NewAuthenticator(
+	config *config.Config,
+	sessionValidator SessionValidator,
+	impersonationAuditor ImpersonationAuditor,
+	apiKeyAuthenticator ApiKeyAuthenticator,
+) *Authenticator {
+	switch {
+	case config == nil || config.JwtSecret == "":
+		log.Logger.Panic("Authenticator requires a jwt secret")
+	case sessionValidator == nil:
+		log.Logger.Panic("Authenticator requires a session validator")
+	case impersonationAuditor == nil:
+		log.Logger.Panic("Authenticator requires an impersonation auditor")
+	case apiKeyAuthenticator == nil:
+		log.Logger.Panic("Authenticator requires an api key authenticator")
+	}
+	return &Authenticator{
+		jwtSecret:            []byte(config.JwtSecret),
+		sessionValidator:     sessionValidator,
+		impersonationAuditor: impersonationAuditor,
+		apiKeyAuthenticator:  apiKeyAuthenticator,
+	}
+}
+
+// RequireAuthentication accepts a bearer access token or an api key in the X-API-Key header.
+func (a *Authenticator) RequireAuthentication() echo.MiddlewareFunc {
 	return Compose(middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey: []byte(jwtSecret),
+		Skipper:    hasApiKey,
+		SigningKey: a.jwtSecret,
 		ErrorHandlerWithContext: func(err error, context echo.Context) error {
 			log.Logger.Debug("JWT error", zap.Error(err))
 			appError := apperror.New(apperror.ErrUnauthorized, "invalid token", nil)
 			return apperror.HandleError(appError, context)
 		},
-	}), requireAuthentication())
+	}), a.requireApiKeyOrToken())
+}
+
+func hasApiKey(c echo.Context) bool {
+	return c.Request().Header.Get(apiKeyHeader) != ""
+}
+
+func (a *Authenticator) requireApiKeyOrToken() echo.MiddlewareFunc {
+	tokenAuthentication := a.requireAuthentication()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withToken := tokenAuthentication(next)
+		return func(c echo.Context) error {
+			if !hasApiKey(c) {
+				return withToken(c)
+			}
+			user, err := a.apiKeyAuthenticator.AuthenticateApiKey(
+				c.Request().Context(),
+				c.Request().Header.Get(apiKeyHeader),
+				c.RealIP(),
+			)
+			if err != nil {
+				return apperror.HandleError(err, c)
+			}
+			enforcer.SetLoggedInUser(c, user)
+			return next(c)
+		}
+	}
 }
 
-func RequireAuthenticationQuery(jwtSecret string) echo.MiddlewareFunc {
+func (a *Authenticator) RequireAuthenticationQuery() echo.MiddlewareFunc {
 	return Compose(middleware.JWTWithConfig(middleware.JWTConfig{
 		TokenLookup: "query:token",
-		SigningKey:  []byte(jwtSecret),
+		SigningKey:  a.jwtSecret,
 		ErrorHandlerWithContext: func(err error, context echo.Context) error {
 			log.Logger.Debug("JWT error", zap.Error(err))
 			appError := apperror.New(apperror.ErrUnauthorized, "invalid token", nil)
 			return apperror.HandleError(appError, context)
 		},
-	}), requireAuthentication())
+	}), a.requireAuthentication())
 }
 
-func requireAuthentication() echo.MiddlewareFunc {
+func (a *Authenticator) requireAuthentication() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			u := c.Get("user").(*jwt.Token)
 			claims := u.Claims.(jwt.MapClaims)
 			id, _ := strconv.Atoi(claims["sub"].(string))
+			sessionId, _ := claims["sid"].(string)
+			if sessionId == "" {
+				appError := apperror.New(apperror.ErrUnauthorized, "invalid token", nil)
+				return apperror.HandleError(appError, c)
+			}
+			// an impersonation token lives on the session of the administrator
+			sessionOwner := id
+			var actorId *int
+			if act, ok := claims["act"].(string); ok {
+				v, err := strconv.Atoi(act)
+				if err != nil {
+					appError := apperror.New(apperror.ErrUnauthorized, "invalid token", err)
+					return apperror.HandleError(appError, c)
+				}
+				actorId = &v
+				sessionOwner = v
+			}
+			err := a.sessionValidator.ValidateSession(c.Request().Context(), sessionId, sessionOwner)
+			if err != nil {
+				return apperror.HandleError(err, c)
+			}
 			var facultyId int
 			if claims["facultyId"] != nil {
 				facultyId, _ = strconv.Atoi(claims["facultyId"].(string))
 			}
 			enforcer.SetLoggedInUser(c, &enforcer.LoggedInUser{
-				Id:        id,
-				Email:     claims["email"].(string),
-				Name:      claims["name"].(string),
-				Role:      enforcer.Role(claims["role"].(string)),
-				FacultyId: &facultyId,
+				Id:             id,
+				Email:          claims["email"].(string),
+				Name:           claims["name"].(string),
+				Role:           enforcer.Role(claims["role"].(string)),
+				FacultyId:      &facultyId,
+				SessionId:      sessionId,
+				ImpersonatedBy: actorId,
 			})
+			if actorId != nil {
+				impersonationId, _ := claims["imp"].(string)
+				allowWrite, _ := claims["imp_write"].(bool)
+				return a.handleImpersonatedRequest(c, next, &ImpersonatedRequest{
+					ImpersonationId: impersonationId,
+					ActorId:         *actorId,
+					UserId:          id,
+				}, allowWrite)
+			}
 			return next(c)
 		}
 	}
 }
 
+func (a *Authenticator) handleImpersonatedRequest(c echo.Context, next echo.HandlerFunc, request *ImpersonatedRequest, allowWrite bool) error {
+	request.Method = c.Request().Method
+	request.Path = c.Request().URL.RequestURI()
+	request.IpAddress = c.RealIP()
+	request.UserAgent = c.Request().UserAgent()
+	var err error
+	if impersonationAllows(request.Method, c.Request().URL.Path, allowWrite) {
+		err = next(c)
+	} else {
+		err = apperror.HandleError(
+			apperror.New(apperror.ErrForbidden, "action not allowed while impersonating", nil),
+			c,
+		)
+	}
+	request.Status = c.Response().Status
+	if httpError, ok := err.(*echo.HTTPError); ok {
+		request.Status = httpError.Code
+	}
+	a.impersonationAuditor.RecordImpersonatedRequest(request)
+	return err
+}
+
+// impersonationAllows keeps impersonation read only unless write access was granted
+// when it started. Deletions and changes to credentials or sessions stay blocked either way.
+func impersonationAllows(method string, path string, allowWrite bool) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodDelete:
+		return false
+	}
+	if !allowWrite {
+		return false
+	}
+	return !strings.HasPrefix(path, "/auth") && !strings.HasPrefix(path, "/me")
+}
+
 func Compose(middlewares ...echo.MiddlewareFunc) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net"
+	"strings"
+)
+
+// IpExtractor resolves the client ip used by api key allow lists, login throttling and
+// audit records. Without trusted proxies the peer address is used as is, X-Forwarded-For
+// is only honoured when the request passed through one of the given comma separated
+// ips or ranges.
+func IpExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	var options []echo.TrustOption
+	for _, entry := range strings.Split(trustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		options = append(options, echo.TrustIPRange(network))
+	}
+	if len(options) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options = append(options,
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	)
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// allowListAuthenticator accepts any key from a single ip, like an api key with an allow list.
+type allowListAuthenticator struct {
+	allowed string
+}
+
+func (a allowListAuthenticator) AuthenticateApiKey(ctx context.Context, key string, ipAddress string) (*enforcer.LoggedInUser, error) {
+	if ipAddress != a.allowed {
+		return nil, apperror.New(apperror.ErrForbidden, "api key is not allowed from "+ipAddress, nil)
+	}
+	return &enforcer.LoggedInUser{Id: 1, Role: enforcer.Administrator}, nil
+}
+
+type noopSessionValidator struct{}
+
+func (noopSessionValidator) ValidateSession(ctx context.Context, sessionId string, userId int) error {
+	return nil
+}
+
+type noopImpersonationAuditor struct{}
+
+func (noopImpersonationAuditor) RecordImpersonatedRequest(request *ImpersonatedRequest) {}
+
+func newApiKeyServer(t *testing.T, trustedProxies string) *echo.Echo {
+	ipExtractor, err := IpExtractor(trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := NewAuthenticator(
+		&config.Config{JwtSecret: "secret"},
+		noopSessionValidator{},
+		noopImpersonationAuditor{},
+		allowListAuthenticator{allowed: "203.0.113.7"},
+	)
+	e := echo.New()
+	e.IPExtractor = ipExtractor
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.RealIP())
+	}, authenticator.RequireAuthentication())
+	return e
+}
+
+func apiKeyRequest(e *echo.Echo, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(apiKeyHeader, "mcm_test_key")
+	if forwardedFor != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestApiKeyAllowListIgnoresSpoofedForwardedFor(t *testing.T) {
+	e := newApiKeyServer(t, "")
+	rec := apiKeyRequest(e, "198.51.100.1:4000", "203.0.113.7")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected spoofed X-Forwarded-For to be rejected, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = apiKeyRequest(e, "203.0.113.7:4000", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected direct request from the allowed ip to pass, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestApiKeyAllowListBehindTrustedProxy(t *testing.T) {
+	e := newApiKeyServer(t, "10.0.0.0/8")
+	rec := apiKeyRequest(e, "10.1.2.3:4000", "203.0.113.7")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected forwarded request from the allowed ip to pass, got %d %s", rec.Code, rec.Body.String())
+	}
+	// the proxy appends the peer it saw, whatever the client put in front is not trusted
+	rec = apiKeyRequest(e, "10.1.2.3:4000", "203.0.113.7, 198.51.100.1")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected spoofed X-Forwarded-For to be rejected, got %d %s", rec.Code, rec.Body.String())
+	}
+	// a client that is not a trusted proxy cannot forward at all
+	rec = apiKeyRequest(e, "198.51.100.1:4000", "203.0.113.7")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected X-Forwarded-For from an untrusted peer to be rejected, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIpExtractorRejectsInvalidProxy(t *testing.T) {
+	if _, err := IpExtractor("10.0.0.0/8, not-an-ip"); err == nil {
+		t.Error("expected an invalid trusted proxy to fail")
+	}
+}
+
+func TestNewAuthenticatorRequiresDependencies(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an authenticator without api key authenticator to fail")
+		}
+	}()
+	NewAuthenticator(&config.Config{JwtSecret: "secret"}, noopSessionValidator{}, noopImpersonationAuditor{}, nil)
+}
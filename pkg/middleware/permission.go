@@ -13,7 +13,7 @@ func RequirePermission(permission enforcer.Permission) echo.MiddlewareFunc {
 			if err != nil {
 				return apperror.HandleError(err, context)
 			}
-			if !enforcer.Can(user.Role, permission) {
+			if !enforcer.Enforce(*user, permission) {
 				return apperror.HandleError(
 					apperror.New(
 						apperror.ErrForbidden,
@@ -26,3 +26,51 @@ func RequirePermission(permission enforcer.Permission) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// RequireAnyPermission lets the request through when one of the permissions is granted,
+// the handler then decides what each of them allows.
+func RequireAnyPermission(permissions ...enforcer.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			user, err := enforcer.GetLoggedInUser(context.Request().Context())
+			if err != nil {
+				return apperror.HandleError(err, context)
+			}
+			for _, permission := range permissions {
+				if enforcer.Enforce(*user, permission) {
+					return next(context)
+				}
+			}
+			return apperror.HandleError(
+				apperror.New(
+					apperror.ErrForbidden,
+					"insufficient permission",
+					nil),
+				context,
+			)
+		}
+	}
+}
+
+// RequireSession rejects api keys, the route manages the account or the session of a
+// user signed in with a password or the identity provider.
+func RequireSession() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			user, err := enforcer.GetLoggedInUser(context.Request().Context())
+			if err != nil {
+				return apperror.HandleError(err, context)
+			}
+			if user.ApiKeyId != nil || user.SessionId == "" {
+				return apperror.HandleError(
+					apperror.New(
+						apperror.ErrForbidden,
+						"not available to api keys",
+						nil),
+					context,
+				)
+			}
+			return next(context)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"net/http"
+	"testing"
+)
+
+func TestRequireSessionRejectsApiKeys(t *testing.T) {
+	authenticator := NewAuthenticator(
+		&config.Config{JwtSecret: "secret"},
+		noopSessionValidator{},
+		noopImpersonationAuditor{},
+		allowListAuthenticator{allowed: "203.0.113.7"},
+	)
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, authenticator.RequireAuthentication(), RequireSession())
+	rec := apiKeyRequest(e, "203.0.113.7:4000", "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected an api key to be rejected, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,5 @@
+package middleware
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(NewAuthenticator)
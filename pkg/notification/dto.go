@@ -5,3 +5,35 @@ type TemplateNewContributionPayLoad struct {
 	StudentName string
 	Link        string
 }
+
+type TemplatePasswordResetPayload struct {
+	Name          string
+	Link          string
+	ExpireMinutes int
+}
+
+type TemplateInvitationPayload struct {
+	Name        string
+	Link        string
+	ExpireHours int
+}
+
+type TemplateSessionClosingPayload struct {
+	Name        string
+	Link        string
+	ClosureTime string
+}
+
+type TemplateSessionClosedPayload struct {
+	Name             string
+	Link             string
+	FinalClosureTime string
+}
+
+type TemplateFeedbackOverduePayload struct {
+	Name          string
+	Link          string
+	StudentName   string
+	Title         string
+	FeedbackDueAt string
+}
@@ -18,6 +18,11 @@ type EmailTemplate string
 
 const (
 	NewContributionTemplate EmailTemplate = "new_contribution"
+	PasswordResetTemplate   EmailTemplate = "password_reset"
+	InvitationTemplate      EmailTemplate = "invitation"
+	SessionClosingTemplate  EmailTemplate = "session_closing"
+	SessionClosedTemplate   EmailTemplate = "session_closed"
+	FeedbackOverdueTemplate EmailTemplate = "feedback_overdue"
 )
 
 type Service struct {
@@ -86,8 +91,28 @@ var parsedTemplate *template.Template
 //go:embed templates/new_contribution.tmpl
 var newContributionTemplate string
 
+//go:embed templates/password_reset.tmpl
+var passwordResetTemplate string
+
+//go:embed templates/invitation.tmpl
+var invitationTemplate string
+
+//go:embed templates/session_closing.tmpl
+var sessionClosingTemplate string
+
+//go:embed templates/session_closed.tmpl
+var sessionClosedTemplate string
+
+//go:embed templates/feedback_overdue.tmpl
+var feedbackOverdueTemplate string
+
 func init() {
 	parsedTemplate = template.Must(template.New(string(NewContributionTemplate)).Parse(newContributionTemplate))
+	template.Must(parsedTemplate.New(string(PasswordResetTemplate)).Parse(passwordResetTemplate))
+	template.Must(parsedTemplate.New(string(InvitationTemplate)).Parse(invitationTemplate))
+	template.Must(parsedTemplate.New(string(SessionClosingTemplate)).Parse(sessionClosingTemplate))
+	template.Must(parsedTemplate.New(string(SessionClosedTemplate)).Parse(sessionClosedTemplate))
+	template.Must(parsedTemplate.New(string(FeedbackOverdueTemplate)).Parse(feedbackOverdueTemplate))
 }
 
 func generateBodyAndSubject(tmpl EmailTemplate, payload interface{}) (string, string, error) {
@@ -102,6 +127,56 @@ func generateBodyAndSubject(tmpl EmailTemplate, payload interface{}) (string, st
 			return buf.String(), fmt.Sprintf("New contribution from %s", v.StudentName), nil
 		}
 		return "", "", errors.New("wrong type of payload")
+	case PasswordResetTemplate:
+		if v, ok := payload.(*TemplatePasswordResetPayload); ok {
+			buf := new(bytes.Buffer)
+			err := parsedTemplate.ExecuteTemplate(buf, string(PasswordResetTemplate), v)
+			if err != nil {
+				return "", "", err
+			}
+			return buf.String(), "Reset your password", nil
+		}
+		return "", "", errors.New("wrong type of payload")
+	case InvitationTemplate:
+		if v, ok := payload.(*TemplateInvitationPayload); ok {
+			buf := new(bytes.Buffer)
+			err := parsedTemplate.ExecuteTemplate(buf, string(InvitationTemplate), v)
+			if err != nil {
+				return "", "", err
+			}
+			return buf.String(), "Your account is ready", nil
+		}
+		return "", "", errors.New("wrong type of payload")
+	case SessionClosingTemplate:
+		if v, ok := payload.(*TemplateSessionClosingPayload); ok {
+			buf := new(bytes.Buffer)
+			err := parsedTemplate.ExecuteTemplate(buf, string(SessionClosingTemplate), v)
+			if err != nil {
+				return "", "", err
+			}
+			return buf.String(), "The contribution session closes soon", nil
+		}
+		return "", "", errors.New("wrong type of payload")
+	case SessionClosedTemplate:
+		if v, ok := payload.(*TemplateSessionClosedPayload); ok {
+			buf := new(bytes.Buffer)
+			err := parsedTemplate.ExecuteTemplate(buf, string(SessionClosedTemplate), v)
+			if err != nil {
+				return "", "", err
+			}
+			return buf.String(), "The contribution session is closed", nil
+		}
+		return "", "", errors.New("wrong type of payload")
+	case FeedbackOverdueTemplate:
+		if v, ok := payload.(*TemplateFeedbackOverduePayload); ok {
+			buf := new(bytes.Buffer)
+			err := parsedTemplate.ExecuteTemplate(buf, string(FeedbackOverdueTemplate), v)
+			if err != nil {
+				return "", "", err
+			}
+			return buf.String(), fmt.Sprintf("Feedback overdue for a contribution of %s", v.StudentName), nil
+		}
+		return "", "", errors.New("wrong type of payload")
 	default:
 		return "", "", fmt.Errorf("unknown template %v", tmpl)
 	}
@@ -110,3 +185,23 @@ func generateBodyAndSubject(tmpl EmailTemplate, payload interface{}) (string, st
 func (s Service) SendNewContributionEmail(des *Destination, payload *TemplateNewContributionPayLoad) error {
 	return s.sendEmail(des, NewContributionTemplate, payload)
 }
+
+func (s Service) SendPasswordResetEmail(des *Destination, payload *TemplatePasswordResetPayload) error {
+	return s.sendEmail(des, PasswordResetTemplate, payload)
+}
+
+func (s Service) SendInvitationEmail(des *Destination, payload *TemplateInvitationPayload) error {
+	return s.sendEmail(des, InvitationTemplate, payload)
+}
+
+func (s Service) SendSessionClosingEmail(des *Destination, payload *TemplateSessionClosingPayload) error {
+	return s.sendEmail(des, SessionClosingTemplate, payload)
+}
+
+func (s Service) SendSessionClosedEmail(des *Destination, payload *TemplateSessionClosedPayload) error {
+	return s.sendEmail(des, SessionClosedTemplate, payload)
+}
+
+func (s Service) SendFeedbackOverdueEmail(des *Destination, payload *TemplateFeedbackOverduePayload) error {
+	return s.sendEmail(des, FeedbackOverdueTemplate, payload)
+}
@@ -0,0 +1,103 @@
+package privacy
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"mcm-api/pkg/contribution"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/user"
+	"time"
+)
+
+type ExportRequest struct {
+	UserId int `json:"userId"`
+}
+
+func (r *ExportRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.UserId, validation.Required),
+	)
+}
+
+type ExportResponse struct {
+	Id     int          `json:"id"`
+	UserId int          `json:"userId"`
+	Status ExportStatus `json:"status" enums:"pending,running,done,failed"`
+	// Link downloads the zip once the export is done, it expires after 15 minutes
+	Link       string     `json:"link,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedBy  *int       `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+type EraseRequest struct {
+	UserId int `json:"userId"`
+}
+
+func (r *EraseRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.UserId, validation.Required),
+	)
+}
+
+type EraseResponse struct {
+	UserId int `json:"userId"`
+	// ScrubbedContributions lost their title, description, article and images
+	ScrubbedContributions int `json:"scrubbedContributions"`
+	// RetainedContributions are accepted contributions kept as magazine content
+	RetainedContributions int `json:"retainedContributions"`
+	ScrubbedComments      int `json:"scrubbedComments"`
+	// DeletedFiles counts the files removed from the media bucket, files that could
+	// not be deleted are logged
+	DeletedFiles int `json:"deletedFiles"`
+}
+
+// the documents below are the json files of an export
+
+type profileDocument struct {
+	Id                      int                          `json:"id"`
+	Name                    string                       `json:"name"`
+	Email                   string                       `json:"email"`
+	Role                    enforcer.Role                `json:"role"`
+	FacultyId               *int                         `json:"facultyId"`
+	Status                  user.UserStatus              `json:"status"`
+	Avatar                  string                       `json:"avatar,omitempty"`
+	NotificationPreferences user.NotificationPreferences `json:"notificationPreferences"`
+	TwoFactorEnabled        bool                         `json:"twoFactorEnabled"`
+	CreatedAt               time.Time                    `json:"createdAt"`
+	UpdatedAt               time.Time                    `json:"updatedAt"`
+}
+
+type contributionDocument struct {
+	Id                  int                       `json:"id"`
+	ContributeSessionId int                       `json:"contributeSessionId"`
+	FacultyId           *int                      `json:"facultyId"`
+	Title               string                    `json:"title"`
+	Description         string                    `json:"description"`
+	Status              contribution.Status       `json:"status"`
+	ArticleVersions     []*articleVersionDocument `json:"articleVersions"`
+	Images              []*imageDocument          `json:"images"`
+	CreatedAt           time.Time                 `json:"createdAt"`
+	UpdatedAt           time.Time                 `json:"updatedAt"`
+}
+
+// File is the path of the document within the zip, it is empty when the file is missing
+type articleVersionDocument struct {
+	Id        int       `json:"id"`
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type imageDocument struct {
+	Title string `json:"title"`
+	File  string `json:"file"`
+}
+
+type commentDocument struct {
+	Id             string    `json:"id"`
+	ContributionId int       `json:"contributionId"`
+	Content        string    `json:"content"`
+	Resolved       bool      `json:"resolved"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
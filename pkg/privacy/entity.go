@@ -0,0 +1,29 @@
+package privacy
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportRunning ExportStatus = "running"
+	ExportDone    ExportStatus = "done"
+	ExportFailed  ExportStatus = "failed"
+)
+
+// ExportEntity is a data-subject export, FileKey is the zip in the media bucket once done.
+type ExportEntity struct {
+	Id         int
+	UserId     int
+	Status     ExportStatus
+	FileKey    string
+	Error      string
+	CreatedBy  *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (e *ExportEntity) TableName() string {
+	return "data_exports"
+}
@@ -0,0 +1,167 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"io"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/log"
+	"path"
+	"strconv"
+)
+
+// writeExport writes the zip of a data-subject export:
+//
+//	profile.json
+//	contributions.json
+//	comments.json
+//	avatar<ext>
+//	contributions/<id>/article-<version id><ext>
+//	contributions/<id>/images/<key>
+//
+// The json documents reference the files by their path in the zip.
+func (s Service) writeExport(ctx context.Context, w io.Writer, userId int) error {
+	entity, err := s.repository.FindUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	contributions, err := s.repository.FindContributionsOfUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	articleIds := make([]int, 0, len(contributions))
+	for _, c := range contributions {
+		if c.ArticleId != nil {
+			articleIds = append(articleIds, *c.ArticleId)
+		}
+	}
+	versions, err := s.repository.FindArticleVersions(ctx, articleIds)
+	if err != nil {
+		return err
+	}
+	comments, err := s.repository.FindCommentsOfUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	profile := &profileDocument{
+		Id:                      entity.Id,
+		Name:                    entity.Name,
+		Email:                   entity.Email,
+		Role:                    entity.Role,
+		FacultyId:               entity.FacultyId,
+		Status:                  entity.Status,
+		NotificationPreferences: entity.NotificationPreferences,
+		TwoFactorEnabled:        entity.TotpEnabledAt != nil,
+		CreatedAt:               entity.CreatedAt,
+		UpdatedAt:               entity.UpdatedAt,
+	}
+	if entity.Avatar != "" {
+		profile.Avatar, err = s.copyFile(ctx, archive, entity.Avatar, "avatar"+path.Ext(entity.Avatar))
+		if err != nil {
+			return err
+		}
+	}
+
+	contributionDocuments := make([]*contributionDocument, 0, len(contributions))
+	for _, c := range contributions {
+		folder := "contributions/" + strconv.Itoa(c.Id) + "/"
+		document := &contributionDocument{
+			Id:                  c.Id,
+			ContributeSessionId: c.ContributeSessionId,
+			FacultyId:           c.FacultyId,
+			Title:               c.Title,
+			Description:         c.Description,
+			Status:              c.Status,
+			ArticleVersions:     []*articleVersionDocument{},
+			Images:              []*imageDocument{},
+			CreatedAt:           c.CreatedAt,
+			UpdatedAt:           c.UpdatedAt,
+		}
+		for _, version := range versions {
+			if c.ArticleId == nil || version.ArticleId != *c.ArticleId {
+				continue
+			}
+			name := folder + "article-" + strconv.Itoa(version.Id) + path.Ext(version.LinkOriginal)
+			file, err := s.copyFile(ctx, archive, version.LinkOriginal, name)
+			if err != nil {
+				return err
+			}
+			document.ArticleVersions = append(document.ArticleVersions, &articleVersionDocument{
+				Id:        version.Id,
+				File:      file,
+				CreatedAt: version.CreatedAt,
+			})
+		}
+		for _, image := range c.Images {
+			file, err := s.copyFile(ctx, archive, image.Key, folder+"images/"+path.Base(image.Key))
+			if err != nil {
+				return err
+			}
+			document.Images = append(document.Images, &imageDocument{Title: image.Title, File: file})
+		}
+		contributionDocuments = append(contributionDocuments, document)
+	}
+
+	commentDocuments := make([]*commentDocument, 0, len(comments))
+	for _, c := range comments {
+		commentDocuments = append(commentDocuments, &commentDocument{
+			Id:             c.Id,
+			ContributionId: c.ContributionId,
+			Content:        c.Content,
+			Resolved:       c.Resolved,
+			CreatedAt:      c.CreatedAt,
+			UpdatedAt:      c.UpdatedAt,
+		})
+	}
+
+	for name, document := range map[string]interface{}{
+		"profile.json":       profile,
+		"contributions.json": contributionDocuments,
+		"comments.json":      commentDocuments,
+	} {
+		err = writeJson(archive, name, document)
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// copyFile copies a file of the media bucket into the zip and returns its name, a
+// missing file is skipped with an empty name so one lost upload does not fail the export.
+func (s Service) copyFile(ctx context.Context, archive *zip.Writer, key string, name string) (string, error) {
+	reader, err := s.mediaService.GetFile(ctx, key)
+	if err != nil {
+		if apperror.Is(err, apperror.ErrNotFound) {
+			log.Logger.Warn("skip missing file of data export", zap.String("key", key))
+			return "", nil
+		}
+		return "", err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	writer, err := archive.Create(name)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(writer, reader)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func writeJson(archive *zip.Writer, name string, document interface{}) error {
+	writer, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,100 @@
+package privacy
+
+import (
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/middleware"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
+}
+
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
+	return &Handler{
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
+	}
+}
+
+func (h *Handler) Register(group *echo.Group) {
+	group.Use(h.authenticator.RequireAuthentication())
+	group.POST("/exports", h.createExport, middleware.RequirePermission(enforcer.ExportUserData))
+	group.GET("/exports/:id", h.getExportById, middleware.RequirePermission(enforcer.ExportUserData))
+	group.POST("/erasures", h.erase, middleware.RequirePermission(enforcer.EraseUser))
+}
+
+// @Tags Privacy
+// @Summary Export the personal data of a user
+// @Description Queue a zip of the profile, contributions, article versions, images and comments of a user.
+// @Description Poll the export until it is done to get the download link.
+// @Accept  json
+// @Produce  json
+// @Param body body privacy.ExportRequest true "export req"
+// @Success 200 {object} privacy.ExportResponse
+// @Security ApiKeyAuth
+// @Router /privacy/exports [post]
+func (h *Handler) createExport(ctx echo.Context) error {
+	req := new(ExportRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	result, err := h.service.RequestExport(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// @Tags Privacy
+// @Summary Show a data export
+// @Description get the state of an export by ID, the download link expires after 15 minutes
+// @Accept  json
+// @Produce  json
+// @Param id path int true "export ID"
+// @Success 200 {object} privacy.ExportResponse
+// @Security ApiKeyAuth
+// @Router /privacy/exports/{id} [get]
+func (h *Handler) getExportById(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), ctx)
+	}
+	result, err := h.service.FindExportById(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// @Tags Privacy
+// @Summary Erase the personal data of a user
+// @Description Anonymize the profile and comments of a user and scrub their contributions, accepted
+// @Description contributions are kept as magazine content unless the retention policy erases them.
+// @Description The user is disabled and cannot be restored.
+// @Accept  json
+// @Produce  json
+// @Param body body privacy.EraseRequest true "erase req"
+// @Success 200 {object} privacy.EraseResponse
+// @Security ApiKeyAuth
+// @Router /privacy/erasures [post]
+func (h *Handler) erase(ctx echo.Context) error {
+	req := new(EraseRequest)
+	err := ctx.Bind(req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	result, err := h.service.Erase(ctx.Request().Context(), req)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,156 @@
+package privacy
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"mcm-api/pkg/article"
+	"mcm-api/pkg/comment"
+	"mcm-api/pkg/contribution"
+	"mcm-api/pkg/user"
+	"time"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func InitializeRepository(db *gorm.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r repository) CreateExport(ctx context.Context, entity *ExportEntity) (*ExportEntity, error) {
+	db := r.db.WithContext(ctx).Create(entity)
+	return entity, db.Error
+}
+
+func (r repository) FindExportById(ctx context.Context, id int) (*ExportEntity, error) {
+	result := new(ExportEntity)
+	db := r.db.WithContext(ctx).First(result, id)
+	return result, db.Error
+}
+
+func (r repository) UpdateExport(ctx context.Context, entity *ExportEntity) (*ExportEntity, error) {
+	db := r.db.WithContext(ctx).Save(entity)
+	return entity, db.Error
+}
+
+// MarkExportRunning moves a pending export to running, it returns false when the
+// export was already picked up.
+func (r repository) MarkExportRunning(ctx context.Context, id int) (bool, error) {
+	db := r.db.WithContext(ctx).Model(&ExportEntity{}).
+		Where("id = ? and status = ?", id, ExportPending).
+		Update("status", ExportRunning)
+	return db.RowsAffected > 0, db.Error
+}
+
+func (r repository) FindExportsOfUser(ctx context.Context, userId int) ([]*ExportEntity, error) {
+	var entities []*ExportEntity
+	db := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&entities)
+	return entities, db.Error
+}
+
+func (r repository) FindUser(ctx context.Context, id int) (*user.Entity, error) {
+	result := new(user.Entity)
+	db := r.db.WithContext(ctx).First(result, id)
+	return result, db.Error
+}
+
+func (r repository) FindContributionsOfUser(ctx context.Context, userId int) ([]*contribution.Entity, error) {
+	var entities []*contribution.Entity
+	db := r.db.WithContext(ctx).
+		Preload("Images").
+		Where("user_id = ?", userId).
+		Order("id").
+		Find(&entities)
+	return entities, db.Error
+}
+
+func (r repository) FindArticleVersions(ctx context.Context, articleIds []int) ([]*article.Version, error) {
+	var entities []*article.Version
+	if len(articleIds) == 0 {
+		return entities, nil
+	}
+	db := r.db.WithContext(ctx).
+		Where("article_id in ?", articleIds).
+		Order("created_at").
+		Find(&entities)
+	return entities, db.Error
+}
+
+func (r repository) FindCommentsOfUser(ctx context.Context, userId int) ([]*comment.Entity, error) {
+	var entities []*comment.Entity
+	db := r.db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at").Find(&entities)
+	return entities, db.Error
+}
+
+// erasure is what Erase removes, contributions not listed keep their content.
+type erasure struct {
+	user            *user.Entity
+	contributionIds []int
+	articleIds      []int
+	commentContent  string
+}
+
+// Erase scrubs the personal data of a user in a single transaction. The rows stay so
+// statistics keep counting them: the user keeps role and faculty, contributions keep
+// session and status, comments keep their contribution.
+func (r repository) Erase(ctx context.Context, e *erasure) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(e.user).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&comment.Entity{}).
+			Where("user_id = ?", e.user.Id).
+			Update("content", e.commentContent).Error
+		if err != nil {
+			return err
+		}
+		if len(e.contributionIds) > 0 {
+			err = tx.Model(&contribution.Entity{}).
+				Where("id in ?", e.contributionIds).
+				Updates(map[string]interface{}{"title": "", "description": ""}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("contribution_id in ?", e.contributionIds).Delete(&contribution.ImageEntity{}).Error
+			if err != nil {
+				return err
+			}
+			// the timeline keeps the previous titles, descriptions, articles and images
+			err = tx.Model(&contribution.EventEntity{}).
+				Where("contribution_id in ? and type in ?", e.contributionIds, []contribution.EventType{
+					contribution.EventTitleChanged,
+					contribution.EventDescriptionChanged,
+					contribution.EventArticleVersionUploaded,
+					contribution.EventImagesChanged,
+				}).
+				Updates(map[string]interface{}{"before": nil, "after": nil}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(e.articleIds) > 0 {
+			err = tx.Where("article_id in ?", e.articleIds).Delete(&article.Version{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		// sessions and tokens hold ip addresses and user agents, and must stop working
+		for _, table := range []string{"auth_sessions", "password_reset_tokens", "user_invitations", "data_exports"} {
+			err = tx.Exec("delete from "+table+" where user_id = ?", e.user.Id).Error
+			if err != nil {
+				return err
+			}
+		}
+		// the acceptances of the term and condition are kept as evidence, without the address
+		err = tx.Exec("update term_and_condition_acceptances set ip_address = null where user_id = ?", e.user.Id).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("update api_keys set revoked_at = ? where user_id = ? and revoked_at is null",
+			time.Now(), e.user.Id).Error
+	})
+}
@@ -0,0 +1,311 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/audit"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/media"
+	"mcm-api/pkg/queue"
+	"mcm-api/pkg/user"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	erasedUserName       = "Erased user"
+	erasedCommentContent = "This comment was removed"
+)
+
+type Service struct {
+	cfg          *config.Config
+	repository   *repository
+	mediaService media.Service
+	queue        queue.Queue
+	auditService *audit.Service
+}
+
+func InitializeService(
+	cfg *config.Config,
+	repository *repository,
+	mediaService media.Service,
+	queue queue.Queue,
+	auditService *audit.Service,
+) *Service {
+	return &Service{
+		cfg:          cfg,
+		repository:   repository,
+		mediaService: mediaService,
+		queue:        queue,
+		auditService: auditService,
+	}
+}
+
+// RequestExport queues a zip of the personal data of a user for the worker, poll the
+// export until it is done to get the download link.
+func (s Service) RequestExport(ctx context.Context, req *ExportRequest) (*ExportResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.findUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.repository.CreateExport(ctx, &ExportEntity{
+		UserId:    req.UserId,
+		Status:    ExportPending,
+		CreatedBy: &loggedInUser.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.queue.Add(ctx, &queue.Message{
+		Topic: queue.UserDataExportRequested,
+		Data:  &queue.UserDataExportRequestedPayload{ExportId: entity.Id},
+	})
+	if err != nil {
+		return nil, s.failExport(entity, err)
+	}
+	s.auditService.RecordAsync(&audit.Entity{
+		ActorId:    &loggedInUser.Id,
+		Action:     audit.UserDataExported,
+		TargetType: "user",
+		TargetId:   strconv.Itoa(req.UserId),
+		Data:       audit.Data{"exportId": entity.Id},
+	})
+	return s.mapExportToResponse(ctx, entity), nil
+}
+
+func (s Service) FindExportById(ctx context.Context, id int) (*ExportResponse, error) {
+	entity, err := s.repository.FindExportById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "data export not found", err)
+		}
+		return nil, err
+	}
+	return s.mapExportToResponse(ctx, entity), nil
+}
+
+// ProcessExport builds and uploads a queued export, it is called by the worker.
+func (s Service) ProcessExport(ctx context.Context, id int) error {
+	started, err := s.repository.MarkExportRunning(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !started {
+		log.Logger.Info("skip data export that is not pending", zap.Int("id", id))
+		return nil
+	}
+	entity, err := s.repository.FindExportById(ctx, id)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "mcm-data-export-*.zip")
+	if err != nil {
+		return s.failExport(entity, err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	err = s.writeExport(ctx, file, entity.UserId)
+	if err != nil {
+		return s.failExport(entity, err)
+	}
+	_, err = file.Seek(0, 0)
+	if err != nil {
+		return s.failExport(entity, err)
+	}
+	uploadResult, err := s.mediaService.UploadDataExport(ctx, &media.DataExportUploadReq{
+		File:     file,
+		ExportId: entity.Id,
+	})
+	if err != nil {
+		return s.failExport(entity, err)
+	}
+	now := time.Now()
+	entity.Status = ExportDone
+	entity.FileKey = uploadResult.Key
+	entity.FinishedAt = &now
+	_, err = s.repository.UpdateExport(ctx, entity)
+	return err
+}
+
+// failExport records why an export stopped, it does not use the context of the export
+// since it may be the reason.
+func (s Service) failExport(entity *ExportEntity, cause error) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelFunc()
+	now := time.Now()
+	entity.Status = ExportFailed
+	entity.Error = cause.Error()
+	entity.FinishedAt = &now
+	_, err := s.repository.UpdateExport(ctx, entity)
+	if err != nil {
+		log.Logger.Error("update failed data export", zap.Int("id", entity.Id), zap.Error(err))
+	}
+	return cause
+}
+
+// Erase anonymizes a user for a right-to-erasure request. Profile, comments, sessions
+// and tokens are scrubbed, contributions lose their content unless they were accepted
+// and the retention policy keeps accepted contributions as magazine content. Audit
+// logs are kept as they are.
+func (s Service) Erase(ctx context.Context, req *EraseRequest) (*EraseResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId == loggedInUser.Id {
+		return nil, apperror.New(apperror.ErrForbidden, "you cannot erase yourself", nil)
+	}
+	entity, err := s.findUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if entity.AnonymizedAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "the personal data of the user was already erased", nil)
+	}
+	contributions, err := s.repository.FindContributionsOfUser(ctx, entity.Id)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.repository.FindCommentsOfUser(ctx, entity.Id)
+	if err != nil {
+		return nil, err
+	}
+	exports, err := s.repository.FindExportsOfUser(ctx, entity.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, export := range exports {
+		if export.Status == ExportPending || export.Status == ExportRunning {
+			return nil, apperror.New(apperror.ErrConflict, "a data export of the user is in progress, erase once it is done", nil)
+		}
+	}
+
+	result := &EraseResponse{UserId: entity.Id, ScrubbedComments: len(comments)}
+	e := &erasure{user: entity, commentContent: erasedCommentContent}
+	var files []string
+	if entity.Avatar != "" {
+		files = append(files, entity.Avatar)
+	}
+	for _, export := range exports {
+		if export.FileKey != "" {
+			files = append(files, export.FileKey)
+		}
+	}
+	for _, c := range contributions {
+		if c.Status.IsPublished() && !s.cfg.PrivacyEraseAcceptedContributions {
+			result.RetainedContributions++
+			continue
+		}
+		result.ScrubbedContributions++
+		e.contributionIds = append(e.contributionIds, c.Id)
+		if c.ArticleId != nil {
+			e.articleIds = append(e.articleIds, *c.ArticleId)
+		}
+		for _, image := range c.Images {
+			files = append(files, image.Key)
+		}
+	}
+	versions, err := s.repository.FindArticleVersions(ctx, e.articleIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		files = append(files, version.LinkOriginal)
+		if version.LinkPdf != "" {
+			files = append(files, version.LinkPdf)
+		}
+	}
+
+	now := time.Now()
+	entity.Name = erasedUserName
+	entity.Email = fmt.Sprintf("erased-%v@anonymized.invalid", entity.Id)
+	entity.Password = ""
+	entity.Avatar = ""
+	entity.NotificationPreferences = user.DefaultNotificationPreferences()
+	entity.TotpSecret = ""
+	entity.TotpEnabledAt = nil
+	entity.TotpRecoveryCodes = nil
+	entity.ExternalSubject = nil
+	entity.Status = user.UserDisable
+	entity.AnonymizedAt = &now
+	err = s.repository.Erase(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rows are gone, a file that cannot be deleted is only orphaned
+	for _, key := range files {
+		err = s.mediaService.DeleteFile(ctx, key)
+		if err != nil {
+			log.Logger.Error("delete file of erased user failed",
+				zap.Int("userId", entity.Id), zap.String("key", key), zap.Error(err))
+			continue
+		}
+		result.DeletedFiles++
+	}
+	s.auditService.RecordAsync(&audit.Entity{
+		ActorId:    &loggedInUser.Id,
+		Action:     audit.UserErased,
+		TargetType: "user",
+		TargetId:   strconv.Itoa(entity.Id),
+		Data: audit.Data{
+			"scrubbedContributions": result.ScrubbedContributions,
+			"retainedContributions": result.RetainedContributions,
+			"scrubbedComments":      result.ScrubbedComments,
+		},
+	})
+	return result, nil
+}
+
+func (s Service) findUser(ctx context.Context, id int) (*user.Entity, error) {
+	entity, err := s.repository.FindUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (s Service) mapExportToResponse(ctx context.Context, entity *ExportEntity) *ExportResponse {
+	res := &ExportResponse{
+		Id:         entity.Id,
+		UserId:     entity.UserId,
+		Status:     entity.Status,
+		Error:      entity.Error,
+		CreatedBy:  entity.CreatedBy,
+		CreatedAt:  entity.CreatedAt,
+		FinishedAt: entity.FinishedAt,
+	}
+	if entity.Status == ExportDone && entity.FileKey != "" {
+		link, err := s.mediaService.GetUrl(ctx, entity.FileKey)
+		if err != nil {
+			log.Logger.Error("get link of data export failed", zap.Int("id", entity.Id), zap.Error(err))
+		} else {
+			res.Link = link
+		}
+	}
+	return res
+}
@@ -0,0 +1,22 @@
+package profile
+
+import "mcm-api/pkg/user"
+
+type ProfileRes struct {
+	*user.UserResponse
+	AvatarLink              string                       `json:"avatarLink,omitempty"`
+	NotificationPreferences user.NotificationPreferences `json:"notificationPreferences"`
+	// ImpersonatedBy is the id of the administrator acting as this user
+	ImpersonatedBy *int `json:"impersonatedBy,omitempty"`
+}
+
+type ProfileUpdateRes struct {
+	*ProfileRes
+	// AccessToken is only set when the change made the current token stale
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+type RecoveryCodesRes struct {
+	// RecoveryCodes are only shown once
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
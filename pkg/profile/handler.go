@@ -0,0 +1,239 @@
+package profile
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/authz"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/media"
+	"mcm-api/pkg/middleware"
+	"mcm-api/pkg/user"
+	"net/http"
+)
+
+type Handler struct {
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
+}
+
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
+	return &Handler{
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
+	}
+}
+
+func (h *Handler) Register(group *echo.Group) {
+	group.Use(h.authenticator.RequireAuthentication(), middleware.RequireSession())
+	group.GET("", h.get)
+	group.PATCH("", h.update)
+	group.POST("/password", h.changePassword)
+	group.PUT("/avatar", h.uploadAvatar)
+	group.DELETE("/avatar", h.removeAvatar)
+	group.POST("/2fa/setup", h.setupTwoFactor)
+	group.POST("/2fa/activate", h.activateTwoFactor)
+	group.POST("/2fa/recovery-codes", h.regenerateRecoveryCodes)
+	group.DELETE("/2fa", h.disableTwoFactor)
+}
+
+// @Tags Me
+// @Summary Get my profile
+// @Description Get profile of the logged in user
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} profile.ProfileRes
+// @Security ApiKeyAuth
+// @Router /me [get]
+func (h *Handler) get(context echo.Context) error {
+	result, err := h.service.Get(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Update my profile
+// @Description Update profile of the logged in user, a new access token is returned when the name changes
+// @Accept  json
+// @Produce  json
+// @Param body body user.ProfileUpdateReq true "update"
+// @Success 200 {object} profile.ProfileUpdateRes
+// @Security ApiKeyAuth
+// @Router /me [patch]
+func (h *Handler) update(context echo.Context) error {
+	body := new(user.ProfileUpdateReq)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.Update(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Change my password
+// @Description Change password of the logged in user, other sessions are logged out
+// @Accept  json
+// @Produce  json
+// @Param body body user.PasswordChangeReq true "change password"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /me/password [post]
+func (h *Handler) changePassword(context echo.Context) error {
+	body := new(user.PasswordChangeReq)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	err = h.service.ChangePassword(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.NoContent(http.StatusOK)
+}
+
+// @Tags Me
+// @Summary Upload my avatar
+// @Description Upload avatar of the logged in user
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "avatar image"
+// @Success 200 {object} profile.ProfileRes
+// @Security ApiKeyAuth
+// @Router /me/avatar [put]
+func (h *Handler) uploadAvatar(context echo.Context) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	file, err := context.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return apperror.HandleError(apperror.New(apperror.ErrInvalid, "missing file", err), context)
+		}
+		return apperror.HandleError(err, context)
+	}
+	open, err := file.Open()
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	defer func() {
+		_ = open.Close()
+	}()
+	result, err := h.service.UploadAvatar(context.Request().Context(), &media.FileUploadOriginalReq{
+		File: open,
+		Size: file.Size,
+		Name: file.Filename,
+		User: loggedInUser,
+	})
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Remove my avatar
+// @Description Remove avatar of the logged in user
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} profile.ProfileRes
+// @Security ApiKeyAuth
+// @Router /me/avatar [delete]
+func (h *Handler) removeAvatar(context echo.Context) error {
+	result, err := h.service.RemoveAvatar(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Set up two-factor authentication
+// @Description Generate a new authenticator secret, it becomes active after /me/2fa/activate
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} authz.TwoFactorSetupResponse
+// @Security ApiKeyAuth
+// @Router /me/2fa/setup [post]
+func (h *Handler) setupTwoFactor(context echo.Context) error {
+	result, err := h.service.SetupTwoFactor(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Activate two-factor authentication
+// @Description Confirm the authenticator with its first code, returns the recovery codes
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorCodeRequest true "code"
+// @Success 200 {object} profile.RecoveryCodesRes
+// @Security ApiKeyAuth
+// @Router /me/2fa/activate [post]
+func (h *Handler) activateTwoFactor(context echo.Context) error {
+	body := new(authz.TwoFactorCodeRequest)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.ActivateTwoFactor(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes, requires a current authenticator code
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorCodeRequest true "code"
+// @Success 200 {object} profile.RecoveryCodesRes
+// @Security ApiKeyAuth
+// @Router /me/2fa/recovery-codes [post]
+func (h *Handler) regenerateRecoveryCodes(context echo.Context) error {
+	body := new(authz.TwoFactorCodeRequest)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.RegenerateRecoveryCodes(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Me
+// @Summary Disable two-factor authentication
+// @Description Not allowed for roles where two-factor authentication is mandatory
+// @Accept  json
+// @Produce  json
+// @Param body body authz.TwoFactorCodeRequest true "authenticator or recovery code"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /me/2fa [delete]
+func (h *Handler) disableTwoFactor(context echo.Context) error {
+	body := new(authz.TwoFactorCodeRequest)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	err = h.service.DisableTwoFactor(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.NoContent(http.StatusOK)
+}
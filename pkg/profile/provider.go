@@ -0,0 +1,5 @@
+package profile
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(InitializeService)
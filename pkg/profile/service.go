@@ -0,0 +1,166 @@
+package profile
+
+import (
+	"context"
+	"mcm-api/config"
+	"mcm-api/pkg/authz"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/media"
+	"mcm-api/pkg/user"
+)
+
+type Service struct {
+	cfg          *config.Config
+	userService  *user.Service
+	authService  *authz.Service
+	mediaService media.Service
+}
+
+func InitializeService(
+	cfg *config.Config,
+	userService *user.Service,
+	authService *authz.Service,
+	mediaService media.Service,
+) *Service {
+	return &Service{
+		cfg:          cfg,
+		userService:  userService,
+		authService:  authService,
+		mediaService: mediaService,
+	}
+}
+
+func (s Service) Get(ctx context.Context) (*ProfileRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userResponse, err := s.userService.FindById(ctx, loggedInUser.Id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.mapToRes(ctx, userResponse)
+	if err != nil {
+		return nil, err
+	}
+	res.ImpersonatedBy = loggedInUser.ImpersonatedBy
+	return res, nil
+}
+
+func (s Service) Update(ctx context.Context, body *user.ProfileUpdateReq) (*ProfileUpdateRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// avatar is changed through its own endpoint
+	body.Avatar = nil
+	userResponse, err := s.userService.UpdateProfile(ctx, loggedInUser.Id, body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.mapToRes(ctx, userResponse)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProfileUpdateRes{ProfileRes: res}
+	if userResponse.Name != loggedInUser.Name {
+		result.AccessToken, err = s.authService.ReissueAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s Service) ChangePassword(ctx context.Context, body *user.PasswordChangeReq) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.userService.ChangePassword(ctx, loggedInUser.Id, body)
+	if err != nil {
+		return err
+	}
+	return s.authService.RevokeOtherSessions(ctx)
+}
+
+func (s Service) SetupTwoFactor(ctx context.Context) (*authz.TwoFactorSetupResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.authService.SetupTwoFactor(ctx, loggedInUser.Id)
+}
+
+func (s Service) ActivateTwoFactor(ctx context.Context, body *authz.TwoFactorCodeRequest) (*RecoveryCodesRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.authService.ActivateTwoFactor(ctx, loggedInUser.Id, body)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodesRes{RecoveryCodes: codes}, nil
+}
+
+func (s Service) DisableTwoFactor(ctx context.Context, body *authz.TwoFactorCodeRequest) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	return s.authService.DisableTwoFactor(ctx, loggedInUser.Id, loggedInUser.Role, body)
+}
+
+func (s Service) RegenerateRecoveryCodes(ctx context.Context, body *authz.TwoFactorCodeRequest) (*RecoveryCodesRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.authService.RegenerateRecoveryCodes(ctx, loggedInUser.Id, body)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodesRes{RecoveryCodes: codes}, nil
+}
+
+func (s Service) UploadAvatar(ctx context.Context, req *media.FileUploadOriginalReq) (*ProfileRes, error) {
+	result, err := s.mediaService.UploadImage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.setAvatar(ctx, result.Key)
+}
+
+func (s Service) RemoveAvatar(ctx context.Context) (*ProfileRes, error) {
+	return s.setAvatar(ctx, "")
+}
+
+func (s Service) setAvatar(ctx context.Context, key string) (*ProfileRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userResponse, err := s.userService.UpdateProfile(ctx, loggedInUser.Id, &user.ProfileUpdateReq{
+		Avatar: &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.mapToRes(ctx, userResponse)
+}
+
+func (s Service) mapToRes(ctx context.Context, userResponse *user.UserResponse) (*ProfileRes, error) {
+	preferences, err := s.userService.GetNotificationPreferences(ctx, userResponse.Id)
+	if err != nil {
+		return nil, err
+	}
+	res := &ProfileRes{
+		UserResponse:            userResponse,
+		NotificationPreferences: *preferences,
+	}
+	if userResponse.Avatar != "" {
+		res.AvatarLink = s.mediaService.GetImageLink(userResponse.Avatar)
+	}
+	return res, nil
+}
@@ -21,3 +21,36 @@ type ArticleUploadedPayload struct {
 type ExportContributeSessionPayload struct {
 	ContributeSessionId int `json:"contributeSessionId"`
 }
+
+type PasswordResetRequestedPayload struct {
+	UserId        int    `json:"userId"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Token         string `json:"token"`
+	ExpireMinutes int    `json:"expireMinutes"`
+}
+
+type UserImportRequestedPayload struct {
+	ImportId int `json:"importId"`
+}
+
+type UserInvitedPayload struct {
+	UserId      int    `json:"userId"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Token       string `json:"token"`
+	ExpireHours int    `json:"expireHours"`
+}
+
+type UserDataExportRequestedPayload struct {
+	ExportId int `json:"exportId"`
+}
+
+type FeedbackOverduePayload struct {
+	ContributionId int    `json:"contributionId"`
+	Title          string `json:"title"`
+	StudentName    string `json:"studentName"`
+	FacultyId      int    `json:"facultyId"`
+	// FeedbackDueAt is formatted by the sender, times do not survive the decoding of the payload
+	FeedbackDueAt string `json:"feedbackDueAt"`
+}
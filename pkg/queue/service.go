@@ -18,6 +18,11 @@ const (
 	ContributionCreated     TopicType = "contribution-created"
 	ArticleUploaded         TopicType = "article-uploaded"
 	ExportContributeSession TopicType = "export-contribute-session"
+	PasswordResetRequested  TopicType = "password-reset-requested"
+	UserImportRequested     TopicType = "user-import-requested"
+	UserInvited             TopicType = "user-invited"
+	UserDataExportRequested TopicType = "user-data-export-requested"
+	FeedbackOverdue         TopicType = "feedback-overdue"
 )
 
 type Message struct {
@@ -103,6 +108,61 @@ func (r *RedisQueue) Pop(ctx context.Context) (*Message, error) {
 			return nil, nil
 		}
 		m.Data = payload
+	case PasswordResetRequested:
+		payload := &PasswordResetRequestedPayload{}
+		err = mapstructure.Decode(m.Data, payload)
+		if err != nil {
+			log.Logger.Error("decode payload failed",
+				zap.Error(err),
+				zap.ByteString("message", messageStr),
+			)
+			return nil, nil
+		}
+		m.Data = payload
+	case UserImportRequested:
+		payload := &UserImportRequestedPayload{}
+		err = mapstructure.Decode(m.Data, payload)
+		if err != nil {
+			log.Logger.Error("decode payload failed",
+				zap.Error(err),
+				zap.ByteString("message", messageStr),
+			)
+			return nil, nil
+		}
+		m.Data = payload
+	case UserInvited:
+		payload := &UserInvitedPayload{}
+		err = mapstructure.Decode(m.Data, payload)
+		if err != nil {
+			log.Logger.Error("decode payload failed",
+				zap.Error(err),
+				zap.ByteString("message", messageStr),
+			)
+			return nil, nil
+		}
+		m.Data = payload
+	case UserDataExportRequested:
+		payload := &UserDataExportRequestedPayload{}
+		err = mapstructure.Decode(m.Data, payload)
+		if err != nil {
+			log.Logger.Error("decode payload failed",
+				zap.Error(err),
+				zap.ByteString("message", messageStr),
+			)
+			return nil, nil
+		}
+		m.Data = payload
+	case FeedbackOverdue:
+		payload := &FeedbackOverduePayload{}
+		err = mapstructure.Decode(m.Data, payload)
+		if err != nil {
+			log.Logger.Error("decode payload failed",
+				zap.Error(err),
+				zap.ByteString("message", messageStr),
+			)
+			return nil, nil
+		}
+		m.Data = payload
 	default:
 		log.Logger.Error("unknown topic", zap.Any("topic", m.Topic))
 		return nil, nil
@@ -0,0 +1,104 @@
+package rbac
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"mcm-api/pkg/common"
+	"mcm-api/pkg/enforcer"
+	"regexp"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type RoleRes struct {
+	Name              enforcer.Role         `json:"name"`
+	Description       string                `json:"description"`
+	RequiresFaculty   bool                  `json:"requiresFaculty"`
+	RequiresTwoFactor bool                  `json:"requiresTwoFactor"`
+	System            bool                  `json:"system"`
+	Permissions       []enforcer.Permission `json:"permissions"`
+	// grant conditions by permission, permissions without condition apply to every resource
+	Conditions map[enforcer.Permission]string `json:"conditions"`
+	common.TrackTime
+}
+
+type PermissionRes struct {
+	Name        enforcer.Permission `json:"name"`
+	Description string              `json:"description"`
+}
+
+type RoleCreateReq struct {
+	Name              enforcer.Role         `json:"name" example:"faculty_editor"`
+	Description       string                `json:"description"`
+	RequiresFaculty   bool                  `json:"requiresFaculty"`
+	RequiresTwoFactor bool                  `json:"requiresTwoFactor"`
+	Permissions       []enforcer.Permission `json:"permissions"`
+	// e.g. {"contribution.read": "faculty && published"}
+	Conditions map[enforcer.Permission]string `json:"conditions"`
+}
+
+func (r *RoleCreateReq) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.Required, validation.Length(3, 50), validation.Match(roleNamePattern)),
+		validation.Field(&r.Description, validation.Length(0, 200)),
+		validation.Field(&r.Permissions, validation.By(validatePermissions)),
+		validation.Field(&r.Conditions, validation.By(validateConditions(r.Permissions))),
+	)
+}
+
+type RoleUpdateReq struct {
+	Description       *string                `json:"description"`
+	RequiresFaculty   *bool                  `json:"requiresFaculty"`
+	RequiresTwoFactor *bool                  `json:"requiresTwoFactor"`
+	Permissions       *[]enforcer.Permission `json:"permissions"`
+	// replaced together with the permissions, the kept permissions keep their conditions
+	// when not given, ignored when permissions are not given
+	Conditions map[enforcer.Permission]string `json:"conditions"`
+}
+
+func (r *RoleUpdateReq) Validate() error {
+	var permissions []enforcer.Permission
+	if r.Permissions != nil {
+		permissions = *r.Permissions
+	}
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Description, validation.NilOrNotEmpty, validation.Length(0, 200)),
+		validation.Field(&r.Permissions, validation.By(validatePermissions)),
+		validation.Field(&r.Conditions, validation.By(validateConditions(permissions))),
+	)
+}
+
+func validatePermissions(value interface{}) error {
+	var permissions []enforcer.Permission
+	switch v := value.(type) {
+	case []enforcer.Permission:
+		permissions = v
+	case *[]enforcer.Permission:
+		if v == nil {
+			return nil
+		}
+		permissions = *v
+	}
+	for _, p := range permissions {
+		if !enforcer.IsPermission(p) {
+			return errors.New("unknown permission " + string(p))
+		}
+	}
+	return nil
+}
+
+func validateConditions(permissions []enforcer.Permission) validation.RuleFunc {
+	return func(value interface{}) error {
+		conditions, _ := value.(map[enforcer.Permission]string)
+		for p, condition := range conditions {
+			if !containsPermission(permissions, p) {
+				return errors.New("condition on a permission that is not granted: " + string(p))
+			}
+			_, err := enforcer.ParseCondition(condition)
+			if err != nil {
+				return errors.New(string(p) + ": " + err.Error())
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+package rbac
+
+import "time"
+
+type RoleEntity struct {
+	Name              string `gorm:"primaryKey"`
+	Description       string
+	RequiresFaculty   bool
+	RequiresTwoFactor bool
+	System            bool
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (e *RoleEntity) TableName() string {
+	return "roles"
+}
+
+type PermissionEntity struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+}
+
+func (e *PermissionEntity) TableName() string {
+	return "permissions"
+}
+
+type RolePermissionEntity struct {
+	RoleName       string `gorm:"primaryKey"`
+	PermissionName string `gorm:"primaryKey"`
+	// see enforcer.ParseCondition, empty when the permission applies to every resource
+	Condition string
+}
+
+func (e *RolePermissionEntity) TableName() string {
+	return "role_permissions"
+}
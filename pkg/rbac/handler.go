@@ -0,0 +1,149 @@
+package rbac
+
+import (
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/middleware"
+	"net/http"
+)
+
+type Handler struct {
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
+}
+
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
+	return &Handler{
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
+	}
+}
+
+func (h *Handler) Register(group *echo.Group) {
+	group.Use(h.authenticator.RequireAuthentication())
+	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadRole))
+	group.GET("/:name", h.getByName, middleware.RequirePermission(enforcer.ReadRole))
+	group.POST("", h.create, middleware.RequirePermission(enforcer.CreateRole))
+	group.PATCH("/:name", h.update, middleware.RequirePermission(enforcer.UpdateRole))
+	group.DELETE("/:name", h.delete, middleware.RequirePermission(enforcer.DeleteRole))
+}
+
+func (h *Handler) RegisterPermissions(group *echo.Group) {
+	group.Use(h.authenticator.RequireAuthentication())
+	group.GET("", h.permissions, middleware.RequirePermission(enforcer.ReadRole))
+}
+
+// @Tags Roles
+// @Summary List roles
+// @Description List roles with their permissions
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} rbac.RoleRes
+// @Security ApiKeyAuth
+// @Router /roles [get]
+func (h *Handler) index(context echo.Context) error {
+	result, err := h.service.FindRoles(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Roles
+// @Summary Show a role
+// @Description get role by name
+// @Accept  json
+// @Produce  json
+// @Param name path string true "role name"
+// @Success 200 {object} rbac.RoleRes
+// @Security ApiKeyAuth
+// @Router /roles/{name} [get]
+func (h *Handler) getByName(context echo.Context) error {
+	result, err := h.service.FindRole(context.Request().Context(), context.Param("name"))
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Roles
+// @Summary Create a role
+// @Description Create a role, changes apply to every api instance immediately
+// @Accept  json
+// @Produce  json
+// @Param body body rbac.RoleCreateReq true "create role"
+// @Success 200 {object} rbac.RoleRes
+// @Security ApiKeyAuth
+// @Router /roles [post]
+func (h *Handler) create(context echo.Context) error {
+	body := new(RoleCreateReq)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.CreateRole(context.Request().Context(), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Roles
+// @Summary Update a role
+// @Description Update a role, permissions are replaced when given
+// @Accept  json
+// @Produce  json
+// @Param name path string true "role name"
+// @Param body body rbac.RoleUpdateReq true "update role"
+// @Success 200 {object} rbac.RoleRes
+// @Security ApiKeyAuth
+// @Router /roles/{name} [patch]
+func (h *Handler) update(context echo.Context) error {
+	body := new(RoleUpdateReq)
+	err := context.Bind(body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	result, err := h.service.UpdateRole(context.Request().Context(), context.Param("name"), body)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
+
+// @Tags Roles
+// @Summary Delete a role
+// @Description Delete a role that is not built-in and not assigned to any user
+// @Accept  json
+// @Produce  json
+// @Param name path string true "role name"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /roles/{name} [delete]
+func (h *Handler) delete(context echo.Context) error {
+	err := h.service.DeleteRole(context.Request().Context(), context.Param("name"))
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.NoContent(http.StatusOK)
+}
+
+// @Tags Roles
+// @Summary List permissions
+// @Description List every permission that can be granted to a role
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} rbac.PermissionRes
+// @Security ApiKeyAuth
+// @Router /permissions [get]
+func (h *Handler) permissions(context echo.Context) error {
+	result, err := h.service.FindPermissions(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
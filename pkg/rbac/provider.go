@@ -0,0 +1,5 @@
+package rbac
+
+import "github.com/google/wire"
+
+var Set = wire.NewSet(InitializeRepository, InitializeService)
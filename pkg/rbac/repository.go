@@ -0,0 +1,99 @@
+package rbac
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func InitializeRepository(db *gorm.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r repository) FindAllRoles(ctx context.Context) ([]*RoleEntity, error) {
+	var entities []*RoleEntity
+	result := r.db.WithContext(ctx).Order("name").Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) FindRole(ctx context.Context, name string) (*RoleEntity, error) {
+	result := new(RoleEntity)
+	db := r.db.WithContext(ctx).First(result, "name = ?", name)
+	return result, db.Error
+}
+
+func (r repository) FindAllRolePermissions(ctx context.Context) ([]*RolePermissionEntity, error) {
+	var entities []*RolePermissionEntity
+	result := r.db.WithContext(ctx).Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) FindPermissionsOfRole(ctx context.Context, name string) ([]*RolePermissionEntity, error) {
+	var entities []*RolePermissionEntity
+	result := r.db.WithContext(ctx).
+		Where("role_name = ?", name).
+		Order("permission_name").
+		Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) FindAllPermissions(ctx context.Context) ([]*PermissionEntity, error) {
+	var entities []*PermissionEntity
+	result := r.db.WithContext(ctx).Order("name").Find(&entities)
+	return entities, result.Error
+}
+
+func (r repository) CreateRole(ctx context.Context, entity *RoleEntity, grants []*RolePermissionEntity) (*RoleEntity, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(entity).Error
+		if err != nil {
+			return err
+		}
+		return replacePermissions(tx, entity.Name, grants)
+	})
+	return entity, err
+}
+
+// UpdateRole saves the role, grants are only replaced when not nil.
+func (r repository) UpdateRole(ctx context.Context, entity *RoleEntity, grants []*RolePermissionEntity) (*RoleEntity, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(entity).Error
+		if err != nil {
+			return err
+		}
+		if grants == nil {
+			return nil
+		}
+		return replacePermissions(tx, entity.Name, grants)
+	})
+	return entity, err
+}
+
+func (r repository) DeleteRole(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Delete(&RoleEntity{}, "name = ?", name).Error
+}
+
+func (r repository) CountUsersOfRole(ctx context.Context, name string) (int64, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Table("users").Where("role = ?", name).Count(&count)
+	return count, result.Error
+}
+
+func replacePermissions(tx *gorm.DB, roleName string, grants []*RolePermissionEntity) error {
+	err := tx.Delete(&RolePermissionEntity{}, "role_name = ?", roleName).Error
+	if err != nil {
+		return err
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+	for _, grant := range grants {
+		grant.RoleName = roleName
+	}
+	return tx.Create(&grants).Error
+}
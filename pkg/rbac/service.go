@@ -0,0 +1,329 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/common"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/log"
+	"time"
+)
+
+const (
+	invalidateChannel = "rbac:invalidate"
+	// safety net in case an invalidation message was missed while redis was unreachable
+	reloadInterval = time.Minute * 5
+)
+
+// permissions the administrator role must keep, otherwise nobody could fix the matrix anymore
+var administratorRequiredPermissions = []enforcer.Permission{enforcer.ReadRole, enforcer.UpdateRole}
+
+type Service struct {
+	cfg        *config.Config
+	repository *repository
+	redis      *redis.Client
+}
+
+func InitializeService(
+	cfg *config.Config,
+	repository *repository,
+	redis *redis.Client,
+) *Service {
+	return &Service{
+		cfg:        cfg,
+		repository: repository,
+		redis:      redis,
+	}
+}
+
+// Load replaces the enforcer role cache with the content of the roles tables.
+func (s Service) Load(ctx context.Context) error {
+	roles, err := s.repository.FindAllRoles(ctx)
+	if err != nil {
+		return err
+	}
+	rolePermissions, err := s.repository.FindAllRolePermissions(ctx)
+	if err != nil {
+		return err
+	}
+	grantsOfRole := groupGrants(rolePermissions)
+	definitions := make([]enforcer.RoleDefinition, len(roles))
+	for i, role := range roles {
+		res := mapRoleToRes(role, grantsOfRole[role.Name])
+		definitions[i] = enforcer.RoleDefinition{
+			Name:              res.Name,
+			Description:       res.Description,
+			RequiresFaculty:   res.RequiresFaculty,
+			RequiresTwoFactor: res.RequiresTwoFactor,
+			System:            res.System,
+			Permissions:       res.Permissions,
+			Conditions:        res.Conditions,
+		}
+	}
+	enforcer.SetRoles(definitions)
+	return nil
+}
+
+// Watch reloads the role cache whenever any replica changes the matrix, it blocks until ctx is done.
+func (s Service) Watch(ctx context.Context) {
+	pubSub := s.redis.Subscribe(ctx, invalidateChannel)
+	defer func() {
+		_ = pubSub.Close()
+	}()
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pubSub.Channel():
+		case <-ticker.C:
+		}
+		err := s.Load(ctx)
+		if err != nil {
+			log.Logger.Error("reload roles failed", zap.Error(err))
+		}
+	}
+}
+
+func (s Service) FindRoles(ctx context.Context) ([]*RoleRes, error) {
+	roles, err := s.repository.FindAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rolePermissions, err := s.repository.FindAllRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grantsOfRole := groupGrants(rolePermissions)
+	result := make([]*RoleRes, len(roles))
+	for i, role := range roles {
+		result[i] = mapRoleToRes(role, grantsOfRole[role.Name])
+	}
+	return result, nil
+}
+
+func (s Service) FindRole(ctx context.Context, name string) (*RoleRes, error) {
+	entity, err := s.findRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.repository.FindPermissionsOfRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return mapRoleToRes(entity, grants), nil
+}
+
+func (s Service) FindPermissions(ctx context.Context) ([]*PermissionRes, error) {
+	entities, err := s.repository.FindAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*PermissionRes, len(entities))
+	for i, entity := range entities {
+		result[i] = &PermissionRes{
+			Name:        enforcer.Permission(entity.Name),
+			Description: entity.Description,
+		}
+	}
+	return result, nil
+}
+
+func (s Service) CreateRole(ctx context.Context, req *RoleCreateReq) (*RoleRes, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.repository.FindRole(ctx, string(req.Name))
+	if err == nil {
+		return nil, apperror.New(apperror.ErrConflict, "role already exists", nil)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	grants := toGrants(req.Permissions, req.Conditions)
+	entity, err := s.repository.CreateRole(ctx, &RoleEntity{
+		Name:              string(req.Name),
+		Description:       req.Description,
+		RequiresFaculty:   req.RequiresFaculty,
+		RequiresTwoFactor: req.RequiresTwoFactor,
+	}, grants)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return mapRoleToRes(entity, grants), nil
+}
+
+func (s Service) UpdateRole(ctx context.Context, name string, req *RoleUpdateReq) (*RoleRes, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.findRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil {
+		entity.Description = *req.Description
+	}
+	if req.RequiresTwoFactor != nil {
+		if entity.System && *req.RequiresTwoFactor != entity.RequiresTwoFactor {
+			return nil, apperror.New(apperror.ErrForbidden, "two-factor requirement of a built-in role cannot be changed", nil)
+		}
+		entity.RequiresTwoFactor = *req.RequiresTwoFactor
+	}
+	if req.RequiresFaculty != nil {
+		if entity.System && *req.RequiresFaculty != entity.RequiresFaculty {
+			return nil, apperror.New(apperror.ErrForbidden, "faculty requirement of a built-in role cannot be changed", nil)
+		}
+		entity.RequiresFaculty = *req.RequiresFaculty
+	}
+	var grants []*RolePermissionEntity
+	if req.Permissions != nil {
+		if enforcer.Role(name) == enforcer.Administrator {
+			for _, required := range administratorRequiredPermissions {
+				if !containsPermission(*req.Permissions, required) {
+					return nil, apperror.New(apperror.ErrInvalid, "administrator must keep permission "+string(required), nil)
+				}
+			}
+		}
+		var stored []*RolePermissionEntity
+		if req.Conditions == nil {
+			stored, err = s.repository.FindPermissionsOfRole(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		grants = updatedGrants(*req.Permissions, req.Conditions, stored)
+	}
+	entity, err = s.repository.UpdateRole(ctx, entity, grants)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return s.FindRole(ctx, name)
+}
+
+func (s Service) DeleteRole(ctx context.Context, name string) error {
+	entity, err := s.findRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if entity.System {
+		return apperror.New(apperror.ErrForbidden, "built-in roles cannot be deleted", nil)
+	}
+	count, err := s.repository.CountUsersOfRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return apperror.New(apperror.ErrConflict, "role is still assigned to users", nil).
+			WithData(map[string]int64{"userCount": count})
+	}
+	err = s.repository.DeleteRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+func (s Service) findRole(ctx context.Context, name string) (*RoleEntity, error) {
+	entity, err := s.repository.FindRole(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "role not found", err)
+		}
+		return nil, err
+	}
+	return entity, nil
+}
+
+// invalidate refreshes the local cache right away and tells the other replicas to do the same.
+func (s Service) invalidate(ctx context.Context) {
+	err := s.Load(ctx)
+	if err != nil {
+		log.Logger.Error("reload roles failed", zap.Error(err))
+	}
+	err = s.redis.Publish(ctx, invalidateChannel, "").Err()
+	if err != nil {
+		log.Logger.Error("publish role invalidation failed", zap.Error(err))
+	}
+}
+
+func mapRoleToRes(entity *RoleEntity, grants []*RolePermissionEntity) *RoleRes {
+	res := &RoleRes{
+		Name:              enforcer.Role(entity.Name),
+		Description:       entity.Description,
+		RequiresFaculty:   entity.RequiresFaculty,
+		RequiresTwoFactor: entity.RequiresTwoFactor,
+		System:            entity.System,
+		Permissions:       make([]enforcer.Permission, len(grants)),
+		Conditions:        make(map[enforcer.Permission]string),
+		TrackTime: common.TrackTime{
+			CreatedAt: entity.CreatedAt,
+			UpdatedAt: entity.UpdatedAt,
+		},
+	}
+	for i, grant := range grants {
+		res.Permissions[i] = enforcer.Permission(grant.PermissionName)
+		if grant.Condition != "" {
+			res.Conditions[res.Permissions[i]] = grant.Condition
+		}
+	}
+	return res
+}
+
+func groupGrants(rolePermissions []*RolePermissionEntity) map[string][]*RolePermissionEntity {
+	result := make(map[string][]*RolePermissionEntity)
+	for _, rp := range rolePermissions {
+		result[rp.RoleName] = append(result[rp.RoleName], rp)
+	}
+	return result
+}
+
+func toGrants(permissions []enforcer.Permission, conditions map[enforcer.Permission]string) []*RolePermissionEntity {
+	result := make([]*RolePermissionEntity, 0, len(permissions))
+	seen := make(map[enforcer.Permission]bool)
+	for _, p := range permissions {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, &RolePermissionEntity{
+				PermissionName: string(p),
+				Condition:      conditions[p],
+			})
+		}
+	}
+	return result
+}
+
+// updatedGrants replaces the grants of a role. Without conditions in the request the
+// permissions that are kept keep their stored condition, so a permission never turns
+// unconditional by omission.
+func updatedGrants(permissions []enforcer.Permission, conditions map[enforcer.Permission]string, stored []*RolePermissionEntity) []*RolePermissionEntity {
+	if conditions == nil {
+		conditions = make(map[enforcer.Permission]string, len(stored))
+		for _, g := range stored {
+			if g.Condition != "" {
+				conditions[enforcer.Permission(g.PermissionName)] = g.Condition
+			}
+		}
+	}
+	return toGrants(permissions, conditions)
+}
+
+func containsPermission(permissions []enforcer.Permission, permission enforcer.Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
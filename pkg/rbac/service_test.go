@@ -0,0 +1,49 @@
+package rbac
+
+import (
+	"mcm-api/pkg/enforcer"
+	"reflect"
+	"testing"
+)
+
+func TestUpdatedGrants(t *testing.T) {
+	stored := []*RolePermissionEntity{
+		{RoleName: "student", PermissionName: string(enforcer.ReadContribution), Condition: "faculty || published"},
+		{RoleName: "student", PermissionName: string(enforcer.UpdateContribution), Condition: "owner"},
+		{RoleName: "student", PermissionName: string(enforcer.CreateContribution)},
+	}
+	cases := []struct {
+		name        string
+		permissions []enforcer.Permission
+		conditions  map[enforcer.Permission]string
+		expected    []*RolePermissionEntity
+	}{
+		{
+			"kept permissions keep their conditions",
+			[]enforcer.Permission{enforcer.ReadContribution, enforcer.CreateContribution, enforcer.CreateComment},
+			nil,
+			[]*RolePermissionEntity{
+				{PermissionName: string(enforcer.ReadContribution), Condition: "faculty || published"},
+				{PermissionName: string(enforcer.CreateContribution)},
+				{PermissionName: string(enforcer.CreateComment)},
+			},
+		},
+		{
+			"given conditions replace the stored ones",
+			[]enforcer.Permission{enforcer.ReadContribution, enforcer.UpdateContribution},
+			map[enforcer.Permission]string{enforcer.ReadContribution: "published"},
+			[]*RolePermissionEntity{
+				{PermissionName: string(enforcer.ReadContribution), Condition: "published"},
+				{PermissionName: string(enforcer.UpdateContribution)},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := updatedGrants(c.permissions, c.conditions, stored)
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	Csv  Format = "csv"
+	Xlsx Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, use csv or xlsx")
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case Csv:
+		return Csv, nil
+	case Xlsx:
+		return Xlsx, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// FormatOf guesses the format from the extension of an uploaded file.
+func FormatOf(fileName string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(fileName), "."))
+}
+
+func (f Format) ContentType() string {
+	if f == Xlsx {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Read returns the cells of a csv file or of the first sheet of a workbook. For
+// workbooks, empty rows are kept so row numbers match what the user sees.
+func Read(r io.ReaderAt, size int64, format Format) ([][]string, error) {
+	switch format {
+	case Csv:
+		reader := csv.NewReader(io.NewSectionReader(r, 0, size))
+		// rows may have trailing empty cells dropped
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case Xlsx:
+		return readXlsx(r, size)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func Write(w io.Writer, format Format, rows [][]string) error {
+	switch format {
+	case Csv:
+		writer := csv.NewWriter(w)
+		for _, row := range rows {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = escapeFormula(cell)
+			}
+			if err := writer.Write(escaped); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case Xlsx:
+		return writeXlsx(w, rows)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// escapeFormula keeps spreadsheet applications from running user supplied values
+// as formulas when a csv file is opened.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// columnName converts a zero based index to a column reference, 0 is A and 26 is AA.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// columnIndex converts a cell reference such as "AB12" to a zero based column index.
+func columnIndex(reference string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range reference {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		letters++
+	}
+	// XFD is the last column spreadsheet applications support
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("invalid cell reference %q", reference)
+	}
+	return index - 1, nil
+}
@@ -0,0 +1,95 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"name", "email", "faculty"},
+		{"Nguyen Van A", "a@example.com", "Computing & <Design>"},
+		{"Tran Thi B", "b@example.com", ""},
+	}
+	for _, format := range []Format{Csv, Xlsx} {
+		t.Run(string(format), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := Write(buf, format, rows); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, rows) {
+				t.Errorf("Read() = %q, want %q", got, rows)
+			}
+		})
+	}
+}
+
+func TestWriteCsvEscapesFormulas(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := Write(buf, Csv, [][]string{{"=HYPERLINK(\"x\")", "-1", "plain"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\"'=HYPERLINK(\"\"x\"\")\",'-1,plain\n"
+	if buf.String() != want {
+		t.Errorf("Write() = %q, want %q", buf.String(), want)
+	}
+}
+
+// TestReadXlsxSharedStrings reads a sheet the way spreadsheet applications save it:
+// shared and rich text strings, numbers, skipped cells and skipped rows.
+func TestReadXlsxSharedStrings(t *testing.T) {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Users" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/users.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si><si><t>faculty</t></si><si><r><t>Le </t></r><r><rPr><b/></rPr><t>Van C</t></r></si></sst>`,
+		"xl/worksheets/users.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="b"><v>1</v></c><c r="C3"><v>12</v></c></row>
+</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Xlsx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "", "faculty"},
+		nil,
+		{"Le Van C", "1", "12"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %q, want %q", got, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != name {
+			t.Errorf("columnName(%v) = %v, want %v", index, got, name)
+		}
+		if got, err := columnIndex(name + "7"); err != nil || got != index {
+			t.Errorf("columnIndex(%v) = %v %v, want %v", name, got, err, index)
+		}
+	}
+}
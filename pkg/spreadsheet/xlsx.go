@@ -0,0 +1,239 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// the uncompressed size a single part of a workbook may have, guards against zip bombs
+const maxXlsxPartSize = 50 << 20
+
+// the size of a sheet in spreadsheet applications
+const (
+	maxXlsxRows    = 1 << 20
+	maxXlsxColumns = 1 << 14
+)
+
+const relationshipNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, either plain or made of formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Reference string    `xml:"r,attr"`
+			Type      string    `xml:"t,attr"`
+			Value     string    `xml:"v"`
+			Inline    *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXlsx(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("file is not a valid xlsx workbook")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	var sharedStrings xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = decodePart(f, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+	sheet, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, errors.New("workbook does not have any sheet")
+	}
+	var worksheet xlsxWorksheet
+	if err = decodePart(sheet, &worksheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range worksheet.Rows {
+		number := row.Number
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		if number <= len(rows) || number > maxXlsxRows {
+			return nil, fmt.Errorf("invalid row number %v", number)
+		}
+		for len(rows) < number {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Reference != "" {
+				column, err = columnIndex(cell.Reference)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if column >= maxXlsxColumns {
+				return nil, fmt.Errorf("invalid cell reference %q", cell.Reference)
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("invalid shared string in cell %v", cell.Reference)
+				}
+				cells[column] = sharedStrings.Items[index].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					cells[column] = cell.Inline.String()
+				}
+			default:
+				cells[column] = cell.Value
+			}
+		}
+		rows[number-1] = cells
+	}
+	return rows, nil
+}
+
+// firstSheetPath follows the workbook relationships to the first sheet, workbooks
+// written by tools that skip them almost always name it sheet1.
+func firstSheetPath(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return fallback
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback
+	}
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if decodePart(workbookFile, &workbook) != nil || decodePart(relsFile, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.Id != workbook.Sheets[0].RelationshipId {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodePart(f *zip.File, v interface{}) error {
+	reader, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	err = xml.NewDecoder(io.LimitReader(reader, maxXlsxPartSize)).Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid workbook part %v: %w", f.Name, err)
+	}
+	return nil
+}
+
+// the smallest set of parts spreadsheet applications accept as a workbook
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + relationshipNamespace + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + relationshipNamespace + `"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + relationshipNamespace + `/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// writeXlsx writes a single sheet workbook, every cell is an inline string.
+func writeXlsx(w io.Writer, rows [][]string) error {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	f, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	b := bufio.NewWriter(f)
+	_, _ = b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		_, _ = fmt.Fprintf(b, `<row r="%d">`, i+1)
+		for j, cell := range row {
+			_, _ = fmt.Fprintf(b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(j), i+1)
+			if err = xml.EscapeText(b, []byte(cell)); err != nil {
+				return err
+			}
+			_, _ = b.WriteString(`</t></is></c>`)
+		}
+		_, _ = b.WriteString(`</row>`)
+	}
+	_, _ = b.WriteString(`</sheetData></worksheet>`)
+	if err = b.Flush(); err != nil {
+		return err
+	}
+	return archive.Close()
+}
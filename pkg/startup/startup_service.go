@@ -2,23 +2,30 @@ package startup
 
 import (
 	"context"
+	"mcm-api/pkg/rbac"
 	"mcm-api/pkg/user"
 	"time"
 )
 
 type Service struct {
 	userService *user.Service
+	rbacService *rbac.Service
 }
 
-func InitializeStartUpService(service *user.Service) *Service {
+func InitializeStartUpService(service *user.Service, rbacService *rbac.Service) *Service {
 	return &Service{
 		userService: service,
+		rbacService: rbacService,
 	}
 }
 
 func (s Service) Run() error {
 	timeout, cancelFunc := context.WithTimeout(context.Background(), time.Second*30)
-	err := s.userService.CreateDefaultAdmin(timeout)
-	cancelFunc()
-	return err
+	defer cancelFunc()
+	err := s.rbacService.Load(timeout)
+	if err != nil {
+		return err
+	}
+	go s.rbacService.Watch(context.Background())
+	return s.userService.CreateDefaultAdmin(timeout)
 }
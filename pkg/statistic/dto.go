@@ -27,19 +27,28 @@ type Session struct {
 	OpenTime         time.Time `json:"openTime"`
 	ClosureTime      time.Time `json:"closureTime"`
 	FinalClosureTime time.Time `json:"finalClosureTime"`
+	// FacultyIds the session is open to, empty when it is open to every faculty
+	FacultyIds []int `json:"facultyIds"`
 }
 
+// ContributionFacultyChart counts the contributions of Sessions, Session is the first
+// of them and is kept for older clients.
 type ContributionFacultyChart struct {
-	Session *Session                   `json:"session"`
-	Data    []*FacultyContributionData `json:"data"`
+	Session  *Session                   `json:"session"`
+	Sessions []*Session                 `json:"sessions"`
+	Data     []*FacultyContributionData `json:"data"`
 }
 
+// ContributionFacultyChartQuery charts the session, by default the running sessions
+// of every faculty, or of the faculty of the logged in user.
 type ContributionFacultyChartQuery struct {
-	Status *contribution.Status `query:"status" enums:"accepted,reviewing,rejected"`
+	Status    *contribution.Status `query:"status" enums:"submitted,under_review,changes_requested,resubmitted,accepted,rejected,selected,withdrawn"`
+	SessionId *int                 `query:"sessionId"`
 }
 
 type ContributionStudentChartQuery struct {
-	Status *contribution.Status `query:"status" enums:"accepted,reviewing,rejected"`
+	Status    *contribution.Status `query:"status" enums:"submitted,under_review,changes_requested,resubmitted,accepted,rejected,selected,withdrawn"`
+	SessionId *int                 `query:"sessionId"`
 }
 
 type ContributionStudentData struct {
@@ -50,8 +59,9 @@ type ContributionStudentData struct {
 }
 
 type ContributionStudentChart struct {
-	Session *Session                   `json:"session"`
-	Data    []*ContributionStudentData `json:"data"`
+	Session  *Session                   `json:"session"`
+	Sessions []*Session                 `json:"sessions"`
+	Data     []*ContributionStudentData `json:"data"`
 }
 
 type ContributionSessionChart struct {
@@ -80,3 +90,25 @@ type StudentFacultyChart struct {
 		StudentCount int    `json:"studentCount"`
 	} `json:"data"`
 }
+
+type FeedbackExceptionQuery struct {
+	SessionId *int `query:"sessionId"`
+}
+
+// FeedbackException is a contribution commented by a coordinator after its feedback
+// deadline, or not commented yet while the deadline passed.
+type FeedbackException struct {
+	ContributionId  int        `json:"contributionId"`
+	Title           string     `json:"title"`
+	FacultyId       *int       `json:"facultyId"`
+	FacultyName     string     `json:"facultyName"`
+	StudentName     string     `json:"studentName"`
+	FeedbackDueAt   time.Time  `json:"feedbackDueAt"`
+	FeedbackGivenAt *time.Time `json:"feedbackGivenAt"`
+	DaysLate        int        `json:"daysLate" gorm:"-"`
+}
+
+type FeedbackExceptionReport struct {
+	Sessions []*Session           `json:"sessions"`
+	Data     []*FeedbackException `json:"data"`
+}
@@ -4,27 +4,31 @@ import (
 	"github.com/labstack/echo/v4"
 	"mcm-api/config"
 	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/middleware"
 	"net/http"
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("/admin-dashboard", h.adminDashboard)
 	group.GET("/contribution-faculty-chart", h.contributionFacultyChart)
 	group.GET("/contribution-student-chart", h.contributionStudentChart)
+	group.GET("/feedback-exceptions", h.feedbackExceptions, middleware.RequirePermission(enforcer.ReadStatistic))
 }
 
 // @Tags Statistics
@@ -86,3 +90,25 @@ func (h Handler) contributionStudentChart(context echo.Context) error {
 	}
 	return context.JSON(http.StatusOK, result)
 }
+
+// @Tags Statistics
+// @Summary Feedback exception report
+// @Description Contributions that did not get coordinator feedback within the deadline
+// @Accept  json
+// @Produce  json
+// @Param params query statistic.FeedbackExceptionQuery false "query"
+// @Success 200 {object} statistic.FeedbackExceptionReport
+// @Security ApiKeyAuth
+// @Router /statistics/feedback-exceptions [get]
+func (h Handler) feedbackExceptions(context echo.Context) error {
+	query := new(FeedbackExceptionQuery)
+	err := context.Bind(query)
+	if err != nil {
+		return err
+	}
+	result, err := h.service.feedbackExceptions(context.Request().Context(), query)
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, result)
+}
@@ -7,6 +7,7 @@ import (
 	"mcm-api/pkg/contribution"
 	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/user"
+	"time"
 )
 
 type repository struct {
@@ -39,7 +40,9 @@ func (r repository) countDisableUser(ctx context.Context) (int64, error) {
 
 func (r repository) totalContributions(ctx context.Context) (int64, error) {
 	var result int64
-	db := r.db.WithContext(ctx).Model(&contribution.Entity{}).Count(&result)
+	db := r.db.WithContext(ctx).Model(&contribution.Entity{}).
+		Where("status <> ?", contribution.Draft).
+		Count(&result)
 	if db.Error != nil {
 		return 0, nil
 	}
@@ -80,18 +83,18 @@ func (r repository) countUserByRole(ctx context.Context) (map[enforcer.Role]int6
 	return resultMap, nil
 }
 
-func (r repository) countContributionGroupByFaculty(ctx context.Context, sessionId *int, status *contribution.Status) ([]*FacultyContributionData, error) {
+func (r repository) countContributionGroupByFaculty(ctx context.Context, sessionIds []int, status *contribution.Status) ([]*FacultyContributionData, error) {
 	var result []*FacultyContributionData
 	db := r.db.WithContext(ctx).Model(&contribution.Entity{}).
 		Select("faculties.id as id, faculties.name as name, count(contributions.id) as count").
-		Joins("left join users on contributions.user_id = users.id").
-		Joins("left join faculties on users.faculty_id = faculties.id").
+		Joins("left join faculties on contributions.faculty_id = faculties.id").
+		Where("contributions.status <> ?", contribution.Draft).
 		Group("faculties.id")
 	if status != nil {
 		db.Where("contributions.status = ?", *status)
 	}
-	if sessionId != nil {
-		db.Where("contributions.contribute_session_id = ?", *sessionId)
+	if sessionIds != nil {
+		db.Where("contributions.contribute_session_id in ?", sessionIds)
 	}
 	db = db.Find(&result)
 	if db.Error != nil {
@@ -100,19 +103,20 @@ func (r repository) countContributionGroupByFaculty(ctx context.Context, session
 	return result, nil
 }
 
-func (r repository) countContributionGroupByStudent(ctx context.Context, sessionId *int, status *contribution.Status) ([]*ContributionStudentData, error) {
+func (r repository) countContributionGroupByStudent(ctx context.Context, sessionIds []int, status *contribution.Status) ([]*ContributionStudentData, error) {
 	var result []*ContributionStudentData
 	db := r.db.WithContext(ctx).Model(&contribution.Entity{}).
 		Select("users.id, users.name, users.email, count(*) as count").
 		Joins("left join users on contributions.user_id = users.id").
+		Where("contributions.status <> ?", contribution.Draft).
 		Group("users.id").
 		Order("count desc").
 		Limit(100)
 	if status != nil {
 		db.Where("contributions.status = ?", *status)
 	}
-	if sessionId != nil {
-		db.Where("contributions.contribute_session_id = ?", *sessionId)
+	if sessionIds != nil {
+		db.Where("contributions.contribute_session_id in ?", sessionIds)
 	}
 	db = db.Find(&result)
 	if db.Error != nil {
@@ -120,3 +124,23 @@ func (r repository) countContributionGroupByStudent(ctx context.Context, session
 	}
 	return result, nil
 }
+
+func (r repository) findFeedbackExceptions(ctx context.Context, sessionIds []int, now time.Time) ([]*FeedbackException, error) {
+	var result []*FeedbackException
+	db := r.db.WithContext(ctx).Model(&contribution.Entity{}).
+		Select("contributions.id as contribution_id, contributions.title, contributions.faculty_id, "+
+			"faculties.name as faculty_name, users.name as student_name, "+
+			"contributions.feedback_due_at, contributions.feedback_given_at").
+		Joins("left join faculties on contributions.faculty_id = faculties.id").
+		Joins("left join users on contributions.user_id = users.id").
+		Where("contributions.status not in ?", []contribution.Status{contribution.Draft, contribution.Withdrawn}).
+		Where("((contributions.feedback_given_at is null and contributions.feedback_due_at <= ?) "+
+			"or contributions.feedback_given_at > contributions.feedback_due_at)", now).
+		Where("contributions.contribute_session_id in ?", sessionIds).
+		Order("contributions.feedback_due_at").
+		Find(&result)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return result, nil
+}
@@ -2,8 +2,10 @@ package statistic
 
 import (
 	"context"
+	"mcm-api/pkg/apperror"
 	"mcm-api/pkg/contributesession"
 	"mcm-api/pkg/enforcer"
+	"time"
 )
 
 type Service struct {
@@ -55,47 +57,132 @@ func (s Service) adminDashboard(ctx context.Context) (*AdminDashboard, error) {
 }
 
 func (s Service) contributionFacultyChart(ctx context.Context, query *ContributionFacultyChartQuery) (*ContributionFacultyChart, error) {
-	session, err := s.contributeSessionService.GetCurrentSession(ctx)
+	sessions, err := s.chartSessions(ctx, query.SessionId)
 	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
 		return &ContributionFacultyChart{
-			Session: nil,
-			Data:    []*FacultyContributionData{},
+			Session:  nil,
+			Sessions: []*Session{},
+			Data:     []*FacultyContributionData{},
 		}, nil
 	}
-	groupByFaculty, err := s.repository.countContributionGroupByFaculty(ctx, &session.Id, query.Status)
+	groupByFaculty, err := s.repository.countContributionGroupByFaculty(ctx, sessionIds(sessions), query.Status)
 	if err != nil {
 		return nil, err
 	}
 	return &ContributionFacultyChart{
-		Session: &Session{
-			Id:               session.Id,
-			OpenTime:         session.OpenTime,
-			ClosureTime:      session.ClosureTime,
-			FinalClosureTime: session.FinalClosureTime,
-		},
-		Data: groupByFaculty,
+		Session:  sessions[0],
+		Sessions: sessions,
+		Data:     groupByFaculty,
 	}, nil
 }
 
 func (s Service) contributionStudentChart(ctx context.Context, query *ContributionStudentChartQuery) (*ContributionStudentChart, error) {
-	session, err := s.contributeSessionService.GetCurrentSession(ctx)
+	sessions, err := s.chartSessions(ctx, query.SessionId)
 	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
 		return &ContributionStudentChart{
-			Session: nil,
-			Data:    []*ContributionStudentData{},
+			Session:  nil,
+			Sessions: []*Session{},
+			Data:     []*ContributionStudentData{},
 		}, nil
 	}
-	groupBy, err := s.repository.countContributionGroupByStudent(ctx, &session.Id, query.Status)
+	groupBy, err := s.repository.countContributionGroupByStudent(ctx, sessionIds(sessions), query.Status)
 	if err != nil {
 		return nil, err
 	}
 	return &ContributionStudentChart{
-		Session: &Session{
-			Id:               session.Id,
-			OpenTime:         session.OpenTime,
-			ClosureTime:      session.ClosureTime,
-			FinalClosureTime: session.FinalClosureTime,
-		},
-		Data: groupBy,
+		Session:  sessions[0],
+		Sessions: sessions,
+		Data:     groupBy,
 	}, nil
 }
+
+// feedbackExceptions reports the contributions of the sessions that missed their feedback
+// deadline, the most overdue first.
+func (s Service) feedbackExceptions(ctx context.Context, query *FeedbackExceptionQuery) (*FeedbackExceptionReport, error) {
+	sessions, err := s.chartSessions(ctx, query.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return &FeedbackExceptionReport{
+			Sessions: []*Session{},
+			Data:     []*FeedbackException{},
+		}, nil
+	}
+	now := time.Now()
+	data, err := s.repository.findFeedbackExceptions(ctx, sessionIds(sessions), now)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range data {
+		end := now
+		if v.FeedbackGivenAt != nil {
+			end = *v.FeedbackGivenAt
+		}
+		v.DaysLate = int(end.Sub(v.FeedbackDueAt).Hours() / 24)
+	}
+	return &FeedbackExceptionReport{
+		Sessions: sessions,
+		Data:     data,
+	}, nil
+}
+
+// chartSessions returns the sessions a chart counts: the requested session, else the
+// running session of the faculty of the logged in user, else every running session
+// since faculties may run on different calendars.
+func (s Service) chartSessions(ctx context.Context, sessionId *int) ([]*Session, error) {
+	if sessionId != nil {
+		session, err := s.contributeSessionService.FindById(ctx, *sessionId)
+		if err != nil {
+			return nil, err
+		}
+		return []*Session{mapSession(session)}, nil
+	}
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if loggedInUser.FacultyId != nil {
+		session, err := s.contributeSessionService.GetCurrentSession(ctx, loggedInUser.FacultyId)
+		if err != nil {
+			if apperror.Is(err, apperror.ErrNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []*Session{mapSession(session)}, nil
+	}
+	current, err := s.contributeSessionService.GetCurrentSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Session, 0, len(current))
+	for _, session := range current {
+		result = append(result, mapSession(session))
+	}
+	return result, nil
+}
+
+func mapSession(session *contributesession.SessionRes) *Session {
+	return &Session{
+		Id:               session.Id,
+		OpenTime:         session.OpenTime,
+		ClosureTime:      session.ClosureTime,
+		FinalClosureTime: session.FinalClosureTime,
+		FacultyIds:       session.FacultyIds,
+	}
+}
+
+func sessionIds(sessions []*Session) []int {
+	ids := make([]int, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.Id)
+	}
+	return ids
+}
@@ -19,3 +19,19 @@ type DataRes struct {
 	Type      ValueType `json:"type" enums:"document,int,string"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+type TermAndConditionRes struct {
+	VersionId int       `json:"versionId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Accepted tells whether the logged in user accepted this version
+	Accepted bool `json:"accepted"`
+}
+
+// TermAcceptance records that a user accepted a version of the term and condition.
+type TermAcceptance struct {
+	UserId         int
+	VersionId      int
+	ContributionId *int
+	IpAddress      string
+}
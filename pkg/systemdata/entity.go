@@ -2,6 +2,9 @@ package systemdata
 
 import "time"
 
+// TermAndConditionKey is the document students accept when they submit a contribution.
+const TermAndConditionKey = "term_and_condition"
+
 type ValueType string
 
 const (
@@ -20,3 +23,29 @@ type Entity struct {
 func (e *Entity) TableName() string {
 	return "system_data"
 }
+
+// TermVersionEntity is a revision of the term and condition, a new one is created
+// whenever the document changes so acceptances keep the text that was accepted.
+type TermVersionEntity struct {
+	Id        int
+	Content   string
+	CreatedBy *int
+	CreatedAt time.Time
+}
+
+func (e TermVersionEntity) TableName() string {
+	return "term_and_condition_versions"
+}
+
+type TermAcceptanceEntity struct {
+	Id             int
+	UserId         int
+	TermVersionId  int
+	ContributionId *int
+	IpAddress      *string
+	AcceptedAt     time.Time
+}
+
+func (e TermAcceptanceEntity) TableName() string {
+	return "term_and_condition_acceptances"
+}
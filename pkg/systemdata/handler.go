@@ -10,20 +10,23 @@ import (
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewHandler(config *config.Config, service *Service) *Handler {
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadSystemData))
+	group.GET("/term-and-condition", h.termAndCondition)
 	group.PUT("/:id", h.update, middleware.RequirePermission(enforcer.UpdateSystemData))
 }
 
@@ -43,6 +46,23 @@ func (h *Handler) index(context echo.Context) error {
 	return context.JSON(http.StatusOK, res)
 }
 
+// @Tags System Data
+// @Summary Get the current term and condition
+// @Description Get the current version of the term and condition, each submission carries
+// @Description the version id the student accepted
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} systemdata.TermAndConditionRes
+// @Security ApiKeyAuth
+// @Router /system-data/term-and-condition [get]
+func (h *Handler) termAndCondition(context echo.Context) error {
+	res, err := h.service.GetTermAndCondition(context.Request().Context())
+	if err != nil {
+		return apperror.HandleError(err, context)
+	}
+	return context.JSON(http.StatusOK, res)
+}
+
 // @Tags System Data
 // @Summary Update system data
 // @Description Update system data
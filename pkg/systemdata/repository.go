@@ -3,6 +3,7 @@ package systemdata
 import (
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -27,6 +28,48 @@ func (r repository) Update(ctx context.Context, entity *Entity) (*Entity, error)
 	return entity, db.Error
 }
 
+// UpdateWithTermVersion saves the term and condition along with its new version.
+func (r repository) UpdateWithTermVersion(ctx context.Context, entity *Entity, version *TermVersionEntity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(entity).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(version).Error
+	})
+}
+
+// LockTermAndCondition runs fn in a transaction that holds the term and condition, no new
+// version is published until it commits.
+func (r repository) LockTermAndCondition(ctx context.Context, fn func(tx *repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			First(&Entity{}, "key = ?", TermAndConditionKey).Error
+		if err != nil {
+			return err
+		}
+		return fn(&repository{db: tx})
+	})
+}
+
+func (r repository) FindLatestTermVersion(ctx context.Context) (*TermVersionEntity, error) {
+	entity := &TermVersionEntity{}
+	db := r.db.WithContext(ctx).Order("id desc").First(entity)
+	return entity, db.Error
+}
+
+func (r repository) HasAcceptedTermVersion(ctx context.Context, userId int, versionId int) (bool, error) {
+	var count int64
+	db := r.db.WithContext(ctx).Model(&TermAcceptanceEntity{}).
+		Where("user_id = ? and term_version_id = ?", userId, versionId).
+		Count(&count)
+	return count > 0, db.Error
+}
+
+func (r repository) CreateTermAcceptance(ctx context.Context, entity *TermAcceptanceEntity) error {
+	return r.db.WithContext(ctx).Create(entity).Error
+}
+
 func (r repository) FindById(ctx context.Context, key string) (*Entity, error) {
 	entity := &Entity{}
 	db := r.db.WithContext(ctx).First(entity, "key = ?", key)
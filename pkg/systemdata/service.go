@@ -6,6 +6,8 @@ import (
 	"gorm.io/gorm"
 	"mcm-api/config"
 	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"time"
 )
 
 type Service struct {
@@ -39,6 +41,9 @@ func (s Service) Update(ctx context.Context, key string, body *DataUpdateReq) er
 		}
 		return err
 	}
+	if key == TermAndConditionKey && entity.Value != body.Value {
+		return s.updateTermAndCondition(ctx, entity, body.Value)
+	}
 	entity.Value = body.Value
 	_, err = s.repository.Update(ctx, entity)
 	if err != nil {
@@ -47,6 +52,103 @@ func (s Service) Update(ctx context.Context, key string, body *DataUpdateReq) er
 	return nil
 }
 
+// updateTermAndCondition versions the new text, students accept it again on their next submission.
+func (s Service) updateTermAndCondition(ctx context.Context, entity *Entity, value string) error {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return err
+	}
+	entity.Value = value
+	return s.repository.UpdateWithTermVersion(ctx, entity, &TermVersionEntity{
+		Content:   value,
+		CreatedBy: &loggedInUser.Id,
+	})
+}
+
+// GetTermAndCondition returns the current version of the term and condition.
+func (s Service) GetTermAndCondition(ctx context.Context) (*TermAndConditionRes, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := findLatestTermVersion(ctx, s.repository)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := s.repository.HasAcceptedTermVersion(ctx, loggedInUser.Id, version.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &TermAndConditionRes{
+		VersionId: version.Id,
+		Content:   version.Content,
+		CreatedAt: version.CreatedAt,
+		Accepted:  accepted,
+	}, nil
+}
+
+// CheckTermVersion rejects the acceptance of a version that is not the current one.
+func (s Service) CheckTermVersion(ctx context.Context, versionId int) error {
+	_, err := checkTermVersion(ctx, s.repository, versionId)
+	return err
+}
+
+// AcceptTermAndCondition records the acceptance, which must be of the current version,
+// in the transaction of write, the change it is given for. The term and condition is
+// held until the transaction commits so it cannot change in between. ContributionId may
+// point to the id of a contribution write creates, it is read after write ran.
+func (s Service) AcceptTermAndCondition(ctx context.Context, acceptance *TermAcceptance, write func(tx *gorm.DB) error) error {
+	locked := false
+	err := s.repository.LockTermAndCondition(ctx, func(tx *repository) error {
+		locked = true
+		version, err := checkTermVersion(ctx, tx, acceptance.VersionId)
+		if err != nil {
+			return err
+		}
+		err = write(tx.db)
+		if err != nil {
+			return err
+		}
+		entity := &TermAcceptanceEntity{
+			UserId:         acceptance.UserId,
+			TermVersionId:  version.Id,
+			ContributionId: acceptance.ContributionId,
+			AcceptedAt:     time.Now(),
+		}
+		if acceptance.IpAddress != "" {
+			entity.IpAddress = &acceptance.IpAddress
+		}
+		return tx.CreateTermAcceptance(ctx, entity)
+	})
+	if !locked && errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.New(apperror.ErrNotFound, "term and condition not found", err)
+	}
+	return err
+}
+
+func checkTermVersion(ctx context.Context, r *repository, versionId int) (*TermVersionEntity, error) {
+	version, err := findLatestTermVersion(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if versionId != version.Id {
+		return nil, apperror.New(apperror.ErrConflict, "the term and condition changed, accept the current version", nil).
+			WithData(map[string]int{"currentVersionId": version.Id})
+	}
+	return version, nil
+}
+
+func findLatestTermVersion(ctx context.Context, r *repository) (*TermVersionEntity, error) {
+	version, err := r.FindLatestTermVersion(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "term and condition not found", err)
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
 func mapEntitiesToRes(entities []*Entity) []*DataRes {
 	var res []*DataRes
 	for _, v := range entities {
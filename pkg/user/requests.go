@@ -1,34 +1,80 @@
 package user
 
 import (
+	"errors"
 	"github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"mcm-api/pkg/common"
 	"mcm-api/pkg/enforcer"
 )
 
+// UserIndexQuery searches name and email, the fields of userListSpec can be filtered
+// and sorted with common.FilterQuery.
 type UserIndexQuery struct {
-	Role enforcer.Role `query:"role" enums:"admin,marketing_manager,marketing_coordinator,student,guest"`
+	Role           enforcer.Role `query:"role" example:"student"`
+	Status         UserStatus    `query:"status" enums:"active,disable,pending"`
+	ServiceAccount *bool         `query:"serviceAccount"`
+	common.FilterQuery
 	common.PaginateQuery
 }
 
+var userListSpec = common.ListSpec{
+	Fields: map[string]common.Field{
+		"id":             {Column: "id", Type: common.IntField, Sortable: true},
+		"name":           {Column: "name", Type: common.StringField, Sortable: true},
+		"email":          {Column: "email", Type: common.StringField, Sortable: true},
+		"role":           {Column: "role", Type: common.StringField, Sortable: true},
+		"status":         {Column: "status", Type: common.StringField, Sortable: true},
+		"facultyId":      {Column: "faculty_id", Type: common.IntField, Sortable: true},
+		"serviceAccount": {Column: "service_account", Type: common.BoolField},
+		"createdAt":      {Column: "created_at", Type: common.TimeField, Sortable: true},
+		"updatedAt":      {Column: "updated_at", Type: common.TimeField, Sortable: true},
+	},
+	SearchColumns: []string{"name", "email"},
+}
+
+// UserExportQuery filters the exported users the same way UserIndexQuery filters the list.
+type UserExportQuery struct {
+	Format         string        `query:"format" enums:"csv,xlsx" example:"csv"`
+	Role           enforcer.Role `query:"role" example:"student"`
+	Status         UserStatus    `query:"status" enums:"active,disable,pending"`
+	ServiceAccount *bool         `query:"serviceAccount"`
+	common.FilterQuery
+}
+
 type UserCreateReq struct {
-	Name      string        `json:"name"`
-	Email     string        `json:"email"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Password is left empty to invite the user, they receive a link to choose it
+	// and stay pending until they do
 	Password  string        `json:"password"`
-	Role      enforcer.Role `json:"role" enums:"admin,marketing_manager,marketing_coordinator,student,guest"`
+	Role      enforcer.Role `json:"role" example:"student"`
 	Status    UserStatus    `json:"status" enums:"active,disable"`
 	FacultyId *int          `json:"facultyId"`
+	// ServiceAccount creates an account for integrations, it has no password and
+	// only authenticates with api keys
+	ServiceAccount bool `json:"serviceAccount"`
+}
+
+// IsInvitation reports whether the user chooses their own password.
+func (c *UserCreateReq) IsInvitation() bool {
+	return c.Password == "" && !c.ServiceAccount
 }
 
 func (c *UserCreateReq) Validate() error {
 	return validation.ValidateStruct(c,
 		validation.Field(&c.Name, validation.Required, validation.Length(5, 50)),
 		validation.Field(&c.Email, validation.Required, is.Email),
-		validation.Field(&c.Password, validation.Required, validation.Length(5, 50)),
-		validation.Field(&c.Role, validation.Required, validation.In(
-			enforcer.Guest, enforcer.Student, enforcer.MarketingCoordinator, enforcer.MarketingManager, enforcer.Administrator)),
-		validation.Field(&c.Status, validation.Required, validation.In(UserActive, UserDisable)),
+		validation.Field(&c.Password,
+			validation.Empty.When(c.ServiceAccount),
+			validation.Length(5, 50),
+		),
+		validation.Field(&c.Role, validation.Required, validation.By(validateRole)),
+		validation.Field(&c.Status,
+			validation.Required.When(!c.IsInvitation()),
+			validation.In(UserActive, UserDisable),
+			validation.When(c.IsInvitation(), validation.In(UserActive).Error("an invited user cannot be disabled")),
+		),
 		validation.Field(&c.FacultyId, validation.Required.When(isRoleRequiredFaculty(c.Role))),
 	)
 }
@@ -47,9 +93,8 @@ func (c UserUpdateReq) Validate() error {
 		validation.Field(&c.Name, validation.Length(5, 50)),
 		validation.Field(&c.Email, is.Email),
 		validation.Field(&c.Password, validation.Length(5, 50)),
-		validation.Field(&c.Role, validation.In(
-			enforcer.Guest, enforcer.Student, enforcer.MarketingCoordinator, enforcer.MarketingManager, enforcer.Administrator)),
-		validation.Field(&c.Status, validation.Required, validation.In(UserActive, UserDisable)),
+		validation.Field(&c.Role, validation.By(validateRole)),
+		validation.Field(&c.Status, validation.In(UserActive, UserDisable)),
 		validation.Field(&c.FacultyId),
 	)
 }
@@ -63,19 +108,71 @@ func (u UserUpdateStatus) Validate() error {
 		validation.Field(&u.Status, validation.Required, validation.In(UserActive, UserDisable)))
 }
 
+type ProfileUpdateReq struct {
+	Name                    *string                        `json:"name"`
+	Avatar                  *string                        `json:"-"`
+	NotificationPreferences *NotificationPreferencesUpdate `json:"notificationPreferences"`
+}
+
+// NotificationPreferencesUpdate changes the flags that are set and keeps the others.
+type NotificationPreferencesUpdate struct {
+	NewContributionEmail *bool `json:"newContributionEmail"`
+}
+
+func (u NotificationPreferencesUpdate) applyTo(preferences *NotificationPreferences) {
+	if u.NewContributionEmail != nil {
+		preferences.NewContributionEmail = *u.NewContributionEmail
+	}
+}
+
+func (p ProfileUpdateReq) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Name, validation.NilOrNotEmpty, validation.Length(5, 50)),
+	)
+}
+
+type PasswordChangeReq struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+func (p PasswordChangeReq) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CurrentPassword, validation.Required),
+		validation.Field(&p.NewPassword, validation.Required, validation.Length(5, 50)),
+	)
+}
+
+// ExternalUserReq carries the identity asserted by the single sign-on provider.
+// Role and FacultyName are empty when the provider groups do not map to any.
+type ExternalUserReq struct {
+	// Subject is the stable id of the user at the provider, accounts are matched on it
+	Subject     string
+	Email       string
+	Name        string
+	Role        enforcer.Role
+	FacultyName string
+	// Provision creates the account on first sight instead of requiring an existing one
+	Provision bool
+}
+
 func isRoleRequiredFaculty(role enforcer.Role) bool {
-	switch role {
-	case enforcer.Administrator:
-		return false
-	case enforcer.MarketingManager:
-		return false
-	case enforcer.MarketingCoordinator:
-		return true
-	case enforcer.Student:
-		return true
-	case enforcer.Guest:
-		return true
-	default:
-		return true
+	return enforcer.RequiresFaculty(role)
+}
+
+func validateRole(value interface{}) error {
+	var role enforcer.Role
+	switch v := value.(type) {
+	case enforcer.Role:
+		role = v
+	case *enforcer.Role:
+		if v == nil {
+			return nil
+		}
+		role = *v
+	}
+	if role != "" && !enforcer.RoleExists(role) {
+		return errors.New("unknown role")
 	}
+	return nil
 }
@@ -3,6 +3,7 @@ package user
 import (
 	"mcm-api/pkg/common"
 	"mcm-api/pkg/enforcer"
+	"time"
 )
 
 type UserResponse struct {
@@ -11,10 +12,35 @@ type UserResponse struct {
 	Email     string        `json:"email"`
 	FacultyId *int          `json:"facultyId"`
 	Role      enforcer.Role `json:"role"`
-	Status    UserStatus    `json:"status"`
+	Status    UserStatus    `json:"status" enums:"active,disable,pending"`
+	Avatar    string        `json:"avatar,omitempty"`
+	// TwoFactorEnabled is true once the user activated an authenticator app
+	TwoFactorEnabled bool `json:"twoFactorEnabled"`
+	ServiceAccount   bool `json:"serviceAccount"`
+	// AnonymizedAt is set once the personal data of the user was erased
+	AnonymizedAt *time.Time `json:"anonymizedAt,omitempty"`
 	common.TrackTime
 }
 
+// FacultyMembershipResponse is a period the user belonged to a faculty, EndedAt is
+// empty for the current faculty.
+type FacultyMembershipResponse struct {
+	FacultyId int        `json:"facultyId"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	// CreatedBy is the user who made the transfer, it is empty for the initial faculty
+	// and for changes made by single sign-on
+	CreatedBy *int `json:"createdBy,omitempty"`
+}
+
+// TwoFactor is the authenticator state of a user, Secret is set but EnabledAt is nil
+// while an enrolment waits for its first code.
+type TwoFactor struct {
+	Secret        string
+	EnabledAt     *time.Time
+	RecoveryCodes []string
+}
+
 type PaginateComposition struct {
 	common.PaginateResponse
 	Data []UserResponse `json:"data"`
@@ -1,6 +1,9 @@
 package user
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"mcm-api/pkg/enforcer"
 	"time"
 )
@@ -10,20 +13,120 @@ type UserStatus string
 const (
 	UserActive  UserStatus = "active"
 	UserDisable UserStatus = "disable"
+	// UserPending users were invited and have not chosen their password yet
+	UserPending UserStatus = "pending"
 )
 
 type Entity struct {
-	Id        int
-	Name      string
-	Email     string
-	Password  string
-	FacultyId *int
-	Role      enforcer.Role
-	Status    UserStatus
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id                      int
+	Name                    string
+	Email                   string
+	Password                string
+	FacultyId               *int
+	Role                    enforcer.Role
+	Status                  UserStatus
+	Avatar                  string
+	NotificationPreferences NotificationPreferences
+	TotpSecret              string
+	TotpEnabledAt           *time.Time
+	TotpRecoveryCodes       RecoveryCodes
+	ServiceAccount          bool
+	ExternalSubject         *string
+	ExternalProvisioned     bool
+	AnonymizedAt            *time.Time
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
 }
 
 func (e *Entity) TableName() string {
 	return "users"
 }
+
+// InvitationEntity is an invitation to choose a password, only the latest one of a
+// user can be accepted.
+type InvitationEntity struct {
+	Id          int
+	UserId      int
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time
+	CancelledAt *time.Time
+	CreatedBy   *int
+	CreatedAt   time.Time
+}
+
+func (e *InvitationEntity) TableName() string {
+	return "user_invitations"
+}
+
+func (e *InvitationEntity) IsUsable(now time.Time) bool {
+	return e.AcceptedAt == nil && e.CancelledAt == nil && now.Before(e.ExpiresAt)
+}
+
+// FacultyMembershipEntity is a period a user belonged to a faculty, the open one has
+// no EndedAt and matches the faculty of the user.
+type FacultyMembershipEntity struct {
+	Id        int
+	UserId    int
+	FacultyId int
+	StartedAt time.Time
+	EndedAt   *time.Time
+	CreatedBy *int
+}
+
+func (e *FacultyMembershipEntity) TableName() string {
+	return "faculty_memberships"
+}
+
+// NotificationPreferences is stored as jsonb, keys missing from the stored
+// document fall back to DefaultNotificationPreferences.
+type NotificationPreferences struct {
+	NewContributionEmail bool `json:"newContributionEmail"`
+}
+
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		NewContributionEmail: true,
+	}
+}
+
+func (p NotificationPreferences) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *NotificationPreferences) Scan(value interface{}) error {
+	*p = DefaultNotificationPreferences()
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return errors.New("unsupported type of notification preferences")
+	}
+}
+
+// RecoveryCodes holds the sha256 hashes of the unused two-factor recovery codes.
+type RecoveryCodes []string
+
+func (c RecoveryCodes) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(c))
+}
+
+func (c *RecoveryCodes) Scan(value interface{}) error {
+	*c = nil
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return errors.New("unsupported type of recovery codes")
+	}
+}
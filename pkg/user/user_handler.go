@@ -1,40 +1,49 @@
 package user
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"mcm-api/config"
 	"mcm-api/pkg/apperror"
 	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/middleware"
+	"mcm-api/pkg/spreadsheet"
 	"net/http"
 	"strconv"
 )
 
 type Handler struct {
-	config  *config.Config
-	service *Service
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
 }
 
-func NewUserHandler(config *config.Config, service *Service) *Handler {
+func NewUserHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
 	return &Handler{
-		config:  config,
-		service: service,
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
 	}
 }
 
 func (h *Handler) Register(group *echo.Group) {
-	group.Use(middleware.RequireAuthentication(h.config.JwtSecret))
+	group.Use(h.authenticator.RequireAuthentication())
 	group.GET("", h.index, middleware.RequirePermission(enforcer.ReadUser))
+	group.GET("/export", h.export, middleware.RequirePermission(enforcer.ReadUser))
 	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.ReadUser))
+	group.GET("/:id/faculty-history", h.getFacultyHistory, middleware.RequirePermission(enforcer.ReadUser))
 	group.POST("", h.createUser, middleware.RequirePermission(enforcer.CreateUser))
 	group.POST("/:id/status", h.updateStatus, middleware.RequirePermission(enforcer.UpdateUser))
+	group.POST("/:id/invitation", h.resendInvitation, middleware.RequirePermission(enforcer.UpdateUser))
+	group.DELETE("/:id/invitation", h.cancelInvitation, middleware.RequirePermission(enforcer.UpdateUser))
 	group.PATCH("/:id", h.updateUser, middleware.RequirePermission(enforcer.UpdateUser))
 	group.DELETE("/:id", h.deleteUser, middleware.RequirePermission(enforcer.DeleteUser))
 }
 
 // @Tags Users
 // @Summary List users
-// @Description List users
+// @Description List users, search by name or email and filter or sort by id, name, email, role, status, facultyId, serviceAccount, createdAt and updatedAt
 // @Accept  json
 // @Produce  json
 // @Param params query user.UserIndexQuery false "user index query"
@@ -58,6 +67,41 @@ func (h *Handler) index(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, paginateRes)
 }
 
+// @Tags Users
+// @Summary Export users
+// @Description Export the users matching the filters as a csv or xlsx file
+// @Accept  json
+// @Produce  octet-stream
+// @Param params query user.UserExportQuery false "user export query"
+// @Success 200 {file} file
+// @Security ApiKeyAuth
+// @Router /users/export [get]
+func (h *Handler) export(ctx echo.Context) error {
+	query := new(UserExportQuery)
+	err := ctx.Bind(query)
+	if err != nil {
+		return err
+	}
+	format := spreadsheet.Csv
+	if query.Format != "" {
+		format, err = spreadsheet.ParseFormat(query.Format)
+		if err != nil {
+			return apperror.HandleError(apperror.New(apperror.ErrInvalid, err.Error(), err), ctx)
+		}
+	}
+	rows, err := h.service.Export(ctx.Request().Context(), query)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	buf := new(bytes.Buffer)
+	err = spreadsheet.Write(buf, format, rows)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="users.%v"`, format))
+	return ctx.Blob(http.StatusOK, format.ContentType(), buf.Bytes())
+}
+
 // @Tags Users
 // @Summary Show a user
 // @Description get user by ID
@@ -81,9 +125,32 @@ func (h *Handler) getById(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, res)
 }
 
+// @Tags Users
+// @Summary Show the faculty history of a user
+// @Description List the faculties a user belonged to with their effective dates, the current one first
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200 {array} user.FacultyMembershipResponse
+// @Security ApiKeyAuth
+// @Router /users/{id}/faculty-history [get]
+func (h *Handler) getFacultyHistory(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.
+			New(apperror.ErrInvalid, "Id should be string", err).
+			ToResponse(ctx)
+	}
+	res, err := h.service.FindFacultyHistory(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
 // @Tags Users
 // @Summary Create a user
-// @Description Create a user
+// @Description Create a user, without password the user is invited by email to choose one and stays pending until then
 // @Accept  json
 // @Produce  json
 // @Param user body user.UserCreateReq true "create user"
@@ -182,3 +249,49 @@ func (h Handler) updateStatus(ctx echo.Context) error {
 	}
 	return ctx.NoContent(http.StatusOK)
 }
+
+// @Tags Users
+// @Summary Resend an invitation
+// @Description Send a new invitation link to a pending user, the previous link stops working
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /users/{id}/invitation [post]
+func (h Handler) resendInvitation(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.
+			New(apperror.ErrInvalid, "Id should be string", err).
+			ToResponse(ctx)
+	}
+	err = h.service.ResendInvitation(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// @Tags Users
+// @Summary Cancel an invitation
+// @Description Make the invitation link of a pending user unusable
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200
+// @Security ApiKeyAuth
+// @Router /users/{id}/invitation [delete]
+func (h Handler) cancelInvitation(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.
+			New(apperror.ErrInvalid, "Id should be string", err).
+			ToResponse(ctx)
+	}
+	err = h.service.CancelInvitation(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
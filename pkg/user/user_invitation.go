@@ -0,0 +1,176 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/queue"
+	"strconv"
+	"time"
+)
+
+const (
+	// invitation ttl, unit: hours
+	invitationTtl = 72
+
+	invitationTokenType = "invitation"
+)
+
+// ResendInvitation cancels the open invitation of a pending user and sends a new one.
+func (s *Service) ResendInvitation(ctx context.Context, id int) error {
+	entity, err := s.findPendingUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.invite(ctx, entity)
+}
+
+// CancelInvitation makes the invitation link of a pending user unusable, the user
+// stays pending until a new invitation is sent.
+func (s *Service) CancelInvitation(ctx context.Context, id int) error {
+	entity, err := s.findPendingUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repository.CancelInvitations(ctx, entity.Id)
+}
+
+// AcceptInvitation sets the password chosen by an invited user and activates the account.
+func (s *Service) AcceptInvitation(ctx context.Context, token string, password string) (*UserResponse, error) {
+	invalid := apperror.New(apperror.ErrInvalid, "invalid or expired invitation", nil)
+	userId, invitationId, err := s.parseInvitationToken(token)
+	if err != nil {
+		return nil, invalid
+	}
+	invitation, err := s.repository.FindInvitationById(ctx, invitationId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if invitation.UserId != userId || !invitation.IsUsable(time.Now()) {
+		return nil, invalid
+	}
+	entity, err := s.repository.FindById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if entity.Status != UserPending {
+		return nil, invalid
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return nil, err
+	}
+	entity.Password = string(hashedPassword)
+	entity.Status = UserActive
+	err = s.repository.AcceptInvitation(ctx, invitation, entity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	return mapEntityToResponse(entity), nil
+}
+
+func (s *Service) findPendingUser(ctx context.Context, id int) (*Entity, error) {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	if entity.Status != UserPending {
+		return nil, apperror.New(apperror.ErrConflict, "the user already accepted the invitation", nil)
+	}
+	return entity, nil
+}
+
+// invite replaces the open invitation of the user and emails the new link.
+func (s *Service) invite(ctx context.Context, entity *Entity) error {
+	err := s.repository.CancelInvitations(ctx, entity.Id)
+	if err != nil {
+		return err
+	}
+	invitation := &InvitationEntity{
+		UserId:    entity.Id,
+		ExpiresAt: time.Now().Add(time.Hour * invitationTtl),
+	}
+	// invitations sent by the worker, e.g. for imports, have no logged in user
+	if loggedInUser, err := enforcer.GetLoggedInUser(ctx); err == nil {
+		invitation.CreatedBy = &loggedInUser.Id
+	}
+	invitation, err = s.repository.CreateInvitation(ctx, invitation)
+	if err != nil {
+		return err
+	}
+	token, err := s.generateInvitationToken(invitation)
+	if err != nil {
+		return err
+	}
+	err = s.queue.Add(ctx, &queue.Message{
+		Topic: queue.UserInvited,
+		Data: &queue.UserInvitedPayload{
+			UserId:      entity.Id,
+			Name:        entity.Name,
+			Email:       entity.Email,
+			Token:       token,
+			ExpireHours: invitationTtl,
+		},
+	})
+	if err != nil {
+		log.Logger.Error("queue invitation email failed", zap.Int("userId", entity.Id), zap.Error(err))
+		return apperror.New(apperror.ErrInternal, "the invitation email could not be sent, please try again", err)
+	}
+	return nil
+}
+
+// the invitation token has no session id, so the authentication middleware never accepts it
+func (s *Service) generateInvitationToken(invitation *InvitationEntity) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+	claims["sub"] = strconv.Itoa(invitation.UserId)
+	claims["typ"] = invitationTokenType
+	claims["jti"] = strconv.Itoa(invitation.Id)
+	claims["exp"] = invitation.ExpiresAt.Unix()
+	return token.SignedString([]byte(s.cfg.JwtSecret))
+}
+
+func (s *Service) parseInvitationToken(invitationToken string) (int, int, error) {
+	token, err := jwt.Parse(invitationToken, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(s.cfg.JwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, 0, errors.New("invalid invitation token")
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if typ, _ := claims["typ"].(string); typ != invitationTokenType {
+		return 0, 0, errors.New("not an invitation token")
+	}
+	sub, _ := claims["sub"].(string)
+	userId, err := strconv.Atoi(sub)
+	if err != nil {
+		return 0, 0, err
+	}
+	jti, _ := claims["jti"].(string)
+	invitationId, err := strconv.Atoi(jti)
+	if err != nil {
+		return 0, 0, err
+	}
+	return userId, invitationId, nil
+}
@@ -3,7 +3,10 @@ package user
 import (
 	"context"
 	"gorm.io/gorm"
+	"mcm-api/pkg/common"
 	"mcm-api/pkg/enforcer"
+	"strings"
+	"time"
 )
 
 type repository struct {
@@ -24,22 +27,56 @@ func (r *repository) FindById(ctx context.Context, id int) (*Entity, error) {
 
 func (r *repository) FindByEmail(ctx context.Context, email string) (*Entity, error) {
 	var result Entity
-	db := r.db.WithContext(ctx).First(&result, "email = ?", email)
+	db := r.db.WithContext(ctx).First(&result, "lower(email) = ?", strings.ToLower(email))
 	return &result, db.Error
 }
 
+func (r *repository) FindByExternalSubject(ctx context.Context, subject string) (*Entity, error) {
+	var result Entity
+	db := r.db.WithContext(ctx).First(&result, "external_subject = ?", subject)
+	return &result, db.Error
+}
+
+// ConsumeRecoveryCode removes the hash from the unused recovery codes of the user, it
+// returns false when the code is not among them, e.g. a concurrent login used it first.
+func (r *repository) ConsumeRecoveryCode(ctx context.Context, id int, hash string) (bool, error) {
+	db := r.db.WithContext(ctx).Model(&Entity{}).
+		Where("id = ? and totp_recovery_codes @> jsonb_build_array(?::text)", id, hash).
+		UpdateColumn("totp_recovery_codes", gorm.Expr("totp_recovery_codes - ?::text", hash))
+	return db.RowsAffected > 0, db.Error
+}
+
 func (r *repository) Create(ctx context.Context, entity *Entity) error {
-	save := r.db.WithContext(ctx).Create(entity)
-	return save.Error
+	// new accounts start with the default preferences, users opt out on /me
+	if entity.NotificationPreferences == (NotificationPreferences{}) {
+		entity.NotificationPreferences = DefaultNotificationPreferences()
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(entity).Error
+		if err != nil {
+			return err
+		}
+		return syncFacultyMembership(ctx, tx, entity)
+	})
 }
 
-func (r *repository) FindAndCount(ctx context.Context, query *UserIndexQuery) ([]*Entity, int64, error) {
-	db := r.db.WithContext(ctx).Table("users")
-	if query.Role != "" {
-		db.Where("role = ?", query.Role)
+// FindByEmails returns the users whose email is one of the given, ignoring case.
+func (r *repository) FindByEmails(ctx context.Context, emails []string) ([]*Entity, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
 	}
+	var entities []*Entity
+	result := r.db.WithContext(ctx).Where("lower(email) in ?", lowered).Find(&entities)
+	return entities, result.Error
+}
+
+func (r *repository) FindAndCount(ctx context.Context, query *UserIndexQuery, filter *common.ListFilter) ([]*Entity, int64, error) {
+	db := r.db.WithContext(ctx).Table("users")
+	applyIndexQuery(db, query, filter)
 	var count int64
 	db.Count(&count)
+	filter.ApplyOrder(db, "id")
 	db.Limit(query.GetLimit())
 	db.Offset(query.GetOffSet())
 	var entities []*Entity
@@ -47,17 +84,172 @@ func (r *repository) FindAndCount(ctx context.Context, query *UserIndexQuery) ([
 	return entities, count, result.Error
 }
 
+// FindAll returns every user matching the filters of the query, pagination is ignored.
+func (r *repository) FindAll(ctx context.Context, query *UserIndexQuery, filter *common.ListFilter) ([]*Entity, error) {
+	db := r.db.WithContext(ctx).Table("users")
+	applyIndexQuery(db, query, filter)
+	filter.ApplyOrder(db, "id")
+	var entities []*Entity
+	result := db.Find(&entities)
+	return entities, result.Error
+}
+
+func applyIndexQuery(db *gorm.DB, query *UserIndexQuery, filter *common.ListFilter) {
+	if query.Role != "" {
+		db.Where("role = ?", query.Role)
+	}
+	if query.Status != "" {
+		db.Where("status = ?", query.Status)
+	}
+	if query.ServiceAccount != nil {
+		db.Where("service_account = ?", *query.ServiceAccount)
+	}
+	filter.ApplyWhere(db)
+}
+
 func (r *repository) FindAllUserOfFaculty(ctx context.Context, role enforcer.Role, id int) ([]*Entity, error) {
 	var entities []*Entity
 	result := r.db.WithContext(ctx).Where("role = ? and faculty_id = ?", role, id).Find(&entities)
 	return entities, result.Error
 }
 
+func (r *repository) FindActiveUsersOfRole(ctx context.Context, role enforcer.Role) ([]*Entity, error) {
+	var entities []*Entity
+	result := r.db.WithContext(ctx).Where("role = ? and status = ?", role, UserActive).Find(&entities)
+	return entities, result.Error
+}
+
 func (r *repository) Update(ctx context.Context, entity *Entity) (*Entity, error) {
-	db := r.db.WithContext(ctx).Save(entity)
-	return entity, db.Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(entity).Error
+		if err != nil {
+			return err
+		}
+		err = syncFacultyMembership(ctx, tx, entity)
+		if err != nil {
+			return err
+		}
+		return syncCoordinatorAssignment(tx, entity)
+	})
+	return entity, err
+}
+
+// syncFacultyMembership closes the open membership of the user when the faculty changed
+// and opens one for the new faculty, the logged in user is recorded as the author.
+func syncFacultyMembership(ctx context.Context, tx *gorm.DB, entity *Entity) error {
+	var open []*FacultyMembershipEntity
+	err := tx.Where("user_id = ? and ended_at is null", entity.Id).Find(&open).Error
+	if err != nil {
+		return err
+	}
+	if len(open) == 1 && entity.FacultyId != nil && open[0].FacultyId == *entity.FacultyId {
+		return nil
+	}
+	now := time.Now()
+	if len(open) > 0 {
+		err = tx.Model(&FacultyMembershipEntity{}).
+			Where("user_id = ? and ended_at is null", entity.Id).
+			Update("ended_at", now).Error
+		if err != nil {
+			return err
+		}
+	}
+	if entity.FacultyId == nil {
+		return nil
+	}
+	membership := &FacultyMembershipEntity{
+		UserId:    entity.Id,
+		FacultyId: *entity.FacultyId,
+		StartedAt: now,
+	}
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err == nil {
+		membership.CreatedBy = &loggedInUser.Id
+	}
+	return tx.Create(membership).Error
+}
+
+// syncCoordinatorAssignment removes the faculty coordinator assignments a user no
+// longer qualifies for after a transfer or a change of role.
+func syncCoordinatorAssignment(tx *gorm.DB, entity *Entity) error {
+	if entity.Role != enforcer.MarketingCoordinator || entity.FacultyId == nil {
+		return tx.Exec("delete from faculty_coordinators where user_id = ?", entity.Id).Error
+	}
+	return tx.Exec("delete from faculty_coordinators where user_id = ? and faculty_id <> ?",
+		entity.Id, *entity.FacultyId).Error
+}
+
+// FindAssignedCoordinators returns the active users assigned as coordinators of the faculty.
+func (r *repository) FindAssignedCoordinators(ctx context.Context, facultyId int) ([]*Entity, error) {
+	var entities []*Entity
+	result := r.db.WithContext(ctx).
+		Joins("join faculty_coordinators on faculty_coordinators.user_id = users.id").
+		Where("faculty_coordinators.faculty_id = ? and users.status = ?", facultyId, UserActive).
+		Find(&entities)
+	return entities, result.Error
+}
+
+// FindStudentsWithoutContribution returns the active students of the faculties, or of
+// every faculty when facultyIds is empty, who did not submit to the session, drafts do
+// not count.
+func (r *repository) FindStudentsWithoutContribution(ctx context.Context, sessionId int, facultyIds []int) ([]*Entity, error) {
+	var entities []*Entity
+	builder := r.db.WithContext(ctx).
+		Where("role = ? and status = ?", enforcer.Student, UserActive).
+		Where("not exists(select 1 from contributions c "+
+			"where c.user_id = users.id and c.contribute_session_id = ? and c.status <> 'draft')", sessionId)
+	if len(facultyIds) > 0 {
+		builder.Where("faculty_id in ?", facultyIds)
+	}
+	result := builder.Order("id").Find(&entities)
+	return entities, result.Error
+}
+
+// FindFacultyMemberships returns the faculty history of a user, the latest first.
+func (r *repository) FindFacultyMemberships(ctx context.Context, userId int) ([]*FacultyMembershipEntity, error) {
+	var entities []*FacultyMembershipEntity
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userId).
+		Order("started_at desc, id desc").
+		Find(&entities)
+	return entities, result.Error
 }
 
 func (r *repository) Delete(ctx context.Context, id int) error {
 	return r.db.WithContext(ctx).Delete(&Entity{}, id).Error
 }
+
+func (r *repository) CreateInvitation(ctx context.Context, entity *InvitationEntity) (*InvitationEntity, error) {
+	db := r.db.WithContext(ctx).Create(entity)
+	return entity, db.Error
+}
+
+func (r *repository) FindInvitationById(ctx context.Context, id int) (*InvitationEntity, error) {
+	result := new(InvitationEntity)
+	db := r.db.WithContext(ctx).First(result, id)
+	return result, db.Error
+}
+
+// CancelInvitations cancels every open invitation of the user.
+func (r *repository) CancelInvitations(ctx context.Context, userId int) error {
+	return r.db.WithContext(ctx).Model(&InvitationEntity{}).
+		Where("user_id = ? and accepted_at is null and cancelled_at is null", userId).
+		Update("cancelled_at", time.Now()).Error
+}
+
+// AcceptInvitation marks the invitation as accepted and saves the user in one transaction,
+// it fails when the invitation was accepted or cancelled concurrently.
+func (r *repository) AcceptInvitation(ctx context.Context, invitation *InvitationEntity, entity *Entity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&InvitationEntity{}).
+			Where("id = ? and accepted_at is null and cancelled_at is null", invitation.Id).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Save(entity).Error
+	})
+}
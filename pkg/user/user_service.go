@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -12,23 +13,31 @@ import (
 	"mcm-api/pkg/enforcer"
 	"mcm-api/pkg/faculty"
 	"mcm-api/pkg/log"
+	"mcm-api/pkg/queue"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Service struct {
 	cfg            *config.Config
 	repository     *repository
 	facultyService *faculty.Service
+	queue          queue.Queue
 }
 
 func InitializeService(
 	cfg *config.Config,
 	repository *repository,
 	facultyService *faculty.Service,
+	queue queue.Queue,
 ) *Service {
 	return &Service{
 		cfg:            cfg,
 		repository:     repository,
 		facultyService: facultyService,
+		queue:          queue,
 	}
 }
 
@@ -43,7 +52,7 @@ func (s *Service) FindById(ctx context.Context, id int) (*UserResponse, error) {
 	return mapEntityToResponse(entity), nil
 }
 
-func (s *Service) FindByEmailAndPassword(ctx context.Context, email string, password string) (*UserResponse, error) {
+func (s *Service) FindByEmail(ctx context.Context, email string) (*UserResponse, error) {
 	entity, err := s.repository.FindByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -51,9 +60,23 @@ func (s *Service) FindByEmailAndPassword(ctx context.Context, email string, pass
 		}
 		return nil, err
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(entity.Password), []byte(password))
-	if err != nil {
-		return nil, apperror.New(apperror.ErrInvalid, "wrong password", err)
+	return mapEntityToResponse(entity), nil
+}
+
+// FindByEmailAndPassword fails the same way and takes the same time whether the
+// email is unknown or the password is wrong, so it cannot be used to find accounts.
+func (s *Service) FindByEmailAndPassword(ctx context.Context, email string, password string) (*UserResponse, error) {
+	entity, err := s.repository.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	hashedPassword := dummyPasswordHash()
+	if err == nil {
+		hashedPassword = []byte(entity.Password)
+	}
+	compareErr := bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+	if err != nil || compareErr != nil || entity.ServiceAccount {
+		return nil, apperror.New(apperror.ErrUnauthorized, "wrong email or password", nil)
 	}
 	return mapEntityToResponse(entity), nil
 }
@@ -70,35 +93,134 @@ func (s *Service) CreateUser(ctx context.Context, req *UserCreateReq) (*UserResp
 		return nil, apperror.New(apperror.ErrConflict, "duplicate email", err)
 	}
 	entity = &Entity{
-		Name:   req.Name,
-		Email:  req.Email,
-		Role:   req.Role,
-		Status: req.Status,
+		Name:           req.Name,
+		Email:          req.Email,
+		Role:           req.Role,
+		Status:         req.Status,
+		ServiceAccount: req.ServiceAccount,
 	}
 
 	// validate and set faculty
-	if req.Role == enforcer.MarketingCoordinator ||
-		req.Role == enforcer.Student ||
-		req.Role == enforcer.Guest {
-		_, err = s.facultyService.FindById(ctx, *req.FacultyId)
+	if isRoleRequiredFaculty(req.Role) {
+		faculty, err := s.facultyService.FindById(ctx, *req.FacultyId)
 		if err != nil {
 			return nil, apperror.New(apperror.ErrInvalid, "invalid faculty", err)
 		}
+		if faculty.IsArchived() {
+			return nil, apperror.New(apperror.ErrInvalid, "faculty is archived", nil)
+		}
 		entity.FacultyId = req.FacultyId
 	}
 
-	// hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	// hash password, service accounts and invited users get one nobody knows
+	password := req.Password
+	if req.ServiceAccount || req.IsInvitation() {
+		password = uuid.NewString()
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
 	if err != nil {
 		return nil, err
 	}
 	entity.Password = string(hashedPassword)
+	if req.IsInvitation() {
+		entity.Status = UserPending
+	}
 
 	// save
 	err = s.repository.Create(ctx, entity)
 	if err != nil {
 		return nil, err
 	}
+	if req.IsInvitation() {
+		// the user exists either way, the invitation can be resent
+		err = s.invite(ctx, entity)
+		if err != nil {
+			log.Logger.Error("invite user failed", zap.Int("id", entity.Id), zap.Error(err))
+		}
+	}
+	return mapEntityToResponse(entity), nil
+}
+
+// SyncExternalUser resolves the account of an identity provider login. Accounts are
+// matched on the subject of the provider, an existing local account with the same
+// verified email is linked once. Only the accounts the provider created on first sight
+// keep their role and faculty in sync with the provider groups, the privileges of a
+// linked local account are managed locally.
+func (s *Service) SyncExternalUser(ctx context.Context, req *ExternalUserReq) (*UserResponse, error) {
+	if req.Subject == "" {
+		return nil, apperror.New(apperror.ErrUnauthorized, "identity provider did not return a subject", nil)
+	}
+	entity, err := s.repository.FindByExternalSubject(ctx, req.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		entity, err = s.repository.FindByEmail(ctx, req.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil && entity.ExternalSubject != nil {
+			return nil, apperror.New(apperror.ErrForbidden, "the account of "+req.Email+" is linked to another identity", nil)
+		}
+	}
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if isNew && !req.Provision {
+		return nil, apperror.New(apperror.ErrNotFound, "no account is registered for "+req.Email, err)
+	}
+	if !isNew && entity.ServiceAccount {
+		return nil, apperror.New(apperror.ErrForbidden, "service accounts cannot log in", nil)
+	}
+	if isNew {
+		if req.Role == "" {
+			return nil, apperror.New(apperror.ErrForbidden, "your account is not assigned to any role", nil)
+		}
+		// the account can only be used through single sign-on until a password is set
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), 10)
+		if err != nil {
+			return nil, err
+		}
+		entity = &Entity{
+			Email:               req.Email,
+			Password:            string(hashedPassword),
+			Status:              UserActive,
+			ExternalProvisioned: true,
+		}
+	}
+	entity.ExternalSubject = &req.Subject
+	if req.Name != "" {
+		entity.Name = req.Name
+	}
+	// the identity provider vouches for the email, an open invitation is no longer needed
+	if entity.Status == UserPending {
+		entity.Status = UserActive
+	}
+	if entity.ExternalProvisioned && req.Provision && req.Role != "" {
+		entity.Role = req.Role
+		previousFacultyId := entity.FacultyId
+		entity.FacultyId = nil
+		if isRoleRequiredFaculty(req.Role) {
+			if req.FacultyName == "" {
+				return nil, apperror.New(apperror.ErrForbidden, "your account is not assigned to any faculty", nil)
+			}
+			faculty, err := s.facultyService.FindByName(ctx, req.FacultyName)
+			if err != nil {
+				return nil, apperror.New(apperror.ErrForbidden, "unknown faculty "+req.FacultyName, err)
+			}
+			// members of an archived faculty keep their access, nobody joins it
+			if faculty.IsArchived() && (previousFacultyId == nil || *previousFacultyId != faculty.Id) {
+				return nil, apperror.New(apperror.ErrForbidden, "faculty "+req.FacultyName+" is archived", nil)
+			}
+			entity.FacultyId = &faculty.Id
+		}
+	}
+	if isNew {
+		err = s.repository.Create(ctx, entity)
+	} else {
+		entity, err = s.repository.Update(ctx, entity)
+	}
+	if err != nil {
+		return nil, err
+	}
 	return mapEntityToResponse(entity), nil
 }
 
@@ -125,7 +247,11 @@ func (s *Service) CreateDefaultAdmin(ctx context.Context) error {
 }
 
 func (s *Service) Find(ctx context.Context, user *enforcer.LoggedInUser, query *UserIndexQuery) (*common.PaginateResponse, error) {
-	entities, count, err := s.repository.FindAndCount(ctx, query)
+	filter, err := query.Parse(userListSpec)
+	if err != nil {
+		return nil, err
+	}
+	entities, count, err := s.repository.FindAndCount(ctx, query, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -133,10 +259,118 @@ func (s *Service) Find(ctx context.Context, user *enforcer.LoggedInUser, query *
 	return common.NewPaginateResponse(dtos, count, query.Page, query.GetLimit()), nil
 }
 
+// Export returns the users matching the query as spreadsheet rows, the first row is
+// the header. The columns can be imported back.
+func (s *Service) Export(ctx context.Context, query *UserExportQuery) ([][]string, error) {
+	filter, err := query.Parse(userListSpec)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := s.repository.FindAll(ctx, &UserIndexQuery{
+		Role:           query.Role,
+		Status:         query.Status,
+		ServiceAccount: query.ServiceAccount,
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+	faculties, err := s.facultyService.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	facultyNames := make(map[int]string, len(faculties))
+	for _, f := range faculties {
+		facultyNames[f.Id] = f.Name
+	}
+	rows := make([][]string, 0, len(entities)+1)
+	rows = append(rows, []string{"id", "name", "email", "role", "faculty", "status", "serviceAccount", "twoFactorEnabled", "createdAt"})
+	for _, entity := range entities {
+		facultyName := ""
+		if entity.FacultyId != nil {
+			facultyName = facultyNames[*entity.FacultyId]
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(entity.Id),
+			entity.Name,
+			entity.Email,
+			string(entity.Role),
+			facultyName,
+			string(entity.Status),
+			strconv.FormatBool(entity.ServiceAccount),
+			strconv.FormatBool(entity.TotpEnabledAt != nil),
+			entity.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return rows, nil
+}
+
+// FindExistingEmails returns which of the emails already belong to a user, the
+// result is lower case.
+func (s *Service) FindExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(emails) == 0 {
+		return result, nil
+	}
+	entities, err := s.repository.FindByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		result[strings.ToLower(entity.Email)] = true
+	}
+	return result, nil
+}
+
+// GetCoordinatorsOfFaculty returns the active coordinators assigned to the faculty, or
+// every marketing coordinator of the faculty when none is assigned.
+func (s *Service) GetCoordinatorsOfFaculty(ctx context.Context, facultyId int) ([]*Entity, error) {
+	entities, err := s.repository.FindAssignedCoordinators(ctx, facultyId)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) > 0 {
+		return entities, nil
+	}
+	return s.repository.FindAllUserOfFaculty(ctx, enforcer.MarketingCoordinator, facultyId)
+}
+
+func (s *Service) GetStudentsWithoutContribution(ctx context.Context, sessionId int, facultyIds []int) ([]*Entity, error) {
+	return s.repository.FindStudentsWithoutContribution(ctx, sessionId, facultyIds)
+}
+
+func (s *Service) GetActiveUsersOfRole(ctx context.Context, role enforcer.Role) ([]*Entity, error) {
+	return s.repository.FindActiveUsersOfRole(ctx, role)
+}
+
 func (s *Service) GetAllUserOfFaculty(ctx context.Context, role enforcer.Role, facultyId int) ([]*Entity, error) {
 	return s.repository.FindAllUserOfFaculty(ctx, role, facultyId)
 }
 
+// FindFacultyHistory lists the faculties a user belonged to, the current one first.
+func (s *Service) FindFacultyHistory(ctx context.Context, id int) ([]*FacultyMembershipResponse, error) {
+	_, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	entities, err := s.repository.FindFacultyMemberships(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*FacultyMembershipResponse, 0, len(entities))
+	for _, v := range entities {
+		result = append(result, &FacultyMembershipResponse{
+			FacultyId: v.FacultyId,
+			StartedAt: v.StartedAt,
+			EndedAt:   v.EndedAt,
+			CreatedBy: v.CreatedBy,
+		})
+	}
+	return result, nil
+}
+
 func (s *Service) Update(ctx context.Context, id int, req *UserUpdateReq) (*UserResponse, error) {
 	err := req.Validate()
 	if err != nil {
@@ -149,6 +383,9 @@ func (s *Service) Update(ctx context.Context, id int, req *UserUpdateReq) (*User
 		}
 		return nil, err
 	}
+	if entity.AnonymizedAt != nil {
+		return nil, apperror.New(apperror.ErrConflict, "the personal data of the user was erased", nil)
+	}
 	if req.Password != nil {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), 10)
 		if err != nil {
@@ -165,11 +402,22 @@ func (s *Service) Update(ctx context.Context, id int, req *UserUpdateReq) (*User
 	if req.Name != nil {
 		entity.Name = *req.Name
 	}
-	if req.FacultyId != nil {
+	if req.FacultyId != nil && (entity.FacultyId == nil || *entity.FacultyId != *req.FacultyId) {
+		faculty, err := s.facultyService.FindById(ctx, *req.FacultyId)
+		if err != nil {
+			return nil, apperror.New(apperror.ErrInvalid, "invalid faculty", err)
+		}
+		if faculty.IsArchived() {
+			return nil, apperror.New(apperror.ErrInvalid, "faculty is archived", nil)
+		}
+		// the repository records the transfer in the faculty history, contributions keep
+		// the faculty they were submitted to
 		entity.FacultyId = req.FacultyId
 	}
-
 	if req.Status != nil {
+		if entity.Status == UserPending {
+			return nil, errInvitationPending()
+		}
 		entity.Status = *req.Status
 	}
 
@@ -180,6 +428,107 @@ func (s *Service) Update(ctx context.Context, id int, req *UserUpdateReq) (*User
 	return mapEntityToResponse(entity), nil
 }
 
+func (s *Service) GetNotificationPreferences(ctx context.Context, id int) (*NotificationPreferences, error) {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	return &entity.NotificationPreferences, nil
+}
+
+func (s *Service) GetTwoFactor(ctx context.Context, id int) (*TwoFactor, error) {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	return &TwoFactor{
+		Secret:        entity.TotpSecret,
+		EnabledAt:     entity.TotpEnabledAt,
+		RecoveryCodes: entity.TotpRecoveryCodes,
+	}, nil
+}
+
+func (s *Service) UpdateTwoFactor(ctx context.Context, id int, twoFactor *TwoFactor) error {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return err
+	}
+	entity.TotpSecret = twoFactor.Secret
+	entity.TotpEnabledAt = twoFactor.EnabledAt
+	entity.TotpRecoveryCodes = twoFactor.RecoveryCodes
+	_, err = s.repository.Update(ctx, entity)
+	return err
+}
+
+// UseRecoveryCode redeems one of the recovery codes of the user by its hash, each code
+// works once even when presented concurrently.
+func (s *Service) UseRecoveryCode(ctx context.Context, id int, hash string) (bool, error) {
+	return s.repository.ConsumeRecoveryCode(ctx, id, hash)
+}
+
+// UpdateProfile applies the changes a user is allowed to make on their own account.
+func (s *Service) UpdateProfile(ctx context.Context, id int, req *ProfileUpdateReq) (*UserResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return nil, err
+	}
+	if req.Name != nil {
+		entity.Name = *req.Name
+	}
+	if req.Avatar != nil {
+		entity.Avatar = *req.Avatar
+	}
+	if req.NotificationPreferences != nil {
+		req.NotificationPreferences.applyTo(&entity.NotificationPreferences)
+	}
+	entity, err = s.repository.Update(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return mapEntityToResponse(entity), nil
+}
+
+func (s *Service) ChangePassword(ctx context.Context, id int, req *PasswordChangeReq) error {
+	err := req.Validate()
+	if err != nil {
+		return err
+	}
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.New(apperror.ErrNotFound, "user not found", err)
+		}
+		return err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(entity.Password), []byte(req.CurrentPassword))
+	if err != nil {
+		return apperror.New(apperror.ErrInvalid, "current password is incorrect", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 10)
+	if err != nil {
+		return err
+	}
+	entity.Password = string(hashedPassword)
+	_, err = s.repository.Update(ctx, entity)
+	return err
+}
+
 func (s *Service) DeleteUser(ctx context.Context, id int) error {
 	entity, err := s.repository.FindById(ctx, id)
 	if err != nil {
@@ -190,7 +539,7 @@ func (s *Service) DeleteUser(ctx context.Context, id int) error {
 	}
 	err = s.repository.Delete(ctx, entity.Id)
 	if err != nil {
-		return apperror.New(apperror.ErrConflict, "failed to delete user, please try disabling it instead", err)
+		return apperror.New(apperror.ErrConflict, "the user has contributions or comments, please disable or erase it instead", err)
 	}
 	return nil
 }
@@ -203,15 +552,41 @@ func (s Service) ChangeStatus(ctx context.Context, id int, req *UserUpdateStatus
 		}
 		return err
 	}
+	if entity.AnonymizedAt != nil {
+		return apperror.New(apperror.ErrConflict, "the personal data of the user was erased", nil)
+	}
 	err = req.Validate()
 	if err != nil {
 		return err
 	}
+	if entity.Status == UserPending {
+		return errInvitationPending()
+	}
 	entity.Status = req.Status
 	_, err = s.repository.Update(ctx, entity)
 	return err
 }
 
+// errInvitationPending rejects status changes of an invited user, who becomes active by
+// accepting the invitation and setting a password.
+func errInvitationPending() error {
+	return apperror.New(apperror.ErrConflict, "the user has not accepted the invitation yet", nil)
+}
+
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// dummyPasswordHash is compared against when the email is unknown, it uses the same
+// cost as real password hashes.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte(uuid.NewString()), 10)
+	})
+	return dummyHash
+}
+
 func mapEntitiesToResponse(entity []*Entity) []*UserResponse {
 	var result []*UserResponse
 	for i := range entity {
@@ -222,12 +597,16 @@ func mapEntitiesToResponse(entity []*Entity) []*UserResponse {
 
 func mapEntityToResponse(entity *Entity) *UserResponse {
 	return &UserResponse{
-		Id:        entity.Id,
-		Name:      entity.Name,
-		Role:      entity.Role,
-		Status:    entity.Status,
-		Email:     entity.Email,
-		FacultyId: entity.FacultyId,
+		Id:               entity.Id,
+		Name:             entity.Name,
+		Role:             entity.Role,
+		Status:           entity.Status,
+		Email:            entity.Email,
+		FacultyId:        entity.FacultyId,
+		Avatar:           entity.Avatar,
+		TwoFactorEnabled: entity.TotpEnabledAt != nil,
+		ServiceAccount:   entity.ServiceAccount,
+		AnonymizedAt:     entity.AnonymizedAt,
 		TrackTime: common.TrackTime{
 			CreatedAt: entity.CreatedAt,
 			UpdatedAt: entity.UpdatedAt,
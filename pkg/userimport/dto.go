@@ -0,0 +1,64 @@
+package userimport
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"io"
+	"time"
+)
+
+// Row is a line of the imported file as written, Row is its number in the file.
+type Row struct {
+	Row     int    `json:"row"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Faculty string `json:"faculty"`
+	Status  string `json:"status"`
+}
+
+type RowResult struct {
+	Row    int    `json:"row"`
+	Email  string `json:"email"`
+	UserId *int   `json:"userId,omitempty"`
+	// Errors maps a column of the file to what is wrong with it
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type Report struct {
+	Total   int          `json:"total"`
+	Invalid int          `json:"invalid"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Invited int          `json:"invited"`
+	Rows    []*RowResult `json:"rows"`
+}
+
+type ImportRequest struct {
+	FileName string
+	File     io.ReaderAt
+	Size     int64
+	DryRun   bool
+	Invite   bool
+}
+
+func (r *ImportRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.FileName, validation.Required),
+		validation.Field(&r.Size, validation.Required, validation.Max(int64(maxImportFileSize)).
+			Error("the file must not be larger than 5MB")),
+	)
+}
+
+type ImportResponse struct {
+	// Id is empty for dry runs, nothing is stored
+	Id         int        `json:"id,omitempty"`
+	FileName   string     `json:"fileName"`
+	Status     Status     `json:"status" enums:"pending,running,done,failed"`
+	DryRun     bool       `json:"dryRun"`
+	Invite     bool       `json:"invite"`
+	Report     Report     `json:"report"`
+	Error      string     `json:"error,omitempty"`
+	CreatedBy  *int       `json:"createdBy,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,78 @@
+package userimport
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+type Entity struct {
+	Id       int
+	FileName string
+	Status   Status
+	// Invite creates active users as pending and sends them an invitation email
+	Invite     bool
+	Rows       Rows
+	Report     Report
+	Error      string
+	CreatedBy  *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (e *Entity) TableName() string {
+	return "user_imports"
+}
+
+// Rows are the parsed rows of the file, stored as jsonb so the worker does not need the file.
+type Rows []*Row
+
+func (r Rows) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]*Row(r))
+}
+
+func (r *Rows) Scan(value interface{}) error {
+	*r = nil
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return errors.New("unsupported type of import rows")
+	}
+}
+
+func (r Report) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *Report) Scan(value interface{}) error {
+	*r = Report{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return errors.New("unsupported type of import report")
+	}
+}
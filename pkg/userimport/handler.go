@@ -0,0 +1,114 @@
+package userimport
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/middleware"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	config        *config.Config
+	authenticator *middleware.Authenticator
+	service       *Service
+}
+
+func NewHandler(config *config.Config, authenticator *middleware.Authenticator, service *Service) *Handler {
+	return &Handler{
+		config:        config,
+		authenticator: authenticator,
+		service:       service,
+	}
+}
+
+func (h *Handler) Register(group *echo.Group) {
+	group.Use(h.authenticator.RequireAuthentication())
+	group.POST("", h.create, middleware.RequirePermission(enforcer.CreateUser))
+	group.GET("/:id", h.getById, middleware.RequirePermission(enforcer.CreateUser))
+}
+
+// @Tags Users
+// @Summary Import users
+// @Description Create users from a csv or xlsx file with the columns name, email, role, faculty (name or id) and status.
+// @Description Nothing is created unless every row is valid, the per-row report is returned as error data.
+// @Description Files of more than 50 rows are imported by the worker, poll the import until it is done.
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "csv or xlsx file"
+// @Param dryRun formData bool false "only validate the file"
+// @Param invite formData bool false "invite active users to choose their password instead of setting a random one"
+// @Success 200 {object} userimport.ImportResponse
+// @Security ApiKeyAuth
+// @Router /user-imports [post]
+func (h *Handler) create(ctx echo.Context) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return apperror.HandleError(apperror.New(apperror.ErrInvalid, "missing file", err), ctx)
+		}
+		return apperror.HandleError(err, ctx)
+	}
+	dryRun, err := parseFormBool(ctx, "dryRun")
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	invite, err := parseFormBool(ctx, "invite")
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	open, err := file.Open()
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	defer func() {
+		_ = open.Close()
+	}()
+	result, err := h.service.Import(ctx.Request().Context(), &ImportRequest{
+		FileName: file.Filename,
+		File:     open,
+		Size:     file.Size,
+		DryRun:   dryRun,
+		Invite:   invite,
+	})
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// @Tags Users
+// @Summary Show a user import
+// @Description get the state and report of an import by ID
+// @Accept  json
+// @Produce  json
+// @Param id path int true "import ID"
+// @Success 200 {object} userimport.ImportResponse
+// @Security ApiKeyAuth
+// @Router /user-imports/{id} [get]
+func (h *Handler) getById(ctx echo.Context) error {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return apperror.HandleError(apperror.New(apperror.ErrInvalid, "invalid id", err), ctx)
+	}
+	result, err := h.service.FindById(ctx.Request().Context(), id)
+	if err != nil {
+		return apperror.HandleError(err, ctx)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func parseFormBool(ctx echo.Context, name string) (bool, error) {
+	value := ctx.FormValue(name)
+	if value == "" {
+		return false, nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apperror.New(apperror.ErrInvalid, name+" should be true or false", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package userimport
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func InitializeRepository(db *gorm.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r repository) Create(ctx context.Context, entity *Entity) (*Entity, error) {
+	db := r.db.WithContext(ctx).Create(entity)
+	return entity, db.Error
+}
+
+func (r repository) FindById(ctx context.Context, id int) (*Entity, error) {
+	result := new(Entity)
+	db := r.db.WithContext(ctx).First(result, id)
+	return result, db.Error
+}
+
+func (r repository) Update(ctx context.Context, entity *Entity) (*Entity, error) {
+	db := r.db.WithContext(ctx).Save(entity)
+	return entity, db.Error
+}
+
+// MarkRunning moves a pending import to running, it returns false when the import
+// was already picked up.
+func (r repository) MarkRunning(ctx context.Context, id int) (bool, error) {
+	db := r.db.WithContext(ctx).Model(&Entity{}).
+		Where("id = ? and status = ?", id, StatusPending).
+		Update("status", StatusRunning)
+	return db.RowsAffected > 0, db.Error
+}
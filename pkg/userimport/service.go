@@ -0,0 +1,397 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"mcm-api/config"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/enforcer"
+	"mcm-api/pkg/faculty"
+	"mcm-api/pkg/log"
+	"mcm-api/pkg/queue"
+	"mcm-api/pkg/spreadsheet"
+	"mcm-api/pkg/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// files with up to this many rows are imported within the request, larger ones by the worker
+	syncImportRows    = 50
+	maxImportRows     = 2000
+	maxImportFileSize = 5 << 20
+)
+
+// headerAliases maps the normalized header of a column to the field it holds
+var headerAliases = map[string]string{
+	"name":         "name",
+	"fullname":     "name",
+	"email":        "email",
+	"emailaddress": "email",
+	"role":         "role",
+	"faculty":      "faculty",
+	"facultyname":  "faculty",
+	"facultyid":    "faculty",
+	"status":       "status",
+}
+
+var requiredColumns = []string{"name", "email", "role"}
+
+type Service struct {
+	cfg            *config.Config
+	repository     *repository
+	userService    *user.Service
+	facultyService *faculty.Service
+	queue          queue.Queue
+}
+
+func InitializeService(
+	cfg *config.Config,
+	repository *repository,
+	userService *user.Service,
+	facultyService *faculty.Service,
+	queue queue.Queue,
+) *Service {
+	return &Service{
+		cfg:            cfg,
+		repository:     repository,
+		userService:    userService,
+		facultyService: facultyService,
+		queue:          queue,
+	}
+}
+
+// Import creates the users of a csv or xlsx file. Every row is validated first and
+// nothing is created unless all rows are valid. A dry run only returns the validation
+// report, small files are imported right away and large ones are queued for the worker.
+func (s Service) Import(ctx context.Context, req *ImportRequest) (*ImportResponse, error) {
+	loggedInUser, err := enforcer.GetLoggedInUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	format, err := spreadsheet.FormatOf(req.FileName)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInvalid, err.Error(), err)
+	}
+	records, err := spreadsheet.Read(req.File, req.Size, format)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInvalid, "cannot read the file", err)
+	}
+	rows, err := parseRows(records)
+	if err != nil {
+		return nil, err
+	}
+	_, report, err := s.validateRows(ctx, rows, req.Invite)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return &ImportResponse{
+			FileName: req.FileName,
+			Status:   StatusDone,
+			DryRun:   true,
+			Invite:   req.Invite,
+			Report:   *report,
+		}, nil
+	}
+	if report.Invalid > 0 {
+		return nil, apperror.New(apperror.ErrInvalid, "the file has invalid rows, nothing was imported", nil).
+			WithData(report)
+	}
+
+	entity, err := s.repository.Create(ctx, &Entity{
+		FileName:  req.FileName,
+		Status:    StatusPending,
+		Invite:    req.Invite,
+		Rows:      rows,
+		Report:    *report,
+		CreatedBy: &loggedInUser.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) <= syncImportRows {
+		entity, err = s.run(ctx, entity)
+		if err != nil {
+			return nil, err
+		}
+		return mapEntityToResponse(entity), nil
+	}
+	err = s.queue.Add(ctx, &queue.Message{
+		Topic: queue.UserImportRequested,
+		Data:  &queue.UserImportRequestedPayload{ImportId: entity.Id},
+	})
+	if err != nil {
+		return nil, s.fail(entity, err)
+	}
+	return mapEntityToResponse(entity), nil
+}
+
+func (s Service) FindById(ctx context.Context, id int) (*ImportResponse, error) {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.ErrNotFound, "user import not found", err)
+		}
+		return nil, err
+	}
+	return mapEntityToResponse(entity), nil
+}
+
+// Process runs a queued import, it is called by the worker.
+func (s Service) Process(ctx context.Context, id int) error {
+	entity, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.run(ctx, entity)
+	return err
+}
+
+// run creates the users of a pending import. The rows are validated again because
+// emails may have been taken since the import was requested, such rows are reported
+// as failed while the others are still created.
+func (s Service) run(ctx context.Context, entity *Entity) (*Entity, error) {
+	started, err := s.repository.MarkRunning(ctx, entity.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		log.Logger.Info("skip user import that is not pending", zap.Int("id", entity.Id))
+		return entity, nil
+	}
+	entity.Status = StatusRunning
+	requests, report, err := s.validateRows(ctx, entity.Rows, entity.Invite)
+	if err != nil {
+		return nil, s.fail(entity, err)
+	}
+	report.Failed = report.Invalid
+	for i, result := range report.Rows {
+		if requests[i] == nil {
+			continue
+		}
+		created, err := s.userService.CreateUser(ctx, requests[i])
+		if err != nil {
+			result.Errors = rowErrors(err)
+			report.Failed++
+			continue
+		}
+		result.UserId = &created.Id
+		report.Created++
+		if created.Status == user.UserPending {
+			report.Invited++
+		}
+	}
+	now := time.Now()
+	entity.Status = StatusDone
+	entity.Report = *report
+	entity.FinishedAt = &now
+	return s.repository.Update(ctx, entity)
+}
+
+// fail records why an import stopped, it does not use the context of the import
+// since it may be the reason.
+func (s Service) fail(entity *Entity, cause error) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelFunc()
+	now := time.Now()
+	entity.Status = StatusFailed
+	entity.Error = cause.Error()
+	entity.FinishedAt = &now
+	_, err := s.repository.Update(ctx, entity)
+	if err != nil {
+		log.Logger.Error("update failed user import", zap.Int("id", entity.Id), zap.Error(err))
+	}
+	return cause
+}
+
+// validateRows checks every row with the rules of UserCreateReq, plus duplicated emails
+// within the file and against existing users. The request of an invalid row is nil.
+// With invite, active rows become invitations and the users choose their password.
+func (s Service) validateRows(ctx context.Context, rows []*Row, invite bool) ([]*user.UserCreateReq, *Report, error) {
+	faculties, err := s.facultyService.FindAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	facultyIds := make(map[int]bool, len(faculties))
+	facultyNames := make(map[string]int, len(faculties))
+	archivedFaculties := make(map[int]bool)
+	for _, f := range faculties {
+		facultyIds[f.Id] = true
+		facultyNames[strings.ToLower(f.Name)] = f.Id
+		if f.IsArchived() {
+			archivedFaculties[f.Id] = true
+		}
+	}
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Email != "" {
+			emails = append(emails, row.Email)
+		}
+	}
+	existing, err := s.userService.FindExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &Report{Total: len(rows), Rows: make([]*RowResult, len(rows))}
+	requests := make([]*user.UserCreateReq, len(rows))
+	firstRowOfEmail := make(map[string]int, len(rows))
+	for i, row := range rows {
+		errs := make(map[string]string)
+		req := &user.UserCreateReq{
+			Name:   row.Name,
+			Email:  row.Email,
+			Role:   enforcer.Role(strings.ToLower(row.Role)),
+			Status: user.UserStatus(strings.ToLower(row.Status)),
+		}
+		if req.Status == "" {
+			req.Status = user.UserActive
+		}
+		if !invite || req.Status != user.UserActive {
+			// the user chooses a password with the forgot password form
+			req.Password = uuid.NewString()
+		}
+		if row.Faculty != "" {
+			if facultyId, ok := resolveFaculty(row.Faculty, facultyIds, facultyNames); ok && archivedFaculties[facultyId] {
+				errs["faculty"] = "archived faculty"
+			} else if ok {
+				req.FacultyId = &facultyId
+			} else {
+				errs["faculty"] = "unknown faculty"
+			}
+		}
+		for column, message := range rowErrors(req.Validate()) {
+			if _, ok := errs[column]; !ok {
+				errs[column] = message
+			}
+		}
+		email := strings.ToLower(row.Email)
+		if _, ok := errs["email"]; !ok && email != "" {
+			if first, ok := firstRowOfEmail[email]; ok {
+				errs["email"] = fmt.Sprintf("the email is already used on row %v", first)
+			} else if existing[email] {
+				errs["email"] = "the email is already used by another user"
+			}
+		}
+		if _, ok := firstRowOfEmail[email]; !ok {
+			firstRowOfEmail[email] = row.Row
+		}
+
+		report.Rows[i] = &RowResult{Row: row.Row, Email: row.Email}
+		if len(errs) > 0 {
+			report.Rows[i].Errors = errs
+			report.Invalid++
+			continue
+		}
+		requests[i] = req
+	}
+	return requests, report, nil
+}
+
+// rowErrors maps an error of UserCreateReq to the columns of the file.
+func rowErrors(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	result := make(map[string]string)
+	var validationErrors validation.Errors
+	if !errors.As(err, &validationErrors) {
+		result["row"] = err.Error()
+		return result
+	}
+	for field, fieldErr := range validationErrors {
+		if field == "facultyId" {
+			field = "faculty"
+		}
+		result[field] = fieldErr.Error()
+	}
+	return result
+}
+
+// resolveFaculty accepts the id or the name of a faculty.
+func resolveFaculty(value string, ids map[int]bool, names map[string]int) (int, bool) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, ids[id]
+	}
+	id, ok := names[strings.ToLower(value)]
+	return id, ok
+}
+
+// parseRows reads the rows below the header, columns are found by their header and
+// unknown columns are ignored so exported files can be imported back.
+func parseRows(records [][]string) ([]*Row, error) {
+	if len(records) == 0 {
+		return nil, apperror.New(apperror.ErrInvalid, "the file is empty", nil)
+	}
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		normalized := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(header)))
+		if field, ok := headerAliases[normalized]; ok {
+			if _, duplicated := columns[field]; !duplicated {
+				columns[field] = i
+			}
+		}
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, apperror.New(apperror.ErrInvalid, "the file does not have a "+column+" column", nil)
+		}
+	}
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []*Row
+	for i, record := range records[1:] {
+		row := &Row{
+			// the header is row 1
+			Row:     i + 2,
+			Name:    cell(record, "name"),
+			Email:   cell(record, "email"),
+			Role:    cell(record, "role"),
+			Faculty: cell(record, "faculty"),
+			Status:  cell(record, "status"),
+		}
+		if *row == (Row{Row: row.Row}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, apperror.New(apperror.ErrInvalid, "the file does not have any user", nil)
+	}
+	if len(rows) > maxImportRows {
+		return nil, apperror.New(apperror.ErrInvalid,
+			fmt.Sprintf("at most %v users can be imported at once", maxImportRows), nil)
+	}
+	return rows, nil
+}
+
+func mapEntityToResponse(entity *Entity) *ImportResponse {
+	return &ImportResponse{
+		Id:         entity.Id,
+		FileName:   entity.FileName,
+		Status:     entity.Status,
+		Invite:     entity.Invite,
+		Report:     entity.Report,
+		Error:      entity.Error,
+		CreatedBy:  entity.CreatedBy,
+		CreatedAt:  &entity.CreatedAt,
+		FinishedAt: entity.FinishedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package userimport
+
+import (
+	"context"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"mcm-api/pkg/apperror"
+	"mcm-api/pkg/faculty"
+	"mcm-api/pkg/user"
+	"reflect"
+	"testing"
+)
+
+func TestParseRows(t *testing.T) {
+	cases := map[string]struct {
+		records  [][]string
+		expected []*Row
+	}{
+		"header aliases": {
+			records: [][]string{
+				{" Full Name ", "E-mail Address", "ROLE", "faculty_id", "Status"},
+				{"Ann Smith", "ann@example.com", "student", "1", "active"},
+			},
+			expected: []*Row{
+				{Row: 2, Name: "Ann Smith", Email: "ann@example.com", Role: "student", Faculty: "1", Status: "active"},
+			},
+		},
+		"unknown and repeated columns": {
+			records: [][]string{
+				{"id", "name", "email", "role", "name"},
+				{"7", " Ann Smith ", "ann@example.com", "guest", "ignored"},
+			},
+			expected: []*Row{
+				{Row: 2, Name: "Ann Smith", Email: "ann@example.com", Role: "guest"},
+			},
+		},
+		"blank rows keep the numbering": {
+			records: [][]string{
+				{"name", "email", "role"},
+				{"", " ", ""},
+				{"Ann Smith", "ann@example.com", "guest"},
+				{},
+				{"Bob Jones", "bob@example.com"},
+			},
+			expected: []*Row{
+				{Row: 3, Name: "Ann Smith", Email: "ann@example.com", Role: "guest"},
+				{Row: 5, Name: "Bob Jones", Email: "bob@example.com"},
+			},
+		},
+	}
+	for name, c := range cases {
+		rows, err := parseRows(c.records)
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(rows, c.expected) {
+			t.Errorf("%v: expected %+v, got %+v", name, c.expected, rows)
+		}
+	}
+}
+
+func TestParseRowsInvalid(t *testing.T) {
+	tooMany := [][]string{{"name", "email", "role"}}
+	for i := 0; i <= maxImportRows; i++ {
+		tooMany = append(tooMany, []string{"Ann Smith", "ann@example.com", "guest"})
+	}
+	cases := map[string][][]string{
+		"empty file":       {},
+		"missing column":   {{"name", "email"}, {"Ann Smith", "ann@example.com"}},
+		"only blank rows":  {{"name", "email", "role"}, {"", "", ""}},
+		"too many rows":    tooMany,
+		"only the headers": {{"name", "email", "role"}},
+	}
+	for name, records := range cases {
+		_, err := parseRows(records)
+		if !apperror.Is(err, apperror.ErrInvalid) {
+			t.Errorf("%v: expected an invalid error, got %v", name, err)
+		}
+	}
+}
+
+func TestResolveFaculty(t *testing.T) {
+	ids := map[int]bool{1: true, 2: true}
+	names := map[string]int{"computing": 1, "business school": 2}
+	cases := []struct {
+		value string
+		id    int
+		ok    bool
+	}{
+		{"1", 1, true},
+		{"2", 2, true},
+		{"3", 3, false},
+		{"Computing", 1, true},
+		{"BUSINESS SCHOOL", 2, true},
+		{"Law", 0, false},
+	}
+	for _, c := range cases {
+		id, ok := resolveFaculty(c.value, ids, names)
+		if ok != c.ok || (ok && id != c.id) {
+			t.Errorf("%q: expected %v %v, got %v %v", c.value, c.id, c.ok, id, ok)
+		}
+	}
+}
+
+func TestValidateRowsDuplicatedEmails(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	facultyService := faculty.InitializeService(nil, faculty.InitializeRepository(db))
+	s := Service{
+		userService:    user.InitializeService(nil, user.InitializeRepository(db), facultyService, nil),
+		facultyService: facultyService,
+	}
+	rows := []*Row{
+		{Row: 2, Name: "Ann Smith", Email: "ann@example.com", Role: "admin"},
+		{Row: 4, Name: "Bob Jones", Email: "bob@example.com", Role: "admin"},
+		{Row: 5, Name: "Ann Smith", Email: "ANN@example.com", Role: "admin"},
+	}
+	requests, report, err := s.validateRows(context.Background(), rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 3 || report.Invalid != 1 {
+		t.Fatalf("expected 1 of 3 rows to be invalid, got %+v", report)
+	}
+	if requests[0] == nil || requests[1] == nil || requests[2] != nil {
+		t.Errorf("expected only the last row to be rejected, got %+v", requests)
+	}
+	expected := map[string]string{"email": "the email is already used on row 2"}
+	if !reflect.DeepEqual(report.Rows[2].Errors, expected) {
+		t.Errorf("expected %v, got %v", expected, report.Rows[2].Errors)
+	}
+}